@@ -12,6 +12,10 @@ type Config struct {
 	HTTPServer HTTPServer
 	Logger     Logger
 	JWT        JWT
+	Scheduler  Scheduler
+	Checker    Checker
+	Trash      Trash
+	Calendar   Calendar
 }
 
 type Logger struct {
@@ -29,6 +33,36 @@ type JWT struct {
 	JWTAccessSecretKey     string        `env:"JWT_ACCESS_SECRET_KEY,required"`
 }
 
+type Scheduler struct {
+	Interval       time.Duration `env:"SCHEDULER_INTERVAL" envDefault:"1m"`
+	RecurrenceLead time.Duration `env:"SCHEDULER_RECURRENCE_LEAD" envDefault:"168h"`
+}
+
+type Checker struct {
+	WorkDir    string        `env:"CHECKER_WORK_DIR"`
+	BatchSize  int64         `env:"CHECKER_BATCH_SIZE" envDefault:"4"`
+	StaleAfter time.Duration `env:"CHECKER_STALE_AFTER" envDefault:"30m"`
+	Interval   time.Duration `env:"CHECKER_INTERVAL" envDefault:"10s"`
+	// RootFS is the directory mounted read-only as the root of every check,
+	// e.g. an unpacked distribution image with the compilers the tasks need.
+	RootFS          string `env:"CHECKER_ROOT_FS"`
+	MaxProcesses    int64  `env:"CHECKER_MAX_PROCESSES" envDefault:"256"`
+	MaxFileSize     int64  `env:"CHECKER_MAX_FILE_SIZE" envDefault:"16777216"`
+	MaxUnpackedSize int64  `env:"CHECKER_MAX_UNPACKED_SIZE" envDefault:"268435456"`
+}
+
+type Trash struct {
+	Retention time.Duration `env:"TRASH_RETENTION" envDefault:"720h"`
+}
+
+type Calendar struct {
+	// TaskURL is a format string with the task id as its only verb, e.g.
+	// "https://lms.example.com/tasks/%d". Events carry no link when empty.
+	TaskURL         string        `env:"CALENDAR_TASK_URL"`
+	GradingPeriod   time.Duration `env:"CALENDAR_GRADING_PERIOD" envDefault:"168h"`
+	RefreshInterval time.Duration `env:"CALENDAR_REFRESH_INTERVAL" envDefault:"1h"`
+}
+
 var (
 	config Config
 	once   sync.Once
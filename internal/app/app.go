@@ -2,8 +2,11 @@ package app
 
 import (
 	"backend/config"
+	"backend/internal/checker"
+	"backend/internal/checker/sandbox"
 	"backend/internal/models"
 	repos "backend/internal/repo/pg"
+	"backend/internal/scheduler"
 	"backend/internal/services"
 	"backend/internal/transport/http"
 	"backend/pkg/logger"
@@ -37,19 +40,69 @@ func Run(cfg *config.Config) {
 	groupsRepo := repos.NewGroupsRepo(pgConn)
 	tasksRepo := repos.NewTasksRepo(pgConn)
 	taskLinksRepo := repos.NewTaskLinksRepo(pgConn)
+	taskSeriesRepo := repos.NewTaskSeriesRepo(pgConn)
+	taskPrerequisitesRepo := repos.NewTaskPrerequisitesRepo(pgConn)
+	rubricsRepo := repos.NewRubricsRepo(pgConn)
+	questionBanksRepo := repos.NewQuestionBanksRepo(pgConn)
+	quizzesRepo := repos.NewQuizzesRepo(pgConn)
+	checkersRepo := repos.NewCheckersRepo(pgConn)
+	trashRepo := repos.NewTrashRepo(pgConn)
+	teamsRepo := repos.NewTeamsRepo(pgConn)
+	commentsRepo := repos.NewCommentsRepo(pgConn)
+	calendarRepo := repos.NewCalendarRepo(pgConn)
+	peerReviewsRepo := repos.NewPeerReviewsRepo(pgConn)
 	usersRepo := repos.NewUsersRepo(pgConn)
 	authRepo := repos.NewAuthRepo(pgConn)
 	marksRepo := repos.NewMarksRepo(pgConn)
 	statisticsRepo := repos.NewStatisticsRepo(pgConn)
+	orgUnitsRepo := repos.NewOrgUnitsRepo(pgConn)
 
 	fileService := services.NewFileServiceImpl(filesRepo, log)
-	answerService := services.NewAnswerServiceImpl(answersRepo, fileService, log)
-	groupService := services.NewGroupServiceImpl(groupsRepo, log)
-	taskLinksService := services.NewTaskLinksServiceImpl(taskLinksRepo, log)
-	taskService := services.NewTaskServiceImpl(tasksRepo, fileService, taskLinksService, log)
 	userService := services.NewUserServiceImpl(usersRepo, log)
-	marksService := services.NewMarkServiceImpl(marksRepo, log)
+	orgUnitService := services.NewOrgUnitServiceImpl(orgUnitsRepo, userService, log)
+	groupService := services.NewGroupServiceImpl(groupsRepo, userService, orgUnitService, log)
+	taskLinksService := services.NewTaskLinksServiceImpl(taskLinksRepo, log)
+	rubricService := services.NewRubricServiceImpl(rubricsRepo, log)
+	taskService := services.NewTaskServiceImpl(tasksRepo, fileService, taskLinksService, groupService, rubricService, services.NewLogNotifier(log), log)
+	taskSeriesService := services.NewTaskSeriesServiceImpl(taskSeriesRepo, taskService, cfg.Scheduler.RecurrenceLead, log)
+	taskPrerequisiteService := services.NewTaskPrerequisiteServiceImpl(taskPrerequisitesRepo, taskService, log)
+	checkerService := services.NewCheckerServiceImpl(
+		checkersRepo,
+		map[string]checker.Checker{
+			models.CheckerSandbox: sandbox.New(sandbox.Config{
+				WorkDir:         cfg.Checker.WorkDir,
+				RootFS:          cfg.Checker.RootFS,
+				MaxProcesses:    cfg.Checker.MaxProcesses,
+				MaxFileSize:     cfg.Checker.MaxFileSize,
+				MaxUnpackedSize: cfg.Checker.MaxUnpackedSize,
+			}),
+		},
+		fileService,
+		taskService,
+		cfg.Checker.BatchSize,
+		cfg.Checker.StaleAfter,
+		log,
+	)
+	teamService := services.NewTeamServiceImpl(teamsRepo, taskService, userService, log)
+	answerService := services.NewAnswerServiceImpl(
+		answersRepo, fileService, taskService, taskPrerequisiteService, checkerService, teamService, log,
+	)
+	peerReviewService := services.NewPeerReviewServiceImpl(peerReviewsRepo, taskService, rubricService, log)
+	marksService := services.NewMarkServiceImpl(marksRepo, answerService, taskService, rubricService, peerReviewService, log)
+	questionBankService := services.NewQuestionBankServiceImpl(questionBanksRepo, log)
+	quizService := services.NewQuizServiceImpl(quizzesRepo, questionBankService, taskService, answerService, marksService, log)
 	statisticsService := services.NewStatisticsServiceImpl(statisticsRepo)
+	trashService := services.NewTrashServiceImpl(trashRepo, cfg.Trash.Retention, log)
+	commentService := services.NewCommentServiceImpl(commentsRepo, answerService, log)
+	calendarService := services.NewCalendarServiceImpl(
+		calendarRepo,
+		taskService,
+		userService,
+		cfg.Calendar.TaskURL,
+		cfg.Calendar.GradingPeriod,
+		cfg.Calendar.RefreshInterval,
+		log,
+	)
 	authService := services.NewAuthServiceImpl(
 		authRepo,
 		models.JWTConfig{
@@ -63,15 +116,27 @@ func Run(cfg *config.Config) {
 	)
 
 	server := http.NewServer(&http.Config{
-		Addr:              cfg.HTTPServer.Addr,
-		TaskService:       taskService,
-		AnswerService:     answerService,
-		FileService:       fileService,
-		GroupService:      groupService,
-		UserService:       userService,
-		AuthService:       authService,
-		MarkService:       marksService,
-		StatisticsService: statisticsService,
+		Addr:                    cfg.HTTPServer.Addr,
+		TaskService:             taskService,
+		TaskSeriesService:       taskSeriesService,
+		TaskPrerequisiteService: taskPrerequisiteService,
+		AnswerService:           answerService,
+		FileService:             fileService,
+		GroupService:            groupService,
+		UserService:             userService,
+		AuthService:             authService,
+		MarkService:             marksService,
+		StatisticsService:       statisticsService,
+		OrgUnitService:          orgUnitService,
+		RubricService:           rubricService,
+		QuestionBankService:     questionBankService,
+		QuizService:             quizService,
+		CheckerService:          checkerService,
+		TrashService:            trashService,
+		TeamService:             teamService,
+		CommentService:          commentService,
+		CalendarService:         calendarService,
+		PeerReviewService:       peerReviewService,
 		JWTConfig: models.JWTConfig{
 			JWTAccessExpirationTime:  cfg.JWT.JWTAccessTokenExpTime,
 			JWTRefreshExpirationTime: cfg.JWT.JWTRefreshTokenExpTime,
@@ -88,10 +153,35 @@ func Run(cfg *config.Config) {
 	}()
 	log.Info().Msgf("Successfully run http server on %s", cfg.HTTPServer.Addr)
 
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	sched := scheduler.New(
+		cfg.Scheduler.Interval,
+		log,
+		scheduler.Job{Name: "task_series", Run: taskSeriesService.GenerateDue},
+		scheduler.Job{Name: "task_publish", Run: taskService.PublishDue},
+		scheduler.Job{Name: "trash_purge", Run: trashService.Purge},
+		scheduler.Job{Name: "peer_review_assign", Run: peerReviewService.AssignDue},
+	)
+	go sched.Run(schedulerCtx)
+	log.Info().Msgf("Successfully run scheduler every %s", cfg.Scheduler.Interval)
+
+	// Checks can take long, so they get a scheduler of their own and do not
+	// hold up the other jobs.
+	checkSched := scheduler.New(
+		cfg.Checker.Interval,
+		log,
+		scheduler.Job{Name: "answer_checks", Run: checkerService.RunPending},
+	)
+	go checkSched.Run(schedulerCtx)
+	log.Info().Msgf("Successfully run answer checks every %s", cfg.Checker.Interval)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGINT, syscall.SIGKILL)
 	<-quit
 
+	stopScheduler()
+	log.Info().Msg("Scheduler successfully stopped")
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
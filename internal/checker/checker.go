@@ -0,0 +1,45 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	StatusPassed       = "passed"
+	StatusWrongAnswer  = "wrong_answer"
+	StatusTimeout      = "timeout"
+	StatusRuntimeError = "runtime_error"
+)
+
+var ErrNoTests = errors.New("tests archive contains no tests")
+
+// File is a stored file handed to a checker: Path is where it lives on disk
+// and Name is the name it was uploaded with.
+type File struct {
+	Path string
+	Name string
+}
+
+type Request struct {
+	Command     string
+	Submission  []File
+	Tests       File
+	Timeout     time.Duration
+	MemoryLimit int64
+}
+
+type TestResult struct {
+	Name     string
+	Status   string
+	Duration time.Duration
+	Output   string
+}
+
+// Checker runs a submission against the tests of a task. An error means the
+// check itself could not be carried out; failing tests are reported through
+// the results.
+type Checker interface {
+	Check(ctx context.Context, req Request) ([]TestResult, error)
+}
@@ -0,0 +1,307 @@
+package sandbox
+
+import (
+	"archive/zip"
+	"backend/internal/checker"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	outputLimit      = 1 << 20
+	reportLimit      = 4 << 10
+	maxUnpackedFiles = 10000
+	sandboxPath      = "PATH=/usr/local/bin:/usr/bin:/bin"
+	inputSuffix      = ".in"
+	outputSuffix     = ".out"
+)
+
+var (
+	ErrNoRootFS        = errors.New("sandbox root filesystem is not configured")
+	ErrUnpackedTooBig  = errors.New("unpacked files exceed the size limit")
+	ErrTooManyUnpacked = errors.New("unpacked files exceed the count limit")
+	ErrSetup           = errors.New("sandbox setup failed")
+	ErrUnsupported     = errors.New("sandbox is not supported on this platform")
+)
+
+// Config sets where checks run and the limits shared by every run. RootFS
+// is a directory with the tools the commands need; it is mounted read-only as
+// the root of the sandbox and must contain the /sandbox, /proc and /tmp
+// directories. Zero limits are not applied.
+type Config struct {
+	WorkDir         string
+	RootFS          string
+	MaxProcesses    int64
+	MaxFileSize     int64
+	MaxUnpackedSize int64
+}
+
+// Sandbox runs the teacher's command in a scratch copy of the submission,
+// once per test: the test's .in file is fed to stdin and stdout is compared
+// with the matching .out file. Every run is limited in time, memory,
+// processes and written file size, sees nothing but the root filesystem and
+// the submission and has no network. Only Linux is supported; elsewhere every
+// run fails with ErrUnsupported.
+type Sandbox struct {
+	cfg Config
+}
+
+func New(cfg Config) *Sandbox {
+	return &Sandbox{cfg: cfg}
+}
+
+func (s *Sandbox) Check(
+	ctx context.Context,
+	req checker.Request,
+) ([]checker.TestResult, error) {
+	dir, err := os.MkdirTemp(s.cfg.WorkDir, "check-*")
+	if err != nil {
+		return nil, fmt.Errorf("os.MkdirTemp: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Only srcDir is mounted into the sandbox, so the expected outputs in
+	// testsDir stay out of the submission's reach.
+	srcDir := filepath.Join(dir, "src")
+	testsDir := filepath.Join(dir, "tests")
+
+	q := newQuota(s.cfg.MaxUnpackedSize)
+	for _, file := range req.Submission {
+		if strings.EqualFold(filepath.Ext(file.Name), ".zip") {
+			err = unzip(file.Path, srcDir, q)
+		} else {
+			err = copyFile(file.Path, filepath.Join(srcDir, filepath.Base(file.Name)), q)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("prepare %s: %w", file.Name, err)
+		}
+	}
+	if err = unzip(req.Tests.Path, testsDir, q); err != nil {
+		return nil, fmt.Errorf("unzip: %w", err)
+	}
+
+	inputs, err := filepath.Glob(filepath.Join(testsDir, "*"+inputSuffix))
+	if err != nil {
+		return nil, fmt.Errorf("filepath.Glob: %w", err)
+	}
+	if len(inputs) == 0 {
+		return nil, checker.ErrNoTests
+	}
+	sort.Strings(inputs)
+
+	results := make([]checker.TestResult, 0, len(inputs))
+	for _, input := range inputs {
+		result, err := s.runTest(ctx, req, srcDir, input)
+		if err != nil {
+			return nil, fmt.Errorf("s.runTest: %w", err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *Sandbox) runTest(
+	ctx context.Context,
+	req checker.Request,
+	srcDir, input string,
+) (checker.TestResult, error) {
+	name := strings.TrimSuffix(filepath.Base(input), inputSuffix)
+
+	expected, err := os.ReadFile(strings.TrimSuffix(input, inputSuffix) + outputSuffix)
+	if err != nil {
+		return checker.TestResult{}, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	stdin, err := os.Open(input)
+	if err != nil {
+		return checker.TestResult{}, fmt.Errorf("os.Open: %w", err)
+	}
+	defer stdin.Close()
+
+	runCtx, cancel := context.WithTimeout(ctx, req.Timeout)
+	defer cancel()
+
+	var stdout, stderr limitedBuffer
+	stdout.limit, stderr.limit = outputLimit, reportLimit
+
+	cmd, setupErr, err := s.command(runCtx, req, srcDir)
+	if err != nil {
+		return checker.TestResult{}, fmt.Errorf("s.command: %w", err)
+	}
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+
+	if err = cmd.Start(); err != nil {
+		_ = setupErr()
+		return checker.TestResult{}, fmt.Errorf("cmd.Start: %w", err)
+	}
+	started := time.Now()
+	err = cmd.Wait()
+	result := checker.TestResult{Name: name, Duration: time.Since(started)}
+	if err := setupErr(); err != nil {
+		return checker.TestResult{}, err
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		result.Status = checker.StatusTimeout
+	case err != nil && !errors.As(err, &exitErr):
+		return checker.TestResult{}, fmt.Errorf("cmd.Wait: %w", err)
+	case err != nil:
+		result.Status = checker.StatusRuntimeError
+		result.Output = stderr.String()
+	case stdout.truncated:
+		result.Status = checker.StatusWrongAnswer
+		result.Output = "output limit exceeded"
+	case normalizeOutput(stdout.String()) != normalizeOutput(string(expected)):
+		result.Status = checker.StatusWrongAnswer
+		result.Output = truncate(stdout.String(), reportLimit)
+	default:
+		result.Status = checker.StatusPassed
+	}
+	return result, nil
+}
+
+// normalizeOutput ignores trailing whitespace on every line and trailing
+// empty lines, which differ between platforms and are rarely meaningful.
+func normalizeOutput(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	return s[:limit]
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the
+// rest without failing the writer, so a chatty process is not killed by a
+// broken pipe.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		b.truncated = true
+		b.buf.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
+
+// quota is what is left of the size and the number of files a check may
+// unpack, so that an archive cannot fill the disk.
+type quota struct {
+	size  int64
+	files int64
+}
+
+// newQuota allows size bytes in total, or any size when it is not positive.
+func newQuota(size int64) *quota {
+	if size <= 0 {
+		// Leaves room for the byte writeFile reads past the limit.
+		size = math.MaxInt64 - 1
+	}
+	return &quota{size: size, files: maxUnpackedFiles}
+}
+
+func unzip(src, dst string, q *quota) error {
+	r, err := zip.OpenReader(src)
+	if err != nil {
+		return fmt.Errorf("zip.OpenReader: %w", err)
+	}
+	defer r.Close()
+
+	root := filepath.Clean(dst) + string(os.PathSeparator)
+	for _, f := range r.File {
+		path := filepath.Join(dst, f.Name)
+		if !strings.HasPrefix(path, root) {
+			return fmt.Errorf("illegal path in archive: %s", f.Name)
+		}
+
+		if f.FileInfo().IsDir() {
+			if err = os.MkdirAll(path, 0o755); err != nil {
+				return fmt.Errorf("os.MkdirAll: %w", err)
+			}
+			continue
+		}
+		if err = extractFile(f, path, q); err != nil {
+			return fmt.Errorf("extractFile: %w", err)
+		}
+	}
+	return nil
+}
+
+func extractFile(f *zip.File, path string, q *quota) error {
+	in, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("f.Open: %w", err)
+	}
+	defer in.Close()
+
+	return writeFile(in, path, f.Mode().Perm()|0o600, q)
+}
+
+func copyFile(src, dst string, q *quota) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("os.Open: %w", err)
+	}
+	defer in.Close()
+
+	return writeFile(in, dst, 0o644, q)
+}
+
+// writeFile writes r to path and charges it to the quota.
+func writeFile(r io.Reader, path string, mode os.FileMode, q *quota) error {
+	if q.files--; q.files < 0 {
+		return ErrTooManyUnpacked
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("os.MkdirAll: %w", err)
+	}
+
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("os.OpenFile: %w", err)
+	}
+
+	// One byte over the limit tells a file that fits exactly from one that
+	// does not.
+	n, err := io.Copy(out, io.LimitReader(r, q.size+1))
+	if err != nil {
+		out.Close()
+		return fmt.Errorf("io.Copy: %w", err)
+	}
+	if q.size -= n; q.size < 0 {
+		out.Close()
+		return ErrUnpackedTooBig
+	}
+	return out.Close()
+}
@@ -0,0 +1,241 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"backend/internal/checker"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// initArg makes the backend binary, started again in the sandbox
+	// namespaces, set the sandbox up and exec the command instead of
+	// running the server.
+	initArg = "sandbox-init"
+	// statusFd is the pipe the init process reports setup errors through.
+	// It is closed on exec, so the parent reads nothing when setup succeeds.
+	statusFd       = 3
+	initFailedCode = 125
+	sandboxDir     = "/sandbox"
+	tmpSize        = "64m"
+)
+
+// Securebits that stop uid 0 from gaining capabilities on exec, see
+// capabilities(7).
+const (
+	secbitNoRoot       = 1 << 0
+	secbitNoRootLocked = 1 << 1
+)
+
+var devices = []string{"/dev/null", "/dev/zero", "/dev/random", "/dev/urandom"}
+
+func init() {
+	if len(os.Args) > 1 && os.Args[1] == initArg {
+		runInit(os.Args[2:])
+	}
+}
+
+// command starts the backend binary again as the init process of fresh user,
+// mount, pid, network, ipc and uts namespaces. The new network namespace only
+// has a loopback interface that is down, so the command has no network
+// access at all. The returned function reports a failed setup once the
+// process has exited.
+func (s *Sandbox) command(
+	ctx context.Context,
+	req checker.Request,
+	srcDir string,
+) (*exec.Cmd, func() error, error) {
+	if s.cfg.RootFS == "" {
+		return nil, nil, ErrNoRootFS
+	}
+
+	status, statusWriter, err := os.Pipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("os.Pipe: %w", err)
+	}
+
+	cmd := exec.CommandContext(
+		ctx, "/proc/self/exe", initArg,
+		s.cfg.RootFS,
+		srcDir,
+		strconv.FormatInt(req.MemoryLimit, 10),
+		strconv.FormatInt(s.cfg.MaxProcesses, 10),
+		strconv.FormatInt(s.cfg.MaxFileSize, 10),
+		req.Command,
+	)
+	cmd.Env = []string{sandboxPath, "HOME=" + sandboxDir}
+	cmd.ExtraFiles = []*os.File{statusWriter}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid:   true,
+		Pdeathsig: syscall.SIGKILL,
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID |
+			syscall.CLONE_NEWNET | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+		UidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: os.Getuid(), Size: 1},
+		},
+		GidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: os.Getgid(), Size: 1},
+		},
+	}
+	// Killing the init process is enough: the kernel kills everything else
+	// in its pid namespace with it.
+	cmd.Cancel = func() error { return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) }
+
+	setupErr := func() error {
+		statusWriter.Close()
+		defer status.Close()
+
+		msg, err := io.ReadAll(status)
+		if err != nil {
+			return fmt.Errorf("io.ReadAll: %w", err)
+		}
+		if len(msg) > 0 {
+			return fmt.Errorf("%w: %s", ErrSetup, msg)
+		}
+		return nil
+	}
+	return cmd, setupErr, nil
+}
+
+// runInit runs in the sandbox namespaces as their root. It never returns:
+// it either execs the command or reports why it could not.
+func runInit(args []string) {
+	// Securebits and no_new_privs belong to the thread that execs.
+	runtime.LockOSThread()
+
+	err := enter(args)
+	status := os.NewFile(statusFd, "status")
+	fmt.Fprint(status, err)
+	os.Exit(initFailedCode)
+}
+
+func enter(args []string) error {
+	if len(args) != 6 {
+		return fmt.Errorf("expected 6 arguments, got %d", len(args))
+	}
+	rootFS, srcDir, command := args[0], args[1], args[5]
+
+	limits := make([]uint64, 0, 3)
+	for _, arg := range args[2:5] {
+		n, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("strconv.ParseUint: %w", err)
+		}
+		limits = append(limits, n)
+	}
+
+	if err := mountRoot(rootFS, srcDir); err != nil {
+		return fmt.Errorf("mountRoot: %w", err)
+	}
+	if err := setLimits(limits[0], limits[1], limits[2]); err != nil {
+		return fmt.Errorf("setLimits: %w", err)
+	}
+	if err := dropPrivileges(); err != nil {
+		return fmt.Errorf("dropPrivileges: %w", err)
+	}
+
+	syscall.CloseOnExec(statusFd)
+	err := unix.Exec("/bin/sh", []string{"/bin/sh", "-c", command}, os.Environ())
+	return fmt.Errorf("unix.Exec: %w", err)
+}
+
+// mountRoot makes rootFS the read-only root of the mount namespace, with the
+// submission mounted at /sandbox, a private /proc and /tmp and the harmless
+// devices the rootfs has placeholders for. The host filesystem, the tests
+// included, is detached and out of reach afterwards.
+func mountRoot(rootFS, srcDir string) error {
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("unix.Mount: private: %w", err)
+	}
+	// pivot_root needs the new root to be a mount point.
+	if err := unix.Mount(rootFS, rootFS, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+		return fmt.Errorf("unix.Mount: %s: %w", rootFS, err)
+	}
+
+	mounts := []struct {
+		source string
+		target string
+		fstype string
+		flags  uintptr
+		data   string
+	}{
+		{source: srcDir, target: sandboxDir, flags: unix.MS_BIND},
+		{source: "proc", target: "/proc", fstype: "proc", flags: unix.MS_NOSUID | unix.MS_NODEV | unix.MS_NOEXEC},
+		{source: "tmpfs", target: "/tmp", fstype: "tmpfs", flags: unix.MS_NOSUID | unix.MS_NODEV, data: "size=" + tmpSize + ",mode=1777"},
+	}
+	for _, m := range mounts {
+		if err := unix.Mount(m.source, filepath.Join(rootFS, m.target), m.fstype, m.flags, m.data); err != nil {
+			return fmt.Errorf("unix.Mount: %s: %w", m.target, err)
+		}
+	}
+	for _, device := range devices {
+		target := filepath.Join(rootFS, device)
+		if _, err := os.Stat(target); err != nil {
+			continue
+		}
+		if err := unix.Mount(device, target, "", unix.MS_BIND, ""); err != nil {
+			return fmt.Errorf("unix.Mount: %s: %w", device, err)
+		}
+	}
+
+	if err := unix.Chdir(rootFS); err != nil {
+		return fmt.Errorf("unix.Chdir: %w", err)
+	}
+	if err := unix.PivotRoot(".", "."); err != nil {
+		return fmt.Errorf("unix.PivotRoot: %w", err)
+	}
+	if err := unix.Unmount(".", unix.MNT_DETACH); err != nil {
+		return fmt.Errorf("unix.Unmount: %w", err)
+	}
+	if err := unix.Mount("", "/", "", unix.MS_BIND|unix.MS_REMOUNT|unix.MS_RDONLY|unix.MS_NOSUID|unix.MS_NODEV, ""); err != nil {
+		return fmt.Errorf("unix.Mount: read-only: %w", err)
+	}
+	if err := unix.Chdir(sandboxDir); err != nil {
+		return fmt.Errorf("unix.Chdir: %w", err)
+	}
+	return nil
+}
+
+// setLimits caps the address space, the number of processes and the size of
+// written files. RLIMIT_NPROC counts every process of the backend's user,
+// the sandboxes running at the same time included.
+func setLimits(memory, processes, fileSize uint64) error {
+	for _, l := range []struct {
+		resource int
+		value    uint64
+	}{
+		{resource: unix.RLIMIT_AS, value: memory},
+		{resource: unix.RLIMIT_NPROC, value: processes},
+		{resource: unix.RLIMIT_FSIZE, value: fileSize},
+	} {
+		if l.value == 0 {
+			continue
+		}
+		if err := unix.Setrlimit(l.resource, &unix.Rlimit{Cur: l.value, Max: l.value}); err != nil {
+			return fmt.Errorf("unix.Setrlimit: %d: %w", l.resource, err)
+		}
+	}
+	return nil
+}
+
+// dropPrivileges makes the command run without any capability, so it cannot
+// undo the mounts above, although it keeps uid 0 in the user namespace.
+func dropPrivileges() error {
+	if err := unix.Prctl(unix.PR_SET_SECUREBITS, secbitNoRoot|secbitNoRootLocked, 0, 0, 0); err != nil {
+		return fmt.Errorf("unix.Prctl: securebits: %w", err)
+	}
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("unix.Prctl: no_new_privs: %w", err)
+	}
+	return nil
+}
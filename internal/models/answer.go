@@ -3,10 +3,14 @@ package models
 import "time"
 
 type Answer struct {
-	Id        int64      `json:"id"`
-	UserId    int64      `json:"userId"`
-	TaskId    int64      `json:"taskId"`
-	Comment   *string    `json:"comment,omitempty"`
-	CreatedAt time.Time  `json:"createdAt"`
-	UpdatedAt *time.Time `json:"updatedAt"`
+	Id          int64      `json:"id"`
+	UserId      int64      `json:"userId"`
+	TaskId      int64      `json:"taskId"`
+	TeamId      *int64     `json:"teamId,omitempty"`
+	Comment     *string    `json:"comment,omitempty"`
+	CommentHtml *string    `json:"commentHtml,omitempty"`
+	IsLate      bool       `json:"isLate"`
+	LateSeconds int64      `json:"lateSeconds"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   *time.Time `json:"updatedAt"`
 }
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+const (
+	CheckerSandbox = "sandbox"
+)
+
+const (
+	AnswerCheckPending = "pending"
+	AnswerCheckRunning = "running"
+	AnswerCheckDone    = "done"
+	AnswerCheckFailed  = "failed"
+)
+
+type TaskChecker struct {
+	TaskId      int64      `json:"taskId"`
+	Kind        string     `json:"kind"`
+	Command     string     `json:"command"`
+	TestsFileId int64      `json:"testsFileId"`
+	TimeoutMs   int64      `json:"timeoutMs"`
+	MemoryMb    int64      `json:"memoryMb"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   *time.Time `json:"updatedAt"`
+}
+
+type AnswerCheck struct {
+	Id           int64         `json:"id"`
+	AnswerId     int64         `json:"answerId"`
+	TaskId       int64         `json:"taskId"`
+	Status       string        `json:"status"`
+	ProposedMark *int64        `json:"proposedMark"`
+	Error        *string       `json:"error,omitempty"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	StartedAt    *time.Time    `json:"startedAt"`
+	FinishedAt   *time.Time    `json:"finishedAt"`
+	Results      []CheckResult `json:"results,omitempty"`
+}
+
+type CheckResult struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	DurationMs int64   `json:"durationMs"`
+	Output     *string `json:"output,omitempty"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Comment is a message in a discussion thread. Comments with an AnswerId are
+// private to the answer's author and the staff, the others are visible to
+// everyone assigned the task.
+type Comment struct {
+	Id         int64      `json:"id"`
+	TaskId     int64      `json:"taskId"`
+	AnswerId   *int64     `json:"answerId"`
+	AuthorId   int64      `json:"authorId"`
+	AuthorName string     `json:"authorName"`
+	Text       string     `json:"text"`
+	TextHtml   string     `json:"textHtml"`
+	Files      []File     `json:"files"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  *time.Time `json:"updatedAt"`
+}
+
+// CommentUnread counts the comments of other users the user has not read yet
+// in one thread.
+type CommentUnread struct {
+	TaskId   int64  `json:"taskId"`
+	AnswerId *int64 `json:"answerId"`
+	Unread   int64  `json:"unread"`
+}
@@ -11,4 +11,6 @@ type File struct {
 	UpdatedAt *time.Time `json:"updatedAt"`
 	TaskId    *int64     `json:"taskId"`
 	AnswerId  *int64     `json:"answerId"`
+	CommentId *int64     `json:"commentId,omitempty"`
+	CreatedBy *int64     `json:"createdBy,omitempty"`
 }
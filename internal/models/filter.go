@@ -1,9 +1,40 @@
 package models
 
+const (
+	FilterOpEq   = "eq"
+	FilterOpIn   = "in"
+	FilterOpLt   = "lt"
+	FilterOpLte  = "lte"
+	FilterOpGt   = "gt"
+	FilterOpGte  = "gte"
+	FilterOpLike = "like"
+)
+
+var FilterOps = []string{
+	FilterOpEq,
+	FilterOpIn,
+	FilterOpLt,
+	FilterOpLte,
+	FilterOpGt,
+	FilterOpGte,
+	FilterOpLike,
+}
+
 type Filter struct {
+	Search string       `json:"search"`
+	Items  []FilterItem `json:"items"`
+	Sort   []SortItem   `json:"sort"`
 }
 
+// FilterItem compares Field with Value using Op, equality when Op is empty.
+// For FilterOpIn the Value is a comma separated list.
 type FilterItem struct {
 	Field string `json:"field"`
+	Op    string `json:"op"`
 	Value string `json:"value"`
 }
+
+type SortItem struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
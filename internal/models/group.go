@@ -5,6 +5,7 @@ import "time"
 type Group struct {
 	Id        int64      `json:"id"`
 	Name      string     `json:"name"`
+	OrgUnitId *int64     `json:"orgUnitId"`
 	CreatedAt time.Time  `json:"createdAt"`
 	UpdatedAt *time.Time `json:"updatedAt"`
 }
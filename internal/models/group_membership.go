@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type GroupMembership struct {
+	Id            int64      `json:"id"`
+	UserId        int64      `json:"userId"`
+	GroupId       int64      `json:"groupId"`
+	GroupName     string     `json:"groupName"`
+	EffectiveFrom time.Time  `json:"effectiveFrom"`
+	EffectiveTill *time.Time `json:"effectiveTill"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+const (
+	OrgUnitKindFaculty    = "faculty"
+	OrgUnitKindDepartment = "department"
+)
+
+type OrgUnit struct {
+	Id        int64      `json:"id"`
+	ParentId  *int64     `json:"parentId"`
+	Kind      string     `json:"kind"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt"`
+}
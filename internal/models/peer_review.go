@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+const (
+	PeerReviewStateAssigned  = "assigned"
+	PeerReviewStateSubmitted = "submitted"
+	PeerReviewStateRejected  = "rejected"
+)
+
+// PeerReviewSettings turns on peer review for a task. Reviews are graded with
+// RubricId when set and are free text with an optional score otherwise.
+// MarkWeight is the percentage of the raw mark taken from the average peer
+// score, zero keeping reviews out of the mark.
+type PeerReviewSettings struct {
+	TaskId           int64      `json:"taskId"`
+	ReviewsPerAnswer int64      `json:"reviewsPerAnswer"`
+	RubricId         *int64     `json:"rubricId"`
+	MarkWeight       int64      `json:"markWeight"`
+	AssignedAt       *time.Time `json:"assignedAt"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        *time.Time `json:"updatedAt"`
+}
+
+// PeerReview is the review of an answer by another student. Students never
+// see who wrote the answer or the review, so ReviewerId and ReviewerName are
+// only filled for staff.
+type PeerReview struct {
+	Id                int64           `json:"id"`
+	TaskId            int64           `json:"taskId"`
+	AnswerId          int64           `json:"answerId"`
+	ReviewerId        int64           `json:"reviewerId,omitempty"`
+	ReviewerName      string          `json:"reviewerName,omitempty"`
+	State             string          `json:"state"`
+	Score             *int64          `json:"score"`
+	Text              *string         `json:"text"`
+	TextHtml          *string         `json:"textHtml"`
+	Criteria          []MarkCriterion `json:"criteria,omitempty"`
+	ModerationComment *string         `json:"moderationComment"`
+	CreatedAt         time.Time       `json:"createdAt"`
+	SubmittedAt       *time.Time      `json:"submittedAt"`
+	ModeratedAt       *time.Time      `json:"moderatedAt"`
+}
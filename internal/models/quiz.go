@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+const (
+	QuestionSingleChoice   = "single_choice"
+	QuestionMultipleChoice = "multiple_choice"
+	QuestionNumeric        = "numeric"
+	QuestionShortText      = "short_text"
+)
+
+type QuestionBank struct {
+	Id          int64      `json:"id"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description"`
+	CreatedBy   int64      `json:"createdBy"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   *time.Time `json:"updatedAt"`
+	Questions   []Question `json:"questions,omitempty"`
+}
+
+// Question holds both the prompt and its answer key: CorrectOptions for the
+// choice kinds, Answer and Tolerance for numeric and AcceptedAnswers for short
+// text questions.
+type Question struct {
+	Id              int64    `json:"id"`
+	BankId          int64    `json:"bankId"`
+	Position        int64    `json:"position"`
+	Kind            string   `json:"kind"`
+	Text            string   `json:"text"`
+	Points          int64    `json:"points"`
+	Options         []string `json:"options,omitempty"`
+	CorrectOptions  []int64  `json:"correctOptions,omitempty"`
+	Answer          *float64 `json:"answer,omitempty"`
+	Tolerance       *float64 `json:"tolerance,omitempty"`
+	AcceptedAnswers []string `json:"acceptedAnswers,omitempty"`
+}
+
+type TaskQuiz struct {
+	TaskId        int64      `json:"taskId"`
+	BankId        int64      `json:"bankId"`
+	QuestionCount *int64     `json:"questionCount"`
+	Shuffle       bool       `json:"shuffle"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     *time.Time `json:"updatedAt"`
+}
+
+type QuizResponse struct {
+	QuestionId int64    `json:"questionId"`
+	Choices    []int64  `json:"choices,omitempty"`
+	Number     *float64 `json:"number,omitempty"`
+	Text       *string  `json:"text,omitempty"`
+	IsCorrect  bool     `json:"isCorrect"`
+	Points     int64    `json:"points"`
+}
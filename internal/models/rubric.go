@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+type Rubric struct {
+	Id          int64             `json:"id"`
+	Name        string            `json:"name"`
+	Description *string           `json:"description"`
+	CreatedBy   int64             `json:"createdBy"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	UpdatedAt   *time.Time        `json:"updatedAt"`
+	Criteria    []RubricCriterion `json:"criteria,omitempty"`
+}
+
+type RubricCriterion struct {
+	Id          int64         `json:"id"`
+	RubricId    int64         `json:"rubricId"`
+	Position    int64         `json:"position"`
+	Name        string        `json:"name"`
+	Description *string       `json:"description"`
+	Levels      []RubricLevel `json:"levels"`
+}
+
+type RubricLevel struct {
+	Id          int64   `json:"id"`
+	CriterionId int64   `json:"criterionId"`
+	Position    int64   `json:"position"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	Points      int64   `json:"points"`
+}
+
+type MarkCriterion struct {
+	CriterionId   int64   `json:"criterionId"`
+	CriterionName string  `json:"criterionName,omitempty"`
+	LevelId       int64   `json:"levelId"`
+	LevelName     string  `json:"levelName,omitempty"`
+	Points        int64   `json:"points"`
+	Comment       *string `json:"comment,omitempty"`
+}
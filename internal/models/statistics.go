@@ -1,7 +1,15 @@
 package models
 
+const (
+	StatisticsLevelUser  = "user"
+	StatisticsLevelGroup = "group"
+)
+
 type Statistics struct {
-	UserName  string `json:"userName"`
-	GroupName string `json:"groupName"`
-	Score     int64  `json:"score"`
+	UserName   string  `json:"userName,omitempty"`
+	GroupName  string  `json:"groupName,omitempty"`
+	UnitName   string  `json:"unitName,omitempty"`
+	Score      int64   `json:"score"`
+	MaxScore   int64   `json:"maxScore"`
+	Percentage float64 `json:"percentage"`
 }
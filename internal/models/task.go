@@ -2,13 +2,59 @@ package models
 
 import "time"
 
+const (
+	LatePolicyNone          = "none"
+	LatePolicyFixed         = "fixed"
+	LatePolicyPercentPerDay = "percent_per_day"
+	LatePolicyGrace         = "grace"
+)
+
+const DefaultTaskCost = 100
+
+const (
+	TaskStateDraft     = "draft"
+	TaskStateScheduled = "scheduled"
+	TaskStatePublished = "published"
+)
+
+const (
+	TaskStatusNotStarted = "not_started"
+	TaskStatusSubmitted  = "submitted"
+	TaskStatusLate       = "late"
+	TaskStatusGraded     = "graded"
+	TaskStatusReturned   = "returned"
+)
+
 type Task struct {
-	Id            int64      `json:"id"`
-	Title         string     `json:"title"`
-	Text          *string    `json:"text"`
-	CreatedBy     int64      `json:"createdBy"`
-	CreatedAt     time.Time  `json:"createdAt"`
-	UpdatedAt     *time.Time `json:"updatedAt"`
-	EffectiveFrom time.Time  `json:"effectiveFrom"`
-	EffectiveTill time.Time  `json:"effectiveTill"`
+	Id             int64      `json:"id"`
+	Title          string     `json:"title"`
+	Text           *string    `json:"text"`
+	TextHtml       *string    `json:"textHtml"`
+	CreatedBy      int64      `json:"createdBy"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      *time.Time `json:"updatedAt"`
+	EffectiveFrom  time.Time  `json:"effectiveFrom"`
+	EffectiveTill  time.Time  `json:"effectiveTill"`
+	LatePolicy     string     `json:"latePolicy"`
+	LatePenalty    int64      `json:"latePenalty"`
+	LateGraceHours int64      `json:"lateGraceHours"`
+	Cost           int64      `json:"cost"`
+	RubricId       *int64     `json:"rubricId"`
+	IsTemplate     bool       `json:"isTemplate"`
+	SeriesId       *int64     `json:"seriesId"`
+	SeriesIndex    int64      `json:"seriesIndex"`
+	State          string     `json:"state"`
+	PublishAt      *time.Time `json:"publishAt"`
+	PublishedAt    *time.Time `json:"publishedAt"`
+}
+
+// UserTask is a task as seen by the student it is assigned to. Returned means
+// the answer was changed after it had been graded and waits for a new mark.
+type UserTask struct {
+	Task
+	Status   string `json:"status"`
+	AnswerId *int64 `json:"answerId"`
+	Mark     *int64 `json:"mark"`
+	// LockedBy lists the prerequisites the student has not met yet.
+	LockedBy []TaskPrerequisite `json:"lockedBy"`
 }
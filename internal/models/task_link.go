@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// TaskLink assigns a task to a user or a group. EffectiveFrom and
+// EffectiveTill override the task dates for that assignee when set.
+type TaskLink struct {
+	Id            int64      `json:"id"`
+	TaskId        int64      `json:"taskId"`
+	UserId        *int64     `json:"userId"`
+	GroupId       *int64     `json:"groupId"`
+	EffectiveFrom *time.Time `json:"effectiveFrom"`
+	EffectiveTill *time.Time `json:"effectiveTill"`
+}
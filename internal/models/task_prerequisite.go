@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+const (
+	PrerequisiteSubmitted   = "submitted"
+	PrerequisiteGraded      = "graded"
+	PrerequisiteMarkAtLeast = "mark_at_least"
+)
+
+// TaskPrerequisite keeps TaskId locked for a student until the student meets
+// Condition on PrerequisiteTaskId.
+type TaskPrerequisite struct {
+	Id                 int64     `json:"id"`
+	TaskId             int64     `json:"taskId"`
+	PrerequisiteTaskId int64     `json:"prerequisiteTaskId"`
+	Condition          string    `json:"condition"`
+	MinMark            *int64    `json:"minMark"`
+	CreatedAt          time.Time `json:"createdAt"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type TaskSeries struct {
+	Id           int64      `json:"id"`
+	SourceTaskId int64      `json:"sourceTaskId"`
+	RRule        string     `json:"rrule"`
+	Generated    int64      `json:"generated"`
+	NextRunAt    *time.Time `json:"nextRunAt"`
+	CancelledAt  *time.Time `json:"cancelledAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    *time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// TeamSettings turns a task into a team task: members of a team share one
+// answer and its mark.
+type TeamSettings struct {
+	TaskId     int64      `json:"taskId"`
+	MinSize    int64      `json:"minSize"`
+	MaxSize    int64      `json:"maxSize"`
+	SelfSignup bool       `json:"selfSignup"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  *time.Time `json:"updatedAt"`
+}
+
+type Team struct {
+	Id        int64        `json:"id"`
+	TaskId    int64        `json:"taskId"`
+	Name      string       `json:"name"`
+	Members   []TeamMember `json:"members"`
+	CreatedAt time.Time    `json:"createdAt"`
+}
+
+// TeamMember is a member of a team. MarkAdjustment is added to the mark of
+// the team answer for this member only, keeping the result between zero and
+// the task cost.
+type TeamMember struct {
+	UserId            int64     `json:"userId"`
+	UserName          string    `json:"userName"`
+	MarkAdjustment    int64     `json:"markAdjustment"`
+	AdjustmentComment *string   `json:"adjustmentComment,omitempty"`
+	JoinedAt          time.Time `json:"joinedAt"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+const (
+	TrashKindTask   = "task"
+	TrashKindAnswer = "answer"
+	TrashKindFile   = "file"
+	TrashKindGroup  = "group"
+)
+
+type TrashItem struct {
+	Kind      string    `json:"kind"`
+	Id        int64     `json:"id"`
+	Title     string    `json:"title"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
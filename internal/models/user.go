@@ -8,6 +8,7 @@ const (
 	UserRoleAdministrator = 1
 	UserRoleTeacher       = 2
 	UserRoleStudent       = 3
+	UserRoleObserver      = 4
 )
 
 type User struct {
@@ -15,10 +16,11 @@ type User struct {
 	GroupId    *int64     `json:"groupId,omitempty"`
 	RoleId     int64      `json:"roleId"`
 	Email      string     `json:"email"`
-	Password   string     `json:"password"`
+	Password   string     `json:"-"`
 	FirstName  string     `json:"firstName"`
 	LastName   string     `json:"lastName"`
 	MiddleName *string    `json:"middleName"`
+	IsActive   bool       `json:"isActive"`
 	CreatedAt  time.Time  `json:"createdAt"`
 	UpdatedAt  *time.Time `json:"updatedAt"`
 }
@@ -3,6 +3,7 @@ package repo
 import (
 	"backend/internal/models"
 	"context"
+	"time"
 )
 
 type AuthRepo interface {
@@ -13,9 +14,11 @@ type AuthRepo interface {
 type UsersRepo interface {
 	GetById(ctx context.Context, id int64) (models.User, error)
 	GetByCredentials(ctx context.Context, opts UsersRepoGetByCredentialsOpts) (models.User, error)
-	GetListByRoleId(ctx context.Context, opts UsersRepoGetListByRoleIdOpts) ([]models.User, error)
-	GetListByRoleIdCount(ctx context.Context, opts UsersRepoGetListByRoleIdOpts) (int, error)
+	GetList(ctx context.Context, opts UsersRepoGetListOpts) ([]models.User, error)
+	GetCount(ctx context.Context, filter models.Filter) (int64, error)
 	Create(ctx context.Context, opts UsersRepoCreateOpts) (models.User, error)
+	GetMemberships(ctx context.Context, userId int64) ([]models.GroupMembership, error)
+	Transfer(ctx context.Context, opts UsersRepoTransferOpts) error
 }
 
 type FilesRepo interface {
@@ -31,8 +34,9 @@ type FilesRepo interface {
 type AnswersRepo interface {
 	GetById(ctx context.Context, id int64) (models.Answer, error)
 	GetList(ctx context.Context, opts AnswersRepoGetListOpts) ([]models.Answer, error)
-	GetCount(ctx context.Context, groupId int64) (int64, error)
+	GetCount(ctx context.Context, opts AnswersRepoGetListOpts) (int64, error)
 	GetByTaskIdAndUserId(ctx context.Context, userId int64, taskId int64) (models.Answer, error)
+	IsVisible(ctx context.Context, id int64, scopeUserId int64) (bool, error)
 	Create(ctx context.Context, opts AnswersRepoCreateOpts) (models.Answer, error)
 	Update(ctx context.Context, opts AnswersRepoUpdateOpts) (models.Answer, error)
 	Delete(ctx context.Context, id int64) error
@@ -42,7 +46,7 @@ type MarkRepo interface {
 	GetById(ctx context.Context, id int64) (models.Mark, error)
 	GetByAnswerId(ctx context.Context, id int64) (models.Mark, error)
 	GetListByUserId(ctx context.Context, opts MarksRepoGetListByUserIdOpts) ([]models.Mark, error)
-	GetCountByUserId(ctx context.Context, userId int64) (int64, error)
+	GetCountByUserId(ctx context.Context, opts MarksRepoGetListByUserIdOpts) (int64, error)
 	Create(ctx context.Context, opts MarksRepoCreateOpts) (models.Mark, error)
 	Update(ctx context.Context, opts MarksRepoUpdateOpts) (models.Mark, error)
 	Delete(ctx context.Context, id int64) error
@@ -50,28 +54,166 @@ type MarkRepo interface {
 
 type TasksRepo interface {
 	GetById(ctx context.Context, id int64) (models.Task, error)
+	GetByIdForUser(ctx context.Context, id, userId int64) (models.Task, error)
+	IsVisible(ctx context.Context, id int64, scopeUserId int64) (bool, error)
+	IsAssigned(ctx context.Context, id int64, userId int64) (bool, error)
+	GetMaxMark(ctx context.Context, id int64) (int64, error)
 	GetListForCreator(ctx context.Context, opts TasksRepoGetListForCreatorOpts) ([]models.Task, error)
-	GetCountForCreator(ctx context.Context, createdBy int64) (int64, error)
-	GetListForUser(ctx context.Context, opts TasksRepoGetListForUserOpts) ([]models.Task, error)
-	GetCountForUser(ctx context.Context, userId, groupId int64) (int64, error)
+	GetCountForCreator(ctx context.Context, opts TasksRepoGetListForCreatorOpts) (int64, error)
+	GetListForUser(ctx context.Context, opts TasksRepoGetListForUserOpts) ([]models.UserTask, error)
+	GetCountForUser(ctx context.Context, opts TasksRepoGetListForUserOpts) (int64, error)
+	GetListForScope(ctx context.Context, opts TasksRepoGetListForScopeOpts) ([]models.Task, error)
+	GetCountForScope(ctx context.Context, opts TasksRepoGetListForScopeOpts) (int64, error)
 	Create(ctx context.Context, opts TasksRepoCreateOpts) (models.Task, error)
 	Update(ctx context.Context, opts TasksRepoUpdateOpts) (models.Task, error)
+	SetState(ctx context.Context, opts TasksRepoSetStateOpts) (models.Task, error)
+	PublishDue(ctx context.Context, now time.Time) ([]models.Task, error)
 	Delete(ctx context.Context, id int64) error
 }
 
+type TaskSeriesRepo interface {
+	GetById(ctx context.Context, id int64) (models.TaskSeries, error)
+	GetDue(ctx context.Context, now time.Time) ([]models.TaskSeries, error)
+	HasInstance(ctx context.Context, id int64, index int64) (bool, error)
+	Create(ctx context.Context, opts TaskSeriesRepoCreateOpts) (models.TaskSeries, error)
+	Update(ctx context.Context, opts TaskSeriesRepoUpdateOpts) (models.TaskSeries, error)
+	Cancel(ctx context.Context, id int64) error
+}
+
+type TaskPrerequisitesRepo interface {
+	GetByTaskId(ctx context.Context, taskId int64) ([]models.TaskPrerequisite, error)
+	GetUnmet(ctx context.Context, userId int64, taskIds []int64) ([]models.TaskPrerequisite, error)
+	Create(ctx context.Context, opts TaskPrerequisitesRepoCreateOpts) (models.TaskPrerequisite, error)
+	Delete(ctx context.Context, taskId, id int64) error
+}
+
 type TaskLinksRepo interface {
+	GetByTaskId(ctx context.Context, taskId int64) ([]models.TaskLink, error)
 	Create(ctx context.Context, opts TaskLinksRepoCreateOpts) error
+	SetWindow(ctx context.Context, opts TaskLinksRepoSetWindowOpts) error
+	GetAssigneeIds(ctx context.Context, taskId int64) ([]int64, error)
 }
 
 type GroupsRepo interface {
 	GetById(ctx context.Context, id int64) (models.Group, error)
 	GetList(ctx context.Context, opts GroupsRepoGetListOpts) ([]models.Group, error)
-	GetCount(ctx context.Context) (int64, error)
+	GetCount(ctx context.Context, opts GroupsRepoGetListOpts) (int64, error)
 	Create(ctx context.Context, opts GroupsRepoCreateOpts) (models.Group, error)
 	Update(ctx context.Context, opts GroupsRepoUpdateOpts) (models.Group, error)
 	Delete(ctx context.Context, id int64) error
+	CreateAssignment(ctx context.Context, opts GroupsRepoAssignmentOpts) error
+	DeleteAssignment(ctx context.Context, opts GroupsRepoAssignmentOpts) error
+	IsAccessible(ctx context.Context, opts GroupsRepoIsAccessibleOpts) (bool, error)
+}
+
+type OrgUnitsRepo interface {
+	GetById(ctx context.Context, id int64) (models.OrgUnit, error)
+	GetList(ctx context.Context) ([]models.OrgUnit, error)
+	Create(ctx context.Context, opts OrgUnitsRepoCreateOpts) (models.OrgUnit, error)
+	Update(ctx context.Context, opts OrgUnitsRepoUpdateOpts) (models.OrgUnit, error)
+	Delete(ctx context.Context, id int64) error
+	HasChildren(ctx context.Context, id int64) (bool, error)
+	HasGroups(ctx context.Context, id int64) (bool, error)
+	IsInSubtree(ctx context.Context, rootId, id int64) (bool, error)
+	IsManagedBy(ctx context.Context, id *int64, userId int64) (bool, error)
+	CreateAdmin(ctx context.Context, opts OrgUnitsRepoAdminOpts) error
+	DeleteAdmin(ctx context.Context, opts OrgUnitsRepoAdminOpts) error
 }
 
 type StatisticsRepo interface {
 	GetStatistics(ctx context.Context, opts GetStatisticsOpts) ([]models.Statistics, error)
 }
+
+type RubricsRepo interface {
+	GetById(ctx context.Context, id int64) (models.Rubric, error)
+	GetList(ctx context.Context, opts RubricsRepoGetListOpts) ([]models.Rubric, error)
+	GetCount(ctx context.Context) (int64, error)
+	Create(ctx context.Context, opts RubricsRepoCreateOpts) (models.Rubric, error)
+	Update(ctx context.Context, opts RubricsRepoUpdateOpts) (models.Rubric, error)
+	Delete(ctx context.Context, id int64) error
+	IsAttached(ctx context.Context, id int64) (bool, error)
+	GetMinCost(ctx context.Context, id int64) (*int64, error)
+	IsGraded(ctx context.Context, id int64) (bool, error)
+}
+
+type QuestionBanksRepo interface {
+	GetById(ctx context.Context, id int64) (models.QuestionBank, error)
+	GetList(ctx context.Context, opts QuestionBanksRepoGetListOpts) ([]models.QuestionBank, error)
+	GetCount(ctx context.Context) (int64, error)
+	Create(ctx context.Context, opts QuestionBanksRepoCreateOpts) (models.QuestionBank, error)
+	Update(ctx context.Context, opts QuestionBanksRepoUpdateOpts) (models.QuestionBank, error)
+	Delete(ctx context.Context, id int64) error
+	IsAttached(ctx context.Context, id int64) (bool, error)
+	IsAnswered(ctx context.Context, id int64) (bool, error)
+}
+
+type QuizzesRepo interface {
+	GetByTaskId(ctx context.Context, taskId int64) (models.TaskQuiz, error)
+	Set(ctx context.Context, opts QuizzesRepoSetOpts) (models.TaskQuiz, error)
+	Delete(ctx context.Context, taskId int64) error
+	IsAnswered(ctx context.Context, taskId int64) (bool, error)
+	GetResponses(ctx context.Context, answerId int64) ([]models.QuizResponse, error)
+	Submit(ctx context.Context, opts QuizzesRepoSubmitOpts) (models.Mark, error)
+}
+
+type CheckersRepo interface {
+	GetByTaskId(ctx context.Context, taskId int64) (models.TaskChecker, error)
+	Set(ctx context.Context, opts CheckersRepoSetOpts) (models.TaskChecker, error)
+	Delete(ctx context.Context, taskId int64) error
+	GetLatestCheck(ctx context.Context, answerId int64) (models.AnswerCheck, error)
+	CreateCheck(ctx context.Context, answerId int64) (models.AnswerCheck, error)
+	ClaimChecks(ctx context.Context, opts CheckersRepoClaimChecksOpts) ([]models.AnswerCheck, error)
+	FinishCheck(ctx context.Context, opts CheckersRepoFinishCheckOpts) error
+}
+
+type TrashRepo interface {
+	GetList(ctx context.Context, opts TrashRepoGetListOpts) ([]models.TrashItem, error)
+	GetCount(ctx context.Context, opts TrashRepoGetListOpts) (int64, error)
+	Restore(ctx context.Context, opts TrashRepoRestoreOpts) error
+	Purge(ctx context.Context, before time.Time) ([]models.File, error)
+}
+
+type TeamsRepo interface {
+	GetSettings(ctx context.Context, taskId int64) (models.TeamSettings, error)
+	SetSettings(ctx context.Context, opts TeamsRepoSetSettingsOpts) (models.TeamSettings, error)
+	DeleteSettings(ctx context.Context, taskId int64) error
+	GetByTaskId(ctx context.Context, taskId int64) ([]models.Team, error)
+	GetById(ctx context.Context, id int64) (models.Team, error)
+	GetByUser(ctx context.Context, taskId, userId int64) (models.Team, error)
+	HasAnswers(ctx context.Context, taskId int64, teamId *int64) (bool, error)
+	Create(ctx context.Context, opts TeamsRepoCreateOpts) (models.Team, error)
+	Delete(ctx context.Context, id int64) error
+	AddMember(ctx context.Context, opts TeamsRepoAddMemberOpts) error
+	RemoveMember(ctx context.Context, teamId, userId int64) error
+	SetAdjustment(ctx context.Context, opts TeamsRepoSetAdjustmentOpts) error
+}
+
+type CommentsRepo interface {
+	GetById(ctx context.Context, id int64) (models.Comment, error)
+	GetList(ctx context.Context, opts CommentsRepoGetListOpts) ([]models.Comment, error)
+	GetCount(ctx context.Context, opts CommentsRepoGetListOpts) (int64, error)
+	Create(ctx context.Context, opts CommentsRepoCreateOpts) (models.Comment, error)
+	Update(ctx context.Context, opts CommentsRepoUpdateOpts) (models.Comment, error)
+	Delete(ctx context.Context, id int64) error
+	MarkRead(ctx context.Context, opts CommentsRepoThreadOpts) error
+	GetUnread(ctx context.Context, opts CommentsRepoGetUnreadOpts) ([]models.CommentUnread, error)
+}
+
+type CalendarRepo interface {
+	GetToken(ctx context.Context, userId int64) (string, error)
+	SetToken(ctx context.Context, userId int64, token string) error
+	GetUserIdByToken(ctx context.Context, token string) (int64, error)
+}
+
+type PeerReviewsRepo interface {
+	GetSettings(ctx context.Context, taskId int64) (models.PeerReviewSettings, error)
+	SetSettings(ctx context.Context, opts PeerReviewsRepoSetSettingsOpts) (models.PeerReviewSettings, error)
+	DeleteSettings(ctx context.Context, taskId int64) error
+	GetDue(ctx context.Context, now time.Time) ([]models.PeerReviewSettings, error)
+	Assign(ctx context.Context, taskId, reviewsPerAnswer int64) (int64, error)
+	GetById(ctx context.Context, id int64) (models.PeerReview, error)
+	GetList(ctx context.Context, opts PeerReviewsRepoGetListOpts) ([]models.PeerReview, error)
+	Submit(ctx context.Context, opts PeerReviewsRepoSubmitOpts) (models.PeerReview, error)
+	Moderate(ctx context.Context, opts PeerReviewsRepoModerateOpts) (models.PeerReview, error)
+	GetScore(ctx context.Context, answerId int64) (*int64, error)
+}
@@ -14,22 +14,28 @@ import (
 )
 
 type answer struct {
-	Id        int64      `db:"id"`
-	UserId    int64      `db:"user_id"`
-	TaskId    int64      `db:"task_id"`
-	Comment   *string    `db:"comment"`
-	CreatedAt time.Time  `db:"created_at"`
-	UpdatedAt *time.Time `db:"updated_at"`
+	Id          int64      `db:"id"`
+	UserId      int64      `db:"user_id"`
+	TaskId      int64      `db:"task_id"`
+	TeamId      *int64     `db:"team_id"`
+	Comment     *string    `db:"comment"`
+	IsLate      bool       `db:"is_late"`
+	LateSeconds int64      `db:"late_seconds"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   *time.Time `db:"updated_at"`
 }
 
 func (a answer) toServiceModel() models.Answer {
 	return models.Answer{
-		Id:        a.Id,
-		UserId:    a.UserId,
-		TaskId:    a.TaskId,
-		Comment:   a.Comment,
-		CreatedAt: a.CreatedAt,
-		UpdatedAt: a.UpdatedAt,
+		Id:          a.Id,
+		UserId:      a.UserId,
+		TaskId:      a.TaskId,
+		TeamId:      a.TeamId,
+		Comment:     a.Comment,
+		IsLate:      a.IsLate,
+		LateSeconds: a.LateSeconds,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
 	}
 }
 
@@ -46,11 +52,17 @@ select
     a.id, 
     a.user_id,
     a.task_id,
+    a.team_id,
     a.comment, 
+    a.is_late, 
+    a.late_seconds, 
     a.created_at, 
     a.updated_at
 from public.answer a
+join public.task t on t.id = a.task_id
 where a.id = $1
+  and a.deleted_at is null
+  and t.deleted_at is null
 `
 
 func (r *AnswersRepo) GetById(
@@ -59,21 +71,54 @@ func (r *AnswersRepo) GetById(
 ) (models.Answer, error) {
 	var a answer
 	if err := r.db.GetContext(ctx, &a, answersRepGetByIdQuery, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Answer{}, repo.ErrNotFound
+		}
 		return models.Answer{}, fmt.Errorf("r.db.GetContext: %w", err)
 	}
 	return a.toServiceModel(), nil
 }
 
+const answersRepoIsVisibleQuery = `
+select public.sees_student($2, a.user_id, a.created_at)
+from public.answer a
+where a.id = $1
+`
+
+// IsVisible tells whether the staff member sees the author of the answer as of
+// the moment it was submitted.
+func (r *AnswersRepo) IsVisible(
+	ctx context.Context,
+	id int64,
+	scopeUserId int64,
+) (bool, error) {
+	var visible bool
+	if err := r.db.GetContext(ctx, &visible, answersRepoIsVisibleQuery, id, scopeUserId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, repo.ErrNotFound
+		}
+		return false, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return visible, nil
+}
+
 const answersRepGetByTaskIdQuery = `
 select 
     a.id, 
     a.user_id,
     a.task_id,
+    a.team_id,
     a.comment, 
+    a.is_late, 
+    a.late_seconds, 
     a.created_at, 
     a.updated_at
 from public.answer a
-where a.task_id = $1 and a.user_id = $2
+where a.task_id = $1
+  and (a.user_id = $2 or a.team_id = public.team_of($1, $2))
+  and a.deleted_at is null
+order by a.id desc
+limit 1
 `
 
 func (r *AnswersRepo) GetByTaskIdAndUserId(
@@ -91,27 +136,58 @@ func (r *AnswersRepo) GetByTaskIdAndUserId(
 	return a.toServiceModel(), nil
 }
 
+var answersRepoFilterSpec = filterSpec{
+	fields: map[string]filterField{
+		"userId":    {column: "a.user_id", parse: parseInt64},
+		"groupId":   {column: "u.group_id", parse: parseInt64},
+		"isLate":    {column: "a.is_late", parse: parseBool},
+		"createdAt": {column: "a.created_at", ops: orderedOps, parse: parseTime},
+	},
+	sortFields: map[string]string{
+		"id":          "a.id",
+		"createdAt":   "a.created_at",
+		"updatedAt":   "a.updated_at",
+		"lateSeconds": "a.late_seconds",
+		"lastName":    "u.last_name",
+	},
+	searchColumn: "(u.last_name || ' ' || u.first_name)",
+	defaultOrder: "a.id desc",
+}
+
 const answersRepoGetListQuery = `
 select 
     a.id, 
     a.task_id,
     a.user_id,
+    a.team_id,
     a.comment, 
+    a.is_late, 
+    a.late_seconds, 
     a.created_at, 
     a.updated_at
 from public.answer a
-where task_id = $1
-order by id desc 
-limit $2
-offset $3
+join public.user u on u.id = a.user_id
+where a.task_id = $1
+  and a.deleted_at is null
+  and ($2::bigint is null or public.sees_student($2, a.user_id, a.created_at)) %s
+order by %s
+limit $%d
+offset $%d
 `
 
 func (r *AnswersRepo) GetList(
 	ctx context.Context,
 	opts repo.AnswersRepoGetListOpts,
 ) ([]models.Answer, error) {
+	where, orderBy, args, err := answersRepoFilterSpec.build(opts.Filter, []any{opts.TaskId, opts.ScopeUserId})
+	if err != nil {
+		return nil, fmt.Errorf("answersRepoFilterSpec.build: %w", err)
+	}
+	args = append(args, opts.Limit, opts.Offset)
+	query := fmt.Sprintf(answersRepoGetListQuery, where, orderBy, len(args)-1, len(args))
+
 	var answers []answer
-	if err := r.db.SelectContext(ctx, &answers, answersRepoGetListQuery, opts.TaskId, opts.Limit, opts.Offset); err != nil {
+	if err = r.db.SelectContext(ctx, &answers, query, args...); err != nil {
 		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
 	}
 	return lo.Map(
@@ -125,23 +201,31 @@ func (r *AnswersRepo) GetList(
 const answersRepoGetCountQuery = `
 select count(*)
 from public.answer a
+join public.user u on u.id = a.user_id
 where a.task_id = $1
+  and a.deleted_at is null
+  and ($2::bigint is null or public.sees_student($2, a.user_id, a.created_at)) %s
 `
 
 func (r *AnswersRepo) GetCount(
 	ctx context.Context,
-	groupId int64,
+	opts repo.AnswersRepoGetListOpts,
 ) (int64, error) {
+	where, _, args, err := answersRepoFilterSpec.build(opts.Filter, []any{opts.TaskId, opts.ScopeUserId})
+	if err != nil {
+		return 0, fmt.Errorf("answersRepoFilterSpec.build: %w", err)
+	}
+
 	var count int64
-	if err := r.db.GetContext(ctx, &count, answersRepoGetCountQuery, groupId); err != nil {
-		return 0, fmt.Errorf("r.db.GetContext: :%w", err)
+	if err = r.db.GetContext(ctx, &count, fmt.Sprintf(answersRepoGetCountQuery, where), args...); err != nil {
+		return 0, fmt.Errorf("r.db.GetContext: %w", err)
 	}
 	return count, nil
 }
 
 const answersRepoCreateQuery = `
-insert into public.answer (task_id, user_id, comment)
-values (:task_id, :user_id, :comment)
+insert into public.answer (task_id, user_id, team_id, comment, is_late, late_seconds)
+values (:task_id, :user_id, :team_id, :comment, :is_late, :late_seconds)
 returning id
 `
 
@@ -150,13 +234,19 @@ func (r *AnswersRepo) Create(
 	opts repo.AnswersRepoCreateOpts,
 ) (models.Answer, error) {
 	rows, err := r.db.NamedQueryContext(ctx, answersRepoCreateQuery, struct {
-		TaskId  int64   `db:"task_id"`
-		UserId  int64   `db:"user_id"`
-		Comment *string `db:"comment"`
+		TaskId      int64   `db:"task_id"`
+		UserId      int64   `db:"user_id"`
+		TeamId      *int64  `db:"team_id"`
+		Comment     *string `db:"comment"`
+		IsLate      bool    `db:"is_late"`
+		LateSeconds int64   `db:"late_seconds"`
 	}{
-		TaskId:  opts.TaskId,
-		UserId:  opts.UserId,
-		Comment: opts.Comment,
+		TaskId:      opts.TaskId,
+		UserId:      opts.UserId,
+		TeamId:      opts.TeamId,
+		Comment:     opts.Comment,
+		IsLate:      opts.IsLate,
+		LateSeconds: opts.LateSeconds,
 	})
 	if err != nil {
 		return models.Answer{}, fmt.Errorf("r.db.NamedExecContext: %w", err)
@@ -178,19 +268,23 @@ func (r *AnswersRepo) Create(
 	}
 
 	return models.Answer{
-		Id:        id,
-		UserId:    0,
-		TaskId:    0,
-		Comment:   nil,
-		CreatedAt: time.Time{},
-		UpdatedAt: nil,
+		Id:          id,
+		UserId:      opts.UserId,
+		TaskId:      opts.TaskId,
+		TeamId:      opts.TeamId,
+		Comment:     opts.Comment,
+		IsLate:      opts.IsLate,
+		LateSeconds: opts.LateSeconds,
+		CreatedAt:   time.Time{},
+		UpdatedAt:   nil,
 	}, nil
 }
 
 const answersRepoUpdateQuery = `
 update public.answer
-set comment = :comment
+set comment = :comment, is_late = :is_late, late_seconds = :late_seconds, updated_at = now()
 where id = :id
+  and deleted_at is null
 `
 
 func (r *AnswersRepo) Update(
@@ -198,11 +292,15 @@ func (r *AnswersRepo) Update(
 	opts repo.AnswersRepoUpdateOpts,
 ) (models.Answer, error) {
 	_, err := r.db.NamedExecContext(ctx, answersRepoUpdateQuery, struct {
-		Id      int64  `db:"id"`
-		Comment string `db:"comment"`
+		Id          int64  `db:"id"`
+		Comment     string `db:"comment"`
+		IsLate      bool   `db:"is_late"`
+		LateSeconds int64  `db:"late_seconds"`
 	}{
-		Id:      opts.Id,
-		Comment: opts.Comment,
+		Id:          opts.Id,
+		Comment:     opts.Comment,
+		IsLate:      opts.IsLate,
+		LateSeconds: opts.LateSeconds,
 	})
 	if err != nil {
 		return models.Answer{}, fmt.Errorf("r.db.NamedExecContext: %w", err)
@@ -212,7 +310,7 @@ func (r *AnswersRepo) Update(
 }
 
 const answersRepoDeleteQuery = `
-delete from public.answer where id = $1
+update public.answer set deleted_at = now() where id = $1 and deleted_at is null
 `
 
 func (r *AnswersRepo) Delete(
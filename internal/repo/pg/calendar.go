@@ -0,0 +1,74 @@
+package pg
+
+import (
+	"backend/internal/repo"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type CalendarRepo struct {
+	db *sqlx.DB
+}
+
+func NewCalendarRepo(db *sqlx.DB) *CalendarRepo {
+	return &CalendarRepo{db: db}
+}
+
+const calendarRepoGetTokenQuery = `
+select token from public.calendar_token where user_id = $1
+`
+
+func (r *CalendarRepo) GetToken(
+	ctx context.Context,
+	userId int64,
+) (string, error) {
+	var token string
+	if err := r.db.GetContext(ctx, &token, calendarRepoGetTokenQuery, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", repo.ErrNotFound
+		}
+		return "", fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return token, nil
+}
+
+const calendarRepoSetTokenQuery = `
+insert into public.calendar_token (user_id, token)
+values ($1, $2)
+on conflict (user_id) do update
+set token = excluded.token,
+    created_at = now()
+`
+
+func (r *CalendarRepo) SetToken(
+	ctx context.Context,
+	userId int64,
+	token string,
+) error {
+	if _, err := r.db.ExecContext(ctx, calendarRepoSetTokenQuery, userId, token); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+const calendarRepoGetUserIdByTokenQuery = `
+select user_id from public.calendar_token where token = $1
+`
+
+func (r *CalendarRepo) GetUserIdByToken(
+	ctx context.Context,
+	token string,
+) (int64, error) {
+	var userId int64
+	if err := r.db.GetContext(ctx, &userId, calendarRepoGetUserIdByTokenQuery, token); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, repo.ErrNotFound
+		}
+		return 0, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return userId, nil
+}
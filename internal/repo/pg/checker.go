@@ -0,0 +1,312 @@
+package pg
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+type taskChecker struct {
+	TaskId      int64      `db:"task_id"`
+	Kind        string     `db:"kind"`
+	Command     string     `db:"command"`
+	TestsFileId int64      `db:"tests_file_id"`
+	TimeoutMs   int64      `db:"timeout_ms"`
+	MemoryMb    int64      `db:"memory_mb"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   *time.Time `db:"updated_at"`
+}
+
+func (c taskChecker) toServiceModel() models.TaskChecker {
+	return models.TaskChecker{
+		TaskId:      c.TaskId,
+		Kind:        c.Kind,
+		Command:     c.Command,
+		TestsFileId: c.TestsFileId,
+		TimeoutMs:   c.TimeoutMs,
+		MemoryMb:    c.MemoryMb,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+type answerCheck struct {
+	Id           int64      `db:"id"`
+	AnswerId     int64      `db:"answer_id"`
+	TaskId       int64      `db:"task_id"`
+	Status       string     `db:"status"`
+	ProposedMark *int64     `db:"proposed_mark"`
+	Error        *string    `db:"error"`
+	CreatedAt    time.Time  `db:"created_at"`
+	StartedAt    *time.Time `db:"started_at"`
+	FinishedAt   *time.Time `db:"finished_at"`
+}
+
+func (c answerCheck) toServiceModel() models.AnswerCheck {
+	return models.AnswerCheck{
+		Id:           c.Id,
+		AnswerId:     c.AnswerId,
+		TaskId:       c.TaskId,
+		Status:       c.Status,
+		ProposedMark: c.ProposedMark,
+		Error:        c.Error,
+		CreatedAt:    c.CreatedAt,
+		StartedAt:    c.StartedAt,
+		FinishedAt:   c.FinishedAt,
+	}
+}
+
+type checkResult struct {
+	Name       string  `db:"name"`
+	Status     string  `db:"status"`
+	DurationMs int64   `db:"duration_ms"`
+	Output     *string `db:"output"`
+}
+
+func (r checkResult) toServiceModel() models.CheckResult {
+	return models.CheckResult{
+		Name:       r.Name,
+		Status:     r.Status,
+		DurationMs: r.DurationMs,
+		Output:     r.Output,
+	}
+}
+
+type CheckersRepo struct {
+	db *sqlx.DB
+}
+
+func NewCheckersRepo(db *sqlx.DB) *CheckersRepo {
+	return &CheckersRepo{db: db}
+}
+
+const checkersRepoGetByTaskIdQuery = `
+select tc.task_id, tc.kind, tc.command, tc.tests_file_id, tc.timeout_ms, tc.memory_mb, tc.created_at, tc.updated_at
+from public.task_checker tc
+where tc.task_id = $1
+`
+
+func (r *CheckersRepo) GetByTaskId(
+	ctx context.Context,
+	taskId int64,
+) (models.TaskChecker, error) {
+	var checker taskChecker
+	if err := r.db.GetContext(ctx, &checker, checkersRepoGetByTaskIdQuery, taskId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TaskChecker{}, repo.ErrNotFound
+		}
+		return models.TaskChecker{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return checker.toServiceModel(), nil
+}
+
+const checkersRepoSetQuery = `
+insert into public.task_checker (task_id, kind, command, tests_file_id, timeout_ms, memory_mb)
+values ($1, $2, $3, $4, $5, $6)
+on conflict (task_id) do update
+set kind = excluded.kind,
+    command = excluded.command,
+    tests_file_id = excluded.tests_file_id,
+    timeout_ms = excluded.timeout_ms,
+    memory_mb = excluded.memory_mb,
+    updated_at = now()
+returning task_id, kind, command, tests_file_id, timeout_ms, memory_mb, created_at, updated_at
+`
+
+func (r *CheckersRepo) Set(
+	ctx context.Context,
+	opts repo.CheckersRepoSetOpts,
+) (models.TaskChecker, error) {
+	var checker taskChecker
+	if err := r.db.GetContext(
+		ctx, &checker, checkersRepoSetQuery,
+		opts.TaskId, opts.Kind, opts.Command, opts.TestsFileId, opts.TimeoutMs, opts.MemoryMb,
+	); err != nil {
+		return models.TaskChecker{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return checker.toServiceModel(), nil
+}
+
+const checkersRepoDeleteQuery = `
+delete from public.task_checker where task_id = $1
+`
+
+func (r *CheckersRepo) Delete(
+	ctx context.Context,
+	taskId int64,
+) error {
+	res, err := r.db.ExecContext(ctx, checkersRepoDeleteQuery, taskId)
+	if err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	} else if affected == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+const (
+	checkersRepoGetLatestCheckQuery = `
+select ac.id, ac.answer_id, a.task_id, ac.status, ac.proposed_mark, ac.error,
+    ac.created_at, ac.started_at, ac.finished_at
+from public.answer_check ac
+join public.answer a on a.id = ac.answer_id
+where ac.answer_id = $1
+order by ac.id desc
+limit 1
+`
+	checkersRepoGetResultsQuery = `
+select acr.name, acr.status, acr.duration_ms, acr.output
+from public.answer_check_result acr
+where acr.check_id = $1
+order by acr.position
+`
+)
+
+func (r *CheckersRepo) GetLatestCheck(
+	ctx context.Context,
+	answerId int64,
+) (models.AnswerCheck, error) {
+	var check answerCheck
+	if err := r.db.GetContext(ctx, &check, checkersRepoGetLatestCheckQuery, answerId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.AnswerCheck{}, repo.ErrNotFound
+		}
+		return models.AnswerCheck{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+
+	var results []checkResult
+	if err := r.db.SelectContext(ctx, &results, checkersRepoGetResultsQuery, check.Id); err != nil {
+		return models.AnswerCheck{}, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	result := check.toServiceModel()
+	result.Results = lo.Map(results, func(item checkResult, _ int) models.CheckResult {
+		return item.toServiceModel()
+	})
+	return result, nil
+}
+
+const checkersRepoCreateCheckQuery = `
+with created as (
+    insert into public.answer_check (answer_id)
+    values ($1)
+    returning id, answer_id, status, proposed_mark, error, created_at, started_at, finished_at
+)
+select c.id, c.answer_id, a.task_id, c.status, c.proposed_mark, c.error,
+    c.created_at, c.started_at, c.finished_at
+from created c
+join public.answer a on a.id = c.answer_id
+`
+
+func (r *CheckersRepo) CreateCheck(
+	ctx context.Context,
+	answerId int64,
+) (models.AnswerCheck, error) {
+	var check answerCheck
+	if err := r.db.GetContext(ctx, &check, checkersRepoCreateCheckQuery, answerId); err != nil {
+		return models.AnswerCheck{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return check.toServiceModel(), nil
+}
+
+const checkersRepoClaimChecksQuery = `
+with claimed as (
+    update public.answer_check
+    set status = 'running', started_at = now()
+    where id in (
+        select id
+        from public.answer_check
+        where status = 'pending'
+            or (status = 'running' and started_at < now() - $2 * interval '1 second')
+        order by id
+        limit $1
+        for update skip locked
+    )
+    returning id, answer_id, status, proposed_mark, error, created_at, started_at, finished_at
+)
+select c.id, c.answer_id, a.task_id, c.status, c.proposed_mark, c.error,
+    c.created_at, c.started_at, c.finished_at
+from claimed c
+join public.answer a on a.id = c.answer_id
+order by c.id
+`
+
+// ClaimChecks marks up to Limit pending checks as running and returns them.
+// Rows locked by another worker are skipped, so concurrent workers never run
+// the same check twice.
+func (r *CheckersRepo) ClaimChecks(
+	ctx context.Context,
+	opts repo.CheckersRepoClaimChecksOpts,
+) ([]models.AnswerCheck, error) {
+	var checks []answerCheck
+	if err := r.db.SelectContext(
+		ctx, &checks, checkersRepoClaimChecksQuery,
+		opts.Limit, int64(opts.StaleAfter.Seconds()),
+	); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return lo.Map(checks, func(item answerCheck, _ int) models.AnswerCheck {
+		return item.toServiceModel()
+	}), nil
+}
+
+const (
+	checkersRepoFinishCheckQuery = `
+update public.answer_check
+set status = $2, proposed_mark = $3, error = $4, finished_at = now()
+where id = $1
+`
+	checkersRepoDeleteResultsQuery = `
+delete from public.answer_check_result where check_id = $1
+`
+	checkersRepoCreateResultQuery = `
+insert into public.answer_check_result (check_id, position, name, status, duration_ms, output)
+values ($1, $2, $3, $4, $5, $6)
+`
+)
+
+func (r *CheckersRepo) FinishCheck(
+	ctx context.Context,
+	opts repo.CheckersRepoFinishCheckOpts,
+) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(
+		ctx, checkersRepoFinishCheckQuery,
+		opts.Id, opts.Status, opts.ProposedMark, opts.Error,
+	); err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+
+	// A check reclaimed after going stale can be finished twice; keep the last run.
+	if _, err = tx.ExecContext(ctx, checkersRepoDeleteResultsQuery, opts.Id); err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	for i, result := range opts.Results {
+		if _, err = tx.ExecContext(
+			ctx, checkersRepoCreateResultQuery,
+			opts.Id, i, result.Name, result.Status, result.DurationMs, result.Output,
+		); err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,388 @@
+package pg
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+type comment struct {
+	Id         int64      `db:"id"`
+	TaskId     int64      `db:"task_id"`
+	AnswerId   *int64     `db:"answer_id"`
+	AuthorId   int64      `db:"author_id"`
+	AuthorName string     `db:"author_name"`
+	Text       string     `db:"text"`
+	CreatedAt  time.Time  `db:"created_at"`
+	UpdatedAt  *time.Time `db:"updated_at"`
+}
+
+func (c comment) toServiceModel() models.Comment {
+	return models.Comment{
+		Id:         c.Id,
+		TaskId:     c.TaskId,
+		AnswerId:   c.AnswerId,
+		AuthorId:   c.AuthorId,
+		AuthorName: c.AuthorName,
+		Text:       c.Text,
+		CreatedAt:  c.CreatedAt,
+		UpdatedAt:  c.UpdatedAt,
+	}
+}
+
+type commentUnread struct {
+	TaskId   int64  `db:"task_id"`
+	AnswerId *int64 `db:"answer_id"`
+	Unread   int64  `db:"unread"`
+}
+
+func (u commentUnread) toServiceModel() models.CommentUnread {
+	return models.CommentUnread{
+		TaskId:   u.TaskId,
+		AnswerId: u.AnswerId,
+		Unread:   u.Unread,
+	}
+}
+
+type CommentsRepo struct {
+	db *sqlx.DB
+}
+
+func NewCommentsRepo(db *sqlx.DB) *CommentsRepo {
+	return &CommentsRepo{db: db}
+}
+
+const (
+	commentsRepoGetByIdQuery = `
+select
+    c.id,
+    c.task_id,
+    c.answer_id,
+    c.author_id,
+    u.last_name || ' ' || u.first_name author_name,
+    c.text,
+    c.created_at,
+    c.updated_at
+from public.comment c
+join public.user u on u.id = c.author_id
+where c.id = $1
+`
+	commentsRepoGetFilesQuery = `
+select
+    f.id,
+    f.name,
+    f.filename,
+    f.filepath,
+    f.created_at,
+    f.updated_at,
+    f.task_id,
+    f.answer_id,
+    f.comment_id
+from public.file f
+where f.comment_id = any ($1::bigint[])
+  and f.deleted_at is null
+order by f.id
+`
+)
+
+func (r *CommentsRepo) GetById(
+	ctx context.Context,
+	id int64,
+) (models.Comment, error) {
+	var c comment
+	if err := r.db.GetContext(ctx, &c, commentsRepoGetByIdQuery, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Comment{}, repo.ErrNotFound
+		}
+		return models.Comment{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	comments, err := r.withFiles(ctx, []comment{c})
+	if err != nil {
+		return models.Comment{}, err
+	}
+	return comments[0], nil
+}
+
+func (r *CommentsRepo) withFiles(
+	ctx context.Context,
+	comments []comment,
+) ([]models.Comment, error) {
+	var files []file
+	if err := r.db.SelectContext(
+		ctx, &files, commentsRepoGetFilesQuery,
+		lo.Map(comments, func(item comment, _ int) int64 { return item.Id }),
+	); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	byComment := lo.GroupBy(files, func(item file) int64 { return lo.FromPtr(item.CommentId) })
+	return lo.Map(
+		comments,
+		func(item comment, _ int) models.Comment {
+			result := item.toServiceModel()
+			result.Files = lo.Map(
+				byComment[item.Id],
+				func(f file, _ int) models.File {
+					return f.toServiceModel()
+				},
+			)
+			return result
+		},
+	), nil
+}
+
+const commentsRepoGetListQuery = `
+select
+    c.id,
+    c.task_id,
+    c.answer_id,
+    c.author_id,
+    u.last_name || ' ' || u.first_name author_name,
+    c.text,
+    c.created_at,
+    c.updated_at
+from public.comment c
+join public.user u on u.id = c.author_id
+where c.task_id = $1
+  and c.answer_id is not distinct from $2
+order by c.id
+limit $3
+offset $4
+`
+
+// GetList returns one thread in the order it was written: the thread of the
+// task when AnswerId is nil and the thread of the answer otherwise.
+func (r *CommentsRepo) GetList(
+	ctx context.Context,
+	opts repo.CommentsRepoGetListOpts,
+) ([]models.Comment, error) {
+	var comments []comment
+	if err := r.db.SelectContext(
+		ctx, &comments, commentsRepoGetListQuery,
+		opts.TaskId, opts.AnswerId, opts.Limit, opts.Offset,
+	); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return r.withFiles(ctx, comments)
+}
+
+const commentsRepoGetCountQuery = `
+select count(*)
+from public.comment c
+where c.task_id = $1
+  and c.answer_id is not distinct from $2
+`
+
+func (r *CommentsRepo) GetCount(
+	ctx context.Context,
+	opts repo.CommentsRepoGetListOpts,
+) (int64, error) {
+	var count int64
+	if err := r.db.GetContext(ctx, &count, commentsRepoGetCountQuery, opts.TaskId, opts.AnswerId); err != nil {
+		return 0, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return count, nil
+}
+
+const (
+	commentsRepoCreateQuery = `
+insert into public.comment (task_id, answer_id, author_id, text)
+values ($1, $2, $3, $4)
+returning id
+`
+	commentsRepoUpdateQuery = `
+update public.comment
+set text = $2, updated_at = now()
+where id = $1
+`
+	commentsRepoDeleteStaleFilesQuery = `
+update public.file
+set deleted_at = now()
+where comment_id = $1
+  and deleted_at is null
+  and id <> all ($2::bigint[])
+`
+	// commentsRepoAttachFilesQuery attaches only loose files uploaded by the
+	// author of the comment and keeps the ones already attached to it.
+	commentsRepoAttachFilesQuery = `
+update public.file f
+set comment_id = c.id
+from public.comment c
+where c.id = $1
+  and f.id = any ($2::bigint[])
+  and f.deleted_at is null
+  and (f.comment_id = c.id
+   or f.created_by = c.author_id
+  and f.task_id is null
+  and f.answer_id is null
+  and f.comment_id is null)
+`
+)
+
+func (r *CommentsRepo) Create(
+	ctx context.Context,
+	opts repo.CommentsRepoCreateOpts,
+) (models.Comment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	if err = tx.GetContext(ctx, &id, commentsRepoCreateQuery, opts.TaskId, opts.AnswerId, opts.AuthorId, opts.Text); err != nil {
+		return models.Comment{}, fmt.Errorf("tx.GetContext: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, commentsRepoAttachFilesQuery, id, lo.Uniq(opts.FileIds)); err != nil {
+		return models.Comment{}, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Comment{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+	return r.GetById(ctx, id)
+}
+
+// Update replaces the text and the attachments of the comment. Attachments
+// left out are moved to the trash.
+func (r *CommentsRepo) Update(
+	ctx context.Context,
+	opts repo.CommentsRepoUpdateOpts,
+) (models.Comment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, commentsRepoUpdateQuery, opts.Id, opts.Text)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return models.Comment{}, fmt.Errorf("res.RowsAffected: %w", err)
+	} else if affected == 0 {
+		return models.Comment{}, repo.ErrNotFound
+	}
+
+	fileIds := lo.Uniq(opts.FileIds)
+	for _, query := range []string{commentsRepoDeleteStaleFilesQuery, commentsRepoAttachFilesQuery} {
+		if _, err = tx.ExecContext(ctx, query, opts.Id, fileIds); err != nil {
+			return models.Comment{}, fmt.Errorf("tx.ExecContext: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Comment{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+	return r.GetById(ctx, opts.Id)
+}
+
+const (
+	commentsRepoTrashFilesQuery = `
+update public.file
+set deleted_at = now()
+where comment_id = $1
+  and deleted_at is null
+`
+	commentsRepoDeleteQuery = `
+delete from public.comment where id = $1
+`
+)
+
+// Delete removes the comment and moves its attachments to the trash.
+func (r *CommentsRepo) Delete(
+	ctx context.Context,
+	id int64,
+) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, commentsRepoTrashFilesQuery, id); err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, commentsRepoDeleteQuery, id)
+	if err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return repo.ErrNotFound
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+	return nil
+}
+
+const commentsRepoMarkReadQuery = `
+insert into public.comment_read (user_id, task_id, answer_id, last_read_id)
+select $1, $2, $3, coalesce(max(c.id), 0)
+from public.comment c
+where c.task_id = $2
+  and c.answer_id is not distinct from $3
+on conflict (user_id, task_id, coalesce(answer_id, 0)) do update
+set last_read_id = greatest(comment_read.last_read_id, excluded.last_read_id),
+    read_at = now()
+`
+
+// MarkRead marks every comment currently in the thread as read by the user.
+func (r *CommentsRepo) MarkRead(
+	ctx context.Context,
+	opts repo.CommentsRepoThreadOpts,
+) error {
+	if _, err := r.db.ExecContext(ctx, commentsRepoMarkReadQuery, opts.UserId, opts.TaskId, opts.AnswerId); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// commentsRepoGetUnreadQuery counts, per thread of the task $2, the comments
+// of other users written after the last one the user $1 has read. Answer
+// threads are limited to the user's own and team answers unless $3 is set.
+const commentsRepoGetUnreadQuery = `
+select c.task_id, c.answer_id, count(*) unread
+from public.comment c
+left join public.answer a on a.id = c.answer_id
+left join public.comment_read cr on cr.user_id = $1
+    and cr.task_id = c.task_id
+    and coalesce(cr.answer_id, 0) = coalesce(c.answer_id, 0)
+where c.task_id = $2
+  and c.author_id <> $1
+  and c.id > coalesce(cr.last_read_id, 0)
+  and (c.answer_id is null
+   or a.deleted_at is null
+  and ($3 or a.user_id = $1 or a.team_id = public.team_of(a.task_id, $1)))
+group by c.task_id, c.answer_id
+order by c.answer_id nulls first
+`
+
+func (r *CommentsRepo) GetUnread(
+	ctx context.Context,
+	opts repo.CommentsRepoGetUnreadOpts,
+) ([]models.CommentUnread, error) {
+	var unread []commentUnread
+	if err := r.db.SelectContext(ctx, &unread, commentsRepoGetUnreadQuery, opts.UserId, opts.TaskId, opts.AllAnswers); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return lo.Map(
+		unread,
+		func(item commentUnread, _ int) models.CommentUnread {
+			return item.toServiceModel()
+		},
+	), nil
+}
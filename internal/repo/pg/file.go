@@ -22,6 +22,8 @@ type file struct {
 	UpdatedAt *time.Time `db:"updated_at"`
 	TaskId    *int64     `db:"task_id"`
 	AnswerId  *int64     `db:"answer_id"`
+	CommentId *int64     `db:"comment_id"`
+	CreatedBy *int64     `db:"created_by"`
 }
 
 func (f file) toServiceModel() models.File {
@@ -34,6 +36,8 @@ func (f file) toServiceModel() models.File {
 		UpdatedAt: f.UpdatedAt,
 		TaskId:    f.TaskId,
 		AnswerId:  f.AnswerId,
+		CommentId: f.CommentId,
+		CreatedBy: f.CreatedBy,
 	}
 }
 
@@ -54,9 +58,21 @@ select
     f.created_at, 
     f.updated_at, 
     f.task_id, 
-    f.answer_id
+    f.answer_id,
+    f.comment_id,
+    f.created_by
 from public.file f
+left join public.task t on t.id = f.task_id
+left join public.answer a on a.id = f.answer_id
+left join public.task ta on ta.id = a.task_id
+left join public.comment c on c.id = f.comment_id
+left join public.task tc on tc.id = c.task_id
 where f.id = $1
+  and f.deleted_at is null
+  and t.deleted_at is null
+  and a.deleted_at is null
+  and ta.deleted_at is null
+  and tc.deleted_at is null
 `
 
 func (r *FilesRepo) GetById(
@@ -65,6 +81,9 @@ func (r *FilesRepo) GetById(
 ) (models.File, error) {
 	var f file
 	if err := r.db.GetContext(ctx, &f, filesRepoGetByIdQuery, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.File{}, repo.ErrNotFound
+		}
 		return models.File{}, fmt.Errorf("r.db.GetContext: %w", err)
 	}
 	return f.toServiceModel(), nil
@@ -79,9 +98,11 @@ select
     f.created_at, 
     f.updated_at, 
     f.task_id, 
-    f.answer_id
+    f.answer_id,
+    f.comment_id
 from public.file f
 where f.answer_id = $1
+  and f.deleted_at is null
 `
 
 func (r *FilesRepo) GetByAnswerId(
@@ -112,9 +133,11 @@ select
     f.created_at, 
     f.updated_at, 
     f.task_id, 
-    f.answer_id
+    f.answer_id,
+    f.comment_id
 from public.file f
 where f.task_id = $1
+  and f.deleted_at is null
 `
 
 func (r *FilesRepo) GetByTaskId(
@@ -137,8 +160,8 @@ func (r *FilesRepo) GetByTaskId(
 }
 
 const filesRepoCreateQuery = `
-insert into public.file (name, filename, filepath, task_id, answer_id) 
-values (:name, :filename, :filepath, :task_id, :answer_id)
+insert into public.file (name, filename, filepath, task_id, answer_id, created_by) 
+values (:name, :filename, :filepath, :task_id, :answer_id, :created_by)
 returning id
 `
 
@@ -147,17 +170,19 @@ func (r *FilesRepo) Create(
 	opts repo.FilesRepoCreateOpts,
 ) (models.File, error) {
 	rows, err := r.db.NamedQueryContext(ctx, filesRepoCreateQuery, struct {
-		Name     string `db:"name"`
-		Filename string `db:"filename"`
-		Filepath string `db:"filepath"`
-		TaskId   *int64 `db:"task_id"`
-		AnswerId *int64 `db:"answer_id"`
+		Name      string `db:"name"`
+		Filename  string `db:"filename"`
+		Filepath  string `db:"filepath"`
+		TaskId    *int64 `db:"task_id"`
+		AnswerId  *int64 `db:"answer_id"`
+		CreatedBy *int64 `db:"created_by"`
 	}{
-		Name:     opts.Name,
-		Filename: opts.Filename,
-		Filepath: opts.Filepath,
-		TaskId:   opts.TaskId,
-		AnswerId: opts.AnswerId,
+		Name:      opts.Name,
+		Filename:  opts.Filename,
+		Filepath:  opts.Filepath,
+		TaskId:    opts.TaskId,
+		AnswerId:  opts.AnswerId,
+		CreatedBy: opts.CreatedBy,
 	})
 	if err != nil {
 		return models.File{}, fmt.Errorf("r.db.NamedExecContext: %w", err)
@@ -205,8 +230,19 @@ func (r *FilesRepo) UpdateAnswerId(
 	return nil
 }
 
+// filesRepoUpdateTaskAnswerIdQuery attaches only loose files uploaded by the
+// creator of the task.
 const filesRepoUpdateTaskAnswerIdQuery = `
-update public.file set task_id = $1 where id = $2
+update public.file f
+set task_id = t.id
+from public.task t
+where t.id = $1
+  and f.id = $2
+  and f.created_by = t.created_by
+  and f.task_id is null
+  and f.answer_id is null
+  and f.comment_id is null
+  and f.deleted_at is null
 `
 
 func (r *FilesRepo) UpdateTaskId(
@@ -221,7 +257,7 @@ func (r *FilesRepo) UpdateTaskId(
 }
 
 const filesRepoDeleteQuery = `
-delete from public.file where id = $1
+update public.file set deleted_at = now() where id = $1 and deleted_at is null
 `
 
 func (r *FilesRepo) Delete(
@@ -0,0 +1,156 @@
+package pg
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// equalityOps are allowed for a field that does not list its own ops.
+	equalityOps = []string{models.FilterOpEq, models.FilterOpIn}
+	orderedOps  = []string{
+		models.FilterOpEq,
+		models.FilterOpIn,
+		models.FilterOpLt,
+		models.FilterOpLte,
+		models.FilterOpGt,
+		models.FilterOpGte,
+	}
+	textOps = []string{models.FilterOpEq, models.FilterOpIn, models.FilterOpLike}
+)
+
+// maxFilterInValues caps the values of one "in" condition so that a filter
+// cannot grow the query and its arguments without bound.
+const maxFilterInValues = 100
+
+var filterOpOperators = map[string]string{
+	models.FilterOpEq:  "=",
+	models.FilterOpLt:  "<",
+	models.FilterOpLte: "<=",
+	models.FilterOpGt:  ">",
+	models.FilterOpGte: ">=",
+}
+
+type filterField struct {
+	column string
+	ops    []string
+	parse  func(value string) (any, error)
+}
+
+type filterSpec struct {
+	fields       map[string]filterField
+	sortFields   map[string]string
+	searchColumn string
+	defaultOrder string
+}
+
+func parseInt64(value string) (any, error) {
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func parseBool(value string) (any, error) {
+	return strconv.ParseBool(value)
+}
+
+func parseString(value string) (any, error) {
+	return value, nil
+}
+
+func parseTime(value string) (any, error) {
+	return time.Parse(time.RFC3339, value)
+}
+
+func parseOneOf(values ...string) func(value string) (any, error) {
+	return func(value string) (any, error) {
+		if !slices.Contains(values, value) {
+			return nil, fmt.Errorf("must be one of %s", strings.Join(values, ", "))
+		}
+		return value, nil
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike makes value match itself literally inside an ilike pattern.
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+// build translates filter into sql conditions and order by clause using only
+// whitelisted columns. Values are never inlined: they are appended to args and
+// referenced by positional placeholders.
+func (s filterSpec) build(filter models.Filter, args []any) (string, string, []any, error) {
+	var where strings.Builder
+
+	if filter.Search != "" && s.searchColumn != "" {
+		args = append(args, escapeLike(filter.Search))
+		fmt.Fprintf(&where, " and %s ilike '%%' || $%d || '%%'", s.searchColumn, len(args))
+	}
+
+	for _, item := range filter.Items {
+		field, ok := s.fields[item.Field]
+		if !ok {
+			return "", "", nil, fmt.Errorf("%w: unknown field %q", repo.ErrInvalidFilter, item.Field)
+		}
+
+		op := item.Op
+		if op == "" {
+			op = models.FilterOpEq
+		}
+		ops := field.ops
+		if ops == nil {
+			ops = equalityOps
+		}
+		if !slices.Contains(ops, op) {
+			return "", "", nil, fmt.Errorf("%w: field %q does not support %q", repo.ErrInvalidFilter, item.Field, op)
+		}
+
+		switch op {
+		case models.FilterOpIn:
+			values := strings.Split(item.Value, ",")
+			if len(values) > maxFilterInValues {
+				return "", "", nil, fmt.Errorf("%w: field %q: more than %d values", repo.ErrInvalidFilter, item.Field, maxFilterInValues)
+			}
+			placeholders := make([]string, 0, len(values))
+			for _, v := range values {
+				value, err := field.parse(strings.TrimSpace(v))
+				if err != nil {
+					return "", "", nil, fmt.Errorf("%w: field %q: %v", repo.ErrInvalidFilter, item.Field, err)
+				}
+				args = append(args, value)
+				placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+			}
+			fmt.Fprintf(&where, " and %s in (%s)", field.column, strings.Join(placeholders, ", "))
+		case models.FilterOpLike:
+			args = append(args, escapeLike(item.Value))
+			fmt.Fprintf(&where, " and %s ilike '%%' || $%d || '%%'", field.column, len(args))
+		default:
+			value, err := field.parse(item.Value)
+			if err != nil {
+				return "", "", nil, fmt.Errorf("%w: field %q: %v", repo.ErrInvalidFilter, item.Field, err)
+			}
+			args = append(args, value)
+			fmt.Fprintf(&where, " and %s %s $%d", field.column, filterOpOperators[op], len(args))
+		}
+	}
+
+	orderBy := make([]string, 0, len(filter.Sort)+1)
+	for _, item := range filter.Sort {
+		column, ok := s.sortFields[item.Field]
+		if !ok {
+			return "", "", nil, fmt.Errorf("%w: unknown sort field %q", repo.ErrInvalidFilter, item.Field)
+		}
+		if item.Desc {
+			column += " desc"
+		}
+		orderBy = append(orderBy, column)
+	}
+	orderBy = append(orderBy, s.defaultOrder)
+
+	return where.String(), strings.Join(orderBy, ", "), args, nil
+}
@@ -0,0 +1,181 @@
+package pg
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFilterSpecBuild(t *testing.T) {
+	spec := filterSpec{
+		fields: map[string]filterField{
+			"id":    {column: "t.id", parse: parseInt64},
+			"score": {column: "t.score", ops: orderedOps, parse: parseInt64},
+			"title": {column: "t.title", ops: textOps, parse: parseString},
+		},
+		sortFields: map[string]string{
+			"title": "t.title",
+			"score": "t.score",
+		},
+		searchColumn: "t.title",
+		defaultOrder: "t.id",
+	}
+
+	ids := make([]string, maxFilterInValues+1)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i + 1)
+	}
+
+	tests := []struct {
+		name      string
+		filter    models.Filter
+		args      []any
+		wantWhere string
+		wantOrder string
+		wantArgs  []any
+		wantErr   bool
+	}{
+		{
+			name:      "empty",
+			wantWhere: "",
+			wantOrder: "t.id",
+		},
+		{
+			name:      "default op is eq",
+			filter:    models.Filter{Items: []models.FilterItem{{Field: "id", Value: "7"}}},
+			wantWhere: " and t.id = $1",
+			wantOrder: "t.id",
+			wantArgs:  []any{int64(7)},
+		},
+		{
+			name: "placeholders continue existing args",
+			filter: models.Filter{
+				Search: "go",
+				Items: []models.FilterItem{
+					{Field: "id", Op: models.FilterOpIn, Value: "1, 2"},
+					{Field: "score", Op: models.FilterOpGte, Value: "50"},
+				},
+			},
+			args:      []any{int64(42), true},
+			wantWhere: " and t.title ilike '%' || $3 || '%' and t.id in ($4, $5) and t.score >= $6",
+			wantOrder: "t.id",
+			wantArgs:  []any{int64(42), true, "go", int64(1), int64(2), int64(50)},
+		},
+		{
+			name:      "like escapes wildcards",
+			filter:    models.Filter{Items: []models.FilterItem{{Field: "title", Op: models.FilterOpLike, Value: `50%_a\b`}}},
+			wantWhere: " and t.title ilike '%' || $1 || '%'",
+			wantOrder: "t.id",
+			wantArgs:  []any{`50\%\_a\\b`},
+		},
+		{
+			name:      "search escapes wildcards",
+			filter:    models.Filter{Search: "%_"},
+			wantWhere: " and t.title ilike '%' || $1 || '%'",
+			wantOrder: "t.id",
+			wantArgs:  []any{`\%\_`},
+		},
+		{
+			name:      "in at the cap",
+			filter:    models.Filter{Items: []models.FilterItem{{Field: "id", Op: models.FilterOpIn, Value: strings.Join(ids[:maxFilterInValues], ",")}}},
+			wantWhere: " and t.id in (" + placeholders(1, maxFilterInValues) + ")",
+			wantOrder: "t.id",
+			wantArgs:  int64Args(maxFilterInValues),
+		},
+		{
+			name:    "in over the cap",
+			filter:  models.Filter{Items: []models.FilterItem{{Field: "id", Op: models.FilterOpIn, Value: strings.Join(ids, ",")}}},
+			wantErr: true,
+		},
+		{
+			name: "multi field sort",
+			filter: models.Filter{Sort: []models.SortItem{
+				{Field: "score", Desc: true},
+				{Field: "title"},
+			}},
+			wantWhere: "",
+			wantOrder: "t.score desc, t.title, t.id",
+		},
+		{
+			name:    "unknown sort field",
+			filter:  models.Filter{Sort: []models.SortItem{{Field: "title"}, {Field: "t.id; drop table task"}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			filter:  models.Filter{Items: []models.FilterItem{{Field: "password", Value: "x"}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown op",
+			filter:  models.Filter{Items: []models.FilterItem{{Field: "title", Op: "regex", Value: "x"}}},
+			wantErr: true,
+		},
+		{
+			name:    "op not allowed for field",
+			filter:  models.Filter{Items: []models.FilterItem{{Field: "id", Op: models.FilterOpGt, Value: "1"}}},
+			wantErr: true,
+		},
+		{
+			name:    "like not allowed for field",
+			filter:  models.Filter{Items: []models.FilterItem{{Field: "score", Op: models.FilterOpLike, Value: "1"}}},
+			wantErr: true,
+		},
+		{
+			name:    "bad value",
+			filter:  models.Filter{Items: []models.FilterItem{{Field: "id", Value: "one"}}},
+			wantErr: true,
+		},
+		{
+			name:    "bad value in list",
+			filter:  models.Filter{Items: []models.FilterItem{{Field: "id", Op: models.FilterOpIn, Value: "1,x"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, order, args, err := spec.build(tt.filter, tt.args)
+			if tt.wantErr {
+				if !errors.Is(err, repo.ErrInvalidFilter) {
+					t.Fatalf("build() error = %v, want ErrInvalidFilter", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("build() error = %v", err)
+			}
+			if where != tt.wantWhere {
+				t.Errorf("build() where = %q, want %q", where, tt.wantWhere)
+			}
+			if order != tt.wantOrder {
+				t.Errorf("build() order = %q, want %q", order, tt.wantOrder)
+			}
+			if len(args) != 0 || len(tt.wantArgs) != 0 {
+				if !reflect.DeepEqual(args, tt.wantArgs) {
+					t.Errorf("build() args = %v, want %v", args, tt.wantArgs)
+				}
+			}
+		})
+	}
+}
+
+func placeholders(from, n int) string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = "$" + strconv.Itoa(from+i)
+	}
+	return strings.Join(items, ", ")
+}
+
+func int64Args(n int) []any {
+	args := make([]any, n)
+	for i := range args {
+		args[i] = int64(i + 1)
+	}
+	return args
+}
@@ -14,6 +14,7 @@ import (
 type group struct {
 	Id        int64      `db:"id"`
 	Name      string     `db:"name"`
+	OrgUnitId *int64     `db:"org_unit_id"`
 	CreatedAt time.Time  `db:"created_at"`
 	UpdatedAt *time.Time `db:"updated_at"`
 }
@@ -22,6 +23,7 @@ func (g group) toServiceModel() models.Group {
 	return models.Group{
 		Id:        g.Id,
 		Name:      g.Name,
+		OrgUnitId: g.OrgUnitId,
 		CreatedAt: g.CreatedAt,
 		UpdatedAt: g.UpdatedAt,
 	}
@@ -39,10 +41,12 @@ const groupsRepoGetByIdQuery = `
 select 
    g.id, 
    g.name, 
+   g.org_unit_id, 
    g.created_at, 
    g.updated_at
 from public."group" g
 where g.id = $1
+  and g.deleted_at is null
 `
 
 func (r *GroupsRepo) GetById(
@@ -56,24 +60,48 @@ func (r *GroupsRepo) GetById(
 	return g.toServiceModel(), nil
 }
 
+var groupsRepoFilterSpec = filterSpec{
+	fields: map[string]filterField{
+		"name":      {column: "g.name", ops: textOps, parse: parseString},
+		"orgUnitId": {column: "g.org_unit_id", parse: parseInt64},
+	},
+	sortFields: map[string]string{
+		"id":        "g.id",
+		"name":      "g.name",
+		"createdAt": "g.created_at",
+	},
+	searchColumn: "g.name",
+	defaultOrder: "g.id desc",
+}
+
 const groupsRepoGetListQuery = `
 select 
    g.id, 
    g.name, 
+   g.org_unit_id, 
    g.created_at, 
    g.updated_at
 from public."group" g
-order by id desc 
-limit $1
-offset $2
+where g.deleted_at is null
+  and ($1::bigint is null or g.id in (select public.visible_groups($1))) %s
+order by %s
+limit $%d
+offset $%d
 `
 
 func (r *GroupsRepo) GetList(
 	ctx context.Context,
 	opts repo.GroupsRepoGetListOpts,
 ) ([]models.Group, error) {
+	where, orderBy, args, err := groupsRepoFilterSpec.build(opts.Filter, []any{opts.ScopeUserId})
+	if err != nil {
+		return nil, fmt.Errorf("groupsRepoFilterSpec.build: %w", err)
+	}
+	args = append(args, opts.Limit, opts.Offset)
+	query := fmt.Sprintf(groupsRepoGetListQuery, where, orderBy, len(args)-1, len(args))
+
 	var groups []group
-	if err := r.db.SelectContext(ctx, &groups, groupsRepoGetListQuery, opts.Limit, opts.Offset); err != nil {
+	if err = r.db.SelectContext(ctx, &groups, query, args...); err != nil {
 		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
 	}
 	return lo.Map(
@@ -86,22 +114,30 @@ func (r *GroupsRepo) GetList(
 
 const groupsRepoGetCountQuery = `
 select count(*)
-from public."group"
+from public."group" g
+where g.deleted_at is null
+  and ($1::bigint is null or g.id in (select public.visible_groups($1))) %s
 `
 
 func (r *GroupsRepo) GetCount(
 	ctx context.Context,
+	opts repo.GroupsRepoGetListOpts,
 ) (int64, error) {
+	where, _, args, err := groupsRepoFilterSpec.build(opts.Filter, []any{opts.ScopeUserId})
+	if err != nil {
+		return 0, fmt.Errorf("groupsRepoFilterSpec.build: %w", err)
+	}
+
 	var count int64
-	if err := r.db.GetContext(ctx, &count, groupsRepoGetCountQuery); err != nil {
-		return 0, fmt.Errorf("r.db.GetContext: :%w", err)
+	if err = r.db.GetContext(ctx, &count, fmt.Sprintf(groupsRepoGetCountQuery, where), args...); err != nil {
+		return 0, fmt.Errorf("r.db.GetContext: %w", err)
 	}
 	return count, nil
 }
 
 const groupsRepoCreateQuery = `
-insert into public."group" (name)
-values (:name)
+insert into public."group" (name, org_unit_id)
+values (:name, :org_unit_id)
 `
 
 func (r *GroupsRepo) Create(
@@ -109,9 +145,11 @@ func (r *GroupsRepo) Create(
 	opts repo.GroupsRepoCreateOpts,
 ) (models.Group, error) {
 	_, err := r.db.NamedExecContext(ctx, groupsRepoCreateQuery, struct {
-		Name string `db:"name"`
+		Name      string `db:"name"`
+		OrgUnitId *int64 `db:"org_unit_id"`
 	}{
-		Name: opts.Name,
+		Name:      opts.Name,
+		OrgUnitId: opts.OrgUnitId,
 	})
 	if err != nil {
 		return models.Group{}, fmt.Errorf("r.db.NamedExecContext: %w", err)
@@ -123,11 +161,16 @@ func (r *GroupsRepo) Create(
 const groupsRepoUpdateQuery = `
 update public."group"
 set (
-     name
+     name,
+     org_unit_id,
+     updated_at
     ) = (
-     :name
+     :name,
+     :org_unit_id,
+     now()
     )
 where id = :id
+  and deleted_at is null
 `
 
 func (r *GroupsRepo) Update(
@@ -135,11 +178,13 @@ func (r *GroupsRepo) Update(
 	opts repo.GroupsRepoUpdateOpts,
 ) (models.Group, error) {
 	_, err := r.db.NamedExecContext(ctx, groupsRepoUpdateQuery, struct {
-		Id   int64  `db:"id"`
-		Name string `db:"name"`
+		Id        int64  `db:"id"`
+		Name      string `db:"name"`
+		OrgUnitId *int64 `db:"org_unit_id"`
 	}{
-		Id:   opts.Id,
-		Name: opts.Name,
+		Id:        opts.Id,
+		Name:      opts.Name,
+		OrgUnitId: opts.OrgUnitId,
 	})
 	if err != nil {
 		return models.Group{}, fmt.Errorf("r.db.NamedExecContext: %w", err)
@@ -149,7 +194,7 @@ func (r *GroupsRepo) Update(
 }
 
 const groupsRepoDeleteQuery = `
-delete from public."group" where id = $1
+update public."group" set deleted_at = now() where id = $1 and deleted_at is null
 `
 
 func (r *GroupsRepo) Delete(
@@ -161,3 +206,57 @@ func (r *GroupsRepo) Delete(
 	}
 	return nil
 }
+
+const groupsRepoCreateAssignmentQuery = `
+insert into public.group_assignment (user_id, group_id)
+values ($1, $2)
+on conflict do nothing
+`
+
+func (r *GroupsRepo) CreateAssignment(
+	ctx context.Context,
+	opts repo.GroupsRepoAssignmentOpts,
+) error {
+	if _, err := r.db.ExecContext(ctx, groupsRepoCreateAssignmentQuery, opts.UserId, opts.GroupId); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+const groupsRepoDeleteAssignmentQuery = `
+delete from public.group_assignment where user_id = $1 and group_id = $2
+`
+
+func (r *GroupsRepo) DeleteAssignment(
+	ctx context.Context,
+	opts repo.GroupsRepoAssignmentOpts,
+) error {
+	if _, err := r.db.ExecContext(ctx, groupsRepoDeleteAssignmentQuery, opts.UserId, opts.GroupId); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+const groupsRepoIsAccessibleQuery = `
+select not exists (
+    select 1
+    from unnest($2::bigint[]) gid
+    where gid not in (select public.visible_groups($1))
+) and not exists (
+    select 1
+    from public.user u
+    where u.id = any ($3::bigint[])
+      and (u.group_id is null or u.group_id not in (select public.visible_groups($1)))
+)
+`
+
+func (r *GroupsRepo) IsAccessible(
+	ctx context.Context,
+	opts repo.GroupsRepoIsAccessibleOpts,
+) (bool, error) {
+	var ok bool
+	if err := r.db.GetContext(ctx, &ok, groupsRepoIsAccessibleQuery, opts.ScopeUserId, opts.GroupIds, opts.UserIds); err != nil {
+		return false, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return ok, nil
+}
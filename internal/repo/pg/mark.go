@@ -16,6 +16,7 @@ type mark struct {
 	Id        int64      `db:"id"`
 	AnswerId  int64      `db:"answer_id"`
 	TaskId    int64      `db:"task_id"`
+	RawMark   int64      `db:"raw_mark"`
 	Mark      int64      `db:"mark"`
 	Comment   *string    `db:"comment"`
 	CreatedAt time.Time  `db:"created_at"`
@@ -27,6 +28,7 @@ func (m mark) toServiceModel() models.Mark {
 		Id:        m.Id,
 		AnswerId:  m.AnswerId,
 		TaskId:    m.TaskId,
+		RawMark:   m.RawMark,
 		Mark:      m.Mark,
 		Comment:   m.Comment,
 		CreatedAt: m.CreatedAt,
@@ -34,6 +36,26 @@ func (m mark) toServiceModel() models.Mark {
 	}
 }
 
+type markCriterion struct {
+	CriterionId   int64   `db:"criterion_id"`
+	CriterionName string  `db:"criterion_name"`
+	LevelId       int64   `db:"level_id"`
+	LevelName     string  `db:"level_name"`
+	Points        int64   `db:"points"`
+	Comment       *string `db:"comment"`
+}
+
+func (c markCriterion) toServiceModel() models.MarkCriterion {
+	return models.MarkCriterion{
+		CriterionId:   c.CriterionId,
+		CriterionName: c.CriterionName,
+		LevelId:       c.LevelId,
+		LevelName:     c.LevelName,
+		Points:        c.Points,
+		Comment:       c.Comment,
+	}
+}
+
 type MarksRepo struct {
 	db *sqlx.DB
 }
@@ -43,7 +65,7 @@ func NewMarksRepo(db *sqlx.DB) *MarksRepo {
 }
 
 const marksRepoGetByIdQuery = `
-select m.id, m.answer_id, m.mark, m.comment, m.created_at, m.updated_at, a.task_id
+select m.id, m.answer_id, m.raw_mark, m.mark, m.comment, m.created_at, m.updated_at, a.task_id
 from public.mark m
 left join public.answer a on a.id = m.answer_id
 where m.id = $1
@@ -60,11 +82,11 @@ func (r *MarksRepo) GetById(
 		}
 		return models.Mark{}, fmt.Errorf("r.db.GetContext: %w", err)
 	}
-	return m.toServiceModel(), nil
+	return r.withCriteria(ctx, m)
 }
 
 const marksRepoGetByAnswerIdQuery = `
-select m.id, m.answer_id, m.mark, m.comment, m.created_at, m.updated_at, a.task_id
+select m.id, m.answer_id, m.raw_mark, m.mark, m.comment, m.created_at, m.updated_at, a.task_id
 from public.mark m
 left join public.answer a on a.id = m.answer_id
 where m.answer_id = $1
@@ -81,25 +103,81 @@ func (r *MarksRepo) GetByAnswerId(
 		}
 		return models.Mark{}, fmt.Errorf("r.db.GetContext: %w", err)
 	}
-	return m.toServiceModel(), nil
+	return r.withCriteria(ctx, m)
+}
+
+const marksRepoGetCriteriaQuery = `
+select mc.criterion_id, rc.name criterion_name, mc.level_id, rl.name level_name, mc.points, mc.comment
+from public.mark_criterion mc
+join public.rubric_criterion rc on rc.id = mc.criterion_id
+join public.rubric_level rl on rl.id = mc.level_id
+where mc.mark_id = $1
+order by rc.position, rc.id
+`
+
+func (r *MarksRepo) withCriteria(
+	ctx context.Context,
+	m mark,
+) (models.Mark, error) {
+	var criteria []markCriterion
+	if err := r.db.SelectContext(ctx, &criteria, marksRepoGetCriteriaQuery, m.Id); err != nil {
+		return models.Mark{}, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	result := m.toServiceModel()
+	result.Criteria = lo.Map(
+		criteria,
+		func(item markCriterion, _ int) models.MarkCriterion {
+			return item.toServiceModel()
+		},
+	)
+	return result, nil
+}
+
+var marksRepoFilterSpec = filterSpec{
+	fields: map[string]filterField{
+		"mark":      {column: "m.mark", ops: orderedOps, parse: parseInt64},
+		"rawMark":   {column: "m.raw_mark", ops: orderedOps, parse: parseInt64},
+		"createdAt": {column: "m.created_at", ops: orderedOps, parse: parseTime},
+	},
+	sortFields: map[string]string{
+		"id":        "m.id",
+		"mark":      "m.mark",
+		"rawMark":   "m.raw_mark",
+		"createdAt": "m.created_at",
+		"taskId":    "a.task_id",
+	},
+	searchColumn: "m.comment",
+	defaultOrder: "m.id",
 }
 
 const marksRepoGetListByUserIdQuery = `
-select m.id, m.answer_id, m.mark, m.comment, m.created_at, m.updated_at, a.task_id
+select m.id, m.answer_id, m.raw_mark, public.member_mark(m.mark, a.team_id, $1) as mark, m.comment, m.created_at, m.updated_at, a.task_id
 from public.mark m
 left join public.answer a on a.id = m.answer_id
-where a.user_id = $1
-order by m.id
-limit $2
-offset $3
+left join public.task t on t.id = a.task_id
+where (a.user_id = $1 or a.team_id = public.team_of(a.task_id, $1))
+  and a.deleted_at is null
+  and t.deleted_at is null
+  and ($2::bigint is null or public.sees_student($2, $1, a.created_at)) %s
+order by %s
+limit $%d
+offset $%d
 `
 
 func (r *MarksRepo) GetListByUserId(
 	ctx context.Context,
 	opts repo.MarksRepoGetListByUserIdOpts,
 ) ([]models.Mark, error) {
+	where, orderBy, args, err := marksRepoFilterSpec.build(opts.Filter, []any{opts.UserId, opts.ScopeUserId})
+	if err != nil {
+		return nil, fmt.Errorf("marksRepoFilterSpec.build: %w", err)
+	}
+	args = append(args, opts.Limit, opts.Offset)
+	query := fmt.Sprintf(marksRepoGetListByUserIdQuery, where, orderBy, len(args)-1, len(args))
+
 	var ms []mark
-	if err := r.db.SelectContext(ctx, &ms, marksRepoGetListByUserIdQuery, opts.UserId, opts.Limit, opts.Offset); err != nil {
+	if err = r.db.SelectContext(ctx, &ms, query, args...); err != nil {
 		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
 	}
 	return lo.Map(
@@ -114,78 +192,124 @@ const marksRepoGetCountByUserIdQuery = `
 select count(*)
 from public.mark m 
 left join public.answer a on a.id = m.answer_id
-where a.user_id = $1
+left join public.task t on t.id = a.task_id
+where (a.user_id = $1 or a.team_id = public.team_of(a.task_id, $1))
+  and a.deleted_at is null
+  and t.deleted_at is null
+  and ($2::bigint is null or public.sees_student($2, $1, a.created_at)) %s
 `
 
 func (r *MarksRepo) GetCountByUserId(
 	ctx context.Context,
-	userId int64,
+	opts repo.MarksRepoGetListByUserIdOpts,
 ) (int64, error) {
+	where, _, args, err := marksRepoFilterSpec.build(opts.Filter, []any{opts.UserId, opts.ScopeUserId})
+	if err != nil {
+		return 0, fmt.Errorf("marksRepoFilterSpec.build: %w", err)
+	}
+
 	var count int64
-	if err := r.db.GetContext(ctx, &count, marksRepoGetCountByUserIdQuery, userId); err != nil {
+	if err = r.db.GetContext(ctx, &count, fmt.Sprintf(marksRepoGetCountByUserIdQuery, where), args...); err != nil {
 		return 0, fmt.Errorf("r.db.GetContext: %w", err)
 	}
 	return count, nil
 }
 
-const marksRepoCreateQuery = `
-insert into public.mark (mark, comment, answer_id)
-values ($1, $2, $3)
-returning id, mark, comment, answer_id, created_at, updated_at
+const (
+	marksRepoCreateQuery = `
+insert into public.mark (mark, comment, answer_id, raw_mark)
+values ($1, $2, $3, $4)
+returning id, raw_mark, mark, comment, answer_id, created_at, updated_at
 `
+	marksRepoUpdateQuery = `
+update public.mark set mark = $1, comment = $2, raw_mark = $4, updated_at = now()
+where id = $3
+returning id, raw_mark, mark, comment, answer_id, created_at, updated_at
+`
+	marksRepoDeleteCriteriaQuery = `
+delete from public.mark_criterion where mark_id = $1
+`
+	marksRepoCreateCriterionQuery = `
+insert into public.mark_criterion (mark_id, criterion_id, level_id, points, comment)
+values ($1, $2, $3, $4, $5)
+`
+)
 
 func (r *MarksRepo) Create(
 	ctx context.Context,
 	opts repo.MarksRepoCreateOpts,
 ) (models.Mark, error) {
-	rows, err := r.db.QueryxContext(ctx, marksRepoCreateQuery, opts.Mark, opts.Comment, opts.AnswerId)
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return models.Mark{}, fmt.Errorf("r.db.QueryContext: %w", err)
+		return models.Mark{}, fmt.Errorf("r.db.BeginTxx: %w", err)
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	if !rows.Next() {
-		return models.Mark{}, errors.New("no rows returned")
-	}
 	var m mark
-	if err = rows.StructScan(&m); err != nil {
-		return models.Mark{}, fmt.Errorf("rows.StructScan: %w", err)
+	if err = tx.GetContext(ctx, &m, marksRepoCreateQuery, opts.Mark, opts.Comment, opts.AnswerId, opts.RawMark); err != nil {
+		return models.Mark{}, fmt.Errorf("tx.GetContext: %w", err)
 	}
-	if rows.Err() != nil {
-		return models.Mark{}, fmt.Errorf("rows.Err: %w", err)
+	if err = r.replaceCriteria(ctx, tx, m.Id, opts.Criteria); err != nil {
+		return models.Mark{}, fmt.Errorf("r.replaceCriteria: %w", err)
 	}
 
-	return m.toServiceModel(), nil
-}
+	if err = tx.Commit(); err != nil {
+		return models.Mark{}, fmt.Errorf("tx.Commit: %w", err)
+	}
 
-const marksRepoUpdateQuery = `
-update public.mark set mark = $1, comment = $2, updated_at = now()
-where id = $3
-returning id, mark, comment, answer_id, created_at, updated_at
-`
+	result := m.toServiceModel()
+	result.Criteria = opts.Criteria
+	return result, nil
+}
 
 func (r *MarksRepo) Update(
 	ctx context.Context,
 	opts repo.MarksRepoUpdateOpts,
 ) (models.Mark, error) {
-	rows, err := r.db.QueryxContext(ctx, marksRepoUpdateQuery, opts.Mark, opts.Comment, opts.Id)
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return models.Mark{}, fmt.Errorf("r.db.QueryxContext: %w", err)
+		return models.Mark{}, fmt.Errorf("r.db.BeginTxx: %w", err)
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	if !rows.Next() {
-		return models.Mark{}, errors.New("no rows returned")
-	}
 	var m mark
-	if err = rows.StructScan(&m); err != nil {
-		return models.Mark{}, fmt.Errorf("rows.StructScan: %w", err)
+	if err = tx.GetContext(ctx, &m, marksRepoUpdateQuery, opts.Mark, opts.Comment, opts.Id, opts.RawMark); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Mark{}, repo.ErrNotFound
+		}
+		return models.Mark{}, fmt.Errorf("tx.GetContext: %w", err)
+	}
+	if err = r.replaceCriteria(ctx, tx, m.Id, opts.Criteria); err != nil {
+		return models.Mark{}, fmt.Errorf("r.replaceCriteria: %w", err)
 	}
-	if rows.Err() != nil {
-		return models.Mark{}, fmt.Errorf("rows.Err: %w", err)
+
+	if err = tx.Commit(); err != nil {
+		return models.Mark{}, fmt.Errorf("tx.Commit: %w", err)
 	}
 
-	return m.toServiceModel(), nil
+	result := m.toServiceModel()
+	result.Criteria = opts.Criteria
+	return result, nil
+}
+
+func (r *MarksRepo) replaceCriteria(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	markId int64,
+	criteria []models.MarkCriterion,
+) error {
+	if _, err := tx.ExecContext(ctx, marksRepoDeleteCriteriaQuery, markId); err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	for _, criterion := range criteria {
+		if _, err := tx.ExecContext(
+			ctx, marksRepoCreateCriterionQuery,
+			markId, criterion.CriterionId, criterion.LevelId, criterion.Points, criterion.Comment,
+		); err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+	}
+	return nil
 }
 
 const marksRepoDeleteQuery = `
@@ -0,0 +1,259 @@
+package pg
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+type orgUnit struct {
+	Id        int64      `db:"id"`
+	ParentId  *int64     `db:"parent_id"`
+	Kind      string     `db:"kind"`
+	Name      string     `db:"name"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt *time.Time `db:"updated_at"`
+}
+
+func (u orgUnit) toServiceModel() models.OrgUnit {
+	return models.OrgUnit{
+		Id:        u.Id,
+		ParentId:  u.ParentId,
+		Kind:      u.Kind,
+		Name:      u.Name,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
+
+type OrgUnitsRepo struct {
+	db *sqlx.DB
+}
+
+func NewOrgUnitsRepo(db *sqlx.DB) *OrgUnitsRepo {
+	return &OrgUnitsRepo{db: db}
+}
+
+const orgUnitsRepoGetByIdQuery = `
+select 
+    ou.id, 
+    ou.parent_id, 
+    ou.kind, 
+    ou.name, 
+    ou.created_at, 
+    ou.updated_at
+from public.org_unit ou
+where ou.id = $1
+`
+
+func (r *OrgUnitsRepo) GetById(
+	ctx context.Context,
+	id int64,
+) (models.OrgUnit, error) {
+	var u orgUnit
+	if err := r.db.GetContext(ctx, &u, orgUnitsRepoGetByIdQuery, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OrgUnit{}, repo.ErrNotFound
+		}
+		return models.OrgUnit{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return u.toServiceModel(), nil
+}
+
+const orgUnitsRepoGetListQuery = `
+select 
+    ou.id, 
+    ou.parent_id, 
+    ou.kind, 
+    ou.name, 
+    ou.created_at, 
+    ou.updated_at
+from public.org_unit ou
+order by ou.parent_id nulls first, ou.name
+`
+
+func (r *OrgUnitsRepo) GetList(
+	ctx context.Context,
+) ([]models.OrgUnit, error) {
+	var units []orgUnit
+	if err := r.db.SelectContext(ctx, &units, orgUnitsRepoGetListQuery); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return lo.Map(
+		units,
+		func(item orgUnit, _ int) models.OrgUnit {
+			return item.toServiceModel()
+		},
+	), nil
+}
+
+const orgUnitsRepoCreateQuery = `
+insert into public.org_unit (parent_id, kind, name)
+values ($1, $2, $3)
+returning id, parent_id, kind, name, created_at, updated_at
+`
+
+func (r *OrgUnitsRepo) Create(
+	ctx context.Context,
+	opts repo.OrgUnitsRepoCreateOpts,
+) (models.OrgUnit, error) {
+	var u orgUnit
+	if err := r.db.GetContext(ctx, &u, orgUnitsRepoCreateQuery, opts.ParentId, opts.Kind, opts.Name); err != nil {
+		return models.OrgUnit{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return u.toServiceModel(), nil
+}
+
+const orgUnitsRepoUpdateQuery = `
+update public.org_unit
+set parent_id = $2, kind = $3, name = $4, updated_at = now()
+where id = $1
+returning id, parent_id, kind, name, created_at, updated_at
+`
+
+func (r *OrgUnitsRepo) Update(
+	ctx context.Context,
+	opts repo.OrgUnitsRepoUpdateOpts,
+) (models.OrgUnit, error) {
+	var u orgUnit
+	if err := r.db.GetContext(ctx, &u, orgUnitsRepoUpdateQuery, opts.Id, opts.ParentId, opts.Kind, opts.Name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OrgUnit{}, repo.ErrNotFound
+		}
+		return models.OrgUnit{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return u.toServiceModel(), nil
+}
+
+const orgUnitsRepoDeleteQuery = `
+delete from public.org_unit where id = $1
+`
+
+func (r *OrgUnitsRepo) Delete(
+	ctx context.Context,
+	id int64,
+) error {
+	if _, err := r.db.ExecContext(ctx, orgUnitsRepoDeleteQuery, id); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+const orgUnitsRepoHasChildrenQuery = `
+select exists (select 1 from public.org_unit ou where ou.parent_id = $1)
+`
+
+func (r *OrgUnitsRepo) HasChildren(
+	ctx context.Context,
+	id int64,
+) (bool, error) {
+	var ok bool
+	if err := r.db.GetContext(ctx, &ok, orgUnitsRepoHasChildrenQuery, id); err != nil {
+		return false, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return ok, nil
+}
+
+const orgUnitsRepoHasGroupsQuery = `
+select exists (select 1 from public."group" g where g.org_unit_id = $1)
+`
+
+func (r *OrgUnitsRepo) HasGroups(
+	ctx context.Context,
+	id int64,
+) (bool, error) {
+	var ok bool
+	if err := r.db.GetContext(ctx, &ok, orgUnitsRepoHasGroupsQuery, id); err != nil {
+		return false, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return ok, nil
+}
+
+const orgUnitsRepoIsInSubtreeQuery = `
+with recursive subtree as (
+    select ou.id
+    from public.org_unit ou
+    where ou.id = $1
+    union
+    select ou.id
+    from public.org_unit ou
+    join subtree s on ou.parent_id = s.id
+)
+select exists (select 1 from subtree where id = $2)
+`
+
+func (r *OrgUnitsRepo) IsInSubtree(
+	ctx context.Context,
+	rootId, id int64,
+) (bool, error) {
+	var ok bool
+	if err := r.db.GetContext(ctx, &ok, orgUnitsRepoIsInSubtreeQuery, rootId, id); err != nil {
+		return false, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return ok, nil
+}
+
+const orgUnitsRepoIsManagedByQuery = `
+with recursive subtree as (
+    select oua.org_unit_id id
+    from public.org_unit_admin oua
+    where oua.user_id = $2
+    union
+    select ou.id
+    from public.org_unit ou
+    join subtree s on ou.parent_id = s.id
+)
+select not exists (select 1 from public.org_unit_admin oua where oua.user_id = $2)
+    or exists (select 1 from subtree where id = $1)
+`
+
+// IsManagedBy tells whether the administrator may change the unit: global
+// administrators manage every unit and the root, scoped ones only their subtrees.
+func (r *OrgUnitsRepo) IsManagedBy(
+	ctx context.Context,
+	id *int64,
+	userId int64,
+) (bool, error) {
+	var ok bool
+	if err := r.db.GetContext(ctx, &ok, orgUnitsRepoIsManagedByQuery, id, userId); err != nil {
+		return false, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return ok, nil
+}
+
+const orgUnitsRepoCreateAdminQuery = `
+insert into public.org_unit_admin (user_id, org_unit_id)
+values ($1, $2)
+on conflict do nothing
+`
+
+func (r *OrgUnitsRepo) CreateAdmin(
+	ctx context.Context,
+	opts repo.OrgUnitsRepoAdminOpts,
+) error {
+	if _, err := r.db.ExecContext(ctx, orgUnitsRepoCreateAdminQuery, opts.UserId, opts.OrgUnitId); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+const orgUnitsRepoDeleteAdminQuery = `
+delete from public.org_unit_admin where user_id = $1 and org_unit_id = $2
+`
+
+func (r *OrgUnitsRepo) DeleteAdmin(
+	ctx context.Context,
+	opts repo.OrgUnitsRepoAdminOpts,
+) error {
+	if _, err := r.db.ExecContext(ctx, orgUnitsRepoDeleteAdminQuery, opts.UserId, opts.OrgUnitId); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
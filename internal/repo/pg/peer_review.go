@@ -0,0 +1,488 @@
+package pg
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+type peerReviewSettings struct {
+	TaskId           int64      `db:"task_id"`
+	ReviewsPerAnswer int64      `db:"reviews_per_answer"`
+	RubricId         *int64     `db:"rubric_id"`
+	MarkWeight       int64      `db:"mark_weight"`
+	AssignedAt       *time.Time `db:"assigned_at"`
+	CreatedAt        time.Time  `db:"created_at"`
+	UpdatedAt        *time.Time `db:"updated_at"`
+}
+
+func (s peerReviewSettings) toServiceModel() models.PeerReviewSettings {
+	return models.PeerReviewSettings{
+		TaskId:           s.TaskId,
+		ReviewsPerAnswer: s.ReviewsPerAnswer,
+		RubricId:         s.RubricId,
+		MarkWeight:       s.MarkWeight,
+		AssignedAt:       s.AssignedAt,
+		CreatedAt:        s.CreatedAt,
+		UpdatedAt:        s.UpdatedAt,
+	}
+}
+
+type peerReview struct {
+	Id                int64      `db:"id"`
+	TaskId            int64      `db:"task_id"`
+	AnswerId          int64      `db:"answer_id"`
+	ReviewerId        int64      `db:"reviewer_id"`
+	ReviewerName      string     `db:"reviewer_name"`
+	State             string     `db:"state"`
+	Score             *int64     `db:"score"`
+	Text              *string    `db:"text"`
+	ModerationComment *string    `db:"moderation_comment"`
+	CreatedAt         time.Time  `db:"created_at"`
+	SubmittedAt       *time.Time `db:"submitted_at"`
+	ModeratedAt       *time.Time `db:"moderated_at"`
+}
+
+func (r peerReview) toServiceModel() models.PeerReview {
+	return models.PeerReview{
+		Id:                r.Id,
+		TaskId:            r.TaskId,
+		AnswerId:          r.AnswerId,
+		ReviewerId:        r.ReviewerId,
+		ReviewerName:      r.ReviewerName,
+		State:             r.State,
+		Score:             r.Score,
+		Text:              r.Text,
+		ModerationComment: r.ModerationComment,
+		CreatedAt:         r.CreatedAt,
+		SubmittedAt:       r.SubmittedAt,
+		ModeratedAt:       r.ModeratedAt,
+	}
+}
+
+type peerReviewCriterion struct {
+	PeerReviewId int64 `db:"peer_review_id"`
+	markCriterion
+}
+
+type PeerReviewsRepo struct {
+	db *sqlx.DB
+}
+
+func NewPeerReviewsRepo(db *sqlx.DB) *PeerReviewsRepo {
+	return &PeerReviewsRepo{db: db}
+}
+
+const peerReviewsRepoGetSettingsQuery = `
+select tpr.task_id, tpr.reviews_per_answer, tpr.rubric_id, tpr.mark_weight, tpr.assigned_at, tpr.created_at, tpr.updated_at
+from public.task_peer_review tpr
+where tpr.task_id = $1
+`
+
+func (r *PeerReviewsRepo) GetSettings(
+	ctx context.Context,
+	taskId int64,
+) (models.PeerReviewSettings, error) {
+	var s peerReviewSettings
+	if err := r.db.GetContext(ctx, &s, peerReviewsRepoGetSettingsQuery, taskId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.PeerReviewSettings{}, repo.ErrNotFound
+		}
+		return models.PeerReviewSettings{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return s.toServiceModel(), nil
+}
+
+const peerReviewsRepoSetSettingsQuery = `
+insert into public.task_peer_review (task_id, reviews_per_answer, rubric_id, mark_weight)
+values ($1, $2, $3, $4)
+on conflict (task_id) do update
+set reviews_per_answer = excluded.reviews_per_answer,
+    rubric_id = excluded.rubric_id,
+    mark_weight = excluded.mark_weight,
+    updated_at = now()
+returning task_id, reviews_per_answer, rubric_id, mark_weight, assigned_at, created_at, updated_at
+`
+
+func (r *PeerReviewsRepo) SetSettings(
+	ctx context.Context,
+	opts repo.PeerReviewsRepoSetSettingsOpts,
+) (models.PeerReviewSettings, error) {
+	var s peerReviewSettings
+	if err := r.db.GetContext(
+		ctx, &s, peerReviewsRepoSetSettingsQuery,
+		opts.TaskId,
+		opts.ReviewsPerAnswer,
+		opts.RubricId,
+		opts.MarkWeight,
+	); err != nil {
+		return models.PeerReviewSettings{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return s.toServiceModel(), nil
+}
+
+const (
+	peerReviewsRepoDeleteReviewsQuery = `
+delete from public.peer_review where task_id = $1
+`
+	peerReviewsRepoDeleteSettingsQuery = `
+delete from public.task_peer_review where task_id = $1
+`
+)
+
+// DeleteSettings turns peer review off for the task and drops its reviews.
+func (r *PeerReviewsRepo) DeleteSettings(
+	ctx context.Context,
+	taskId int64,
+) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, peerReviewsRepoDeleteReviewsQuery, taskId); err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, peerReviewsRepoDeleteSettingsQuery, taskId)
+	if err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return repo.ErrNotFound
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+	return nil
+}
+
+// peerReviewsRepoGetDueQuery selects the published tasks that are over for
+// every assignee, extensions on their user or group links included, but have
+// not had their reviews assigned yet.
+const peerReviewsRepoGetDueQuery = `
+select tpr.task_id, tpr.reviews_per_answer, tpr.rubric_id, tpr.mark_weight, tpr.assigned_at, tpr.created_at, tpr.updated_at
+from public.task_peer_review tpr
+join public.task t on t.id = tpr.task_id
+where tpr.assigned_at is null
+  and t.state = 'published'
+  and t.deleted_at is null
+  and coalesce((
+      select max(coalesce(tl.effective_till, t.effective_till))
+      from public.task_links tl
+      where tl.task_id = t.id
+  ), t.effective_till) <= $1
+order by tpr.task_id
+`
+
+func (r *PeerReviewsRepo) GetDue(
+	ctx context.Context,
+	now time.Time,
+) ([]models.PeerReviewSettings, error) {
+	var settings []peerReviewSettings
+	if err := r.db.SelectContext(ctx, &settings, peerReviewsRepoGetDueQuery, now); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return lo.Map(
+		settings,
+		func(item peerReviewSettings, _ int) models.PeerReviewSettings {
+			return item.toServiceModel()
+		},
+	), nil
+}
+
+const (
+	peerReviewsRepoLockSettingsQuery = `
+select tpr.task_id
+from public.task_peer_review tpr
+where tpr.task_id = $1
+  and tpr.assigned_at is null
+for update
+`
+	// peerReviewsRepoAssignQuery puts the answers of the task in a random
+	// circle and has the author of each review the next $2 answers, so that
+	// every answer gets the same number of reviews and nobody reviews their
+	// own. With fewer answers than that each author reviews all the others.
+	peerReviewsRepoAssignQuery = `
+with submitted as (
+    select
+        a.id answer_id,
+        a.user_id,
+        row_number() over (order by random()) - 1 pos,
+        count(*) over () total
+    from public.answer a
+    where a.task_id = $1
+      and a.deleted_at is null
+)
+insert into public.peer_review (task_id, answer_id, reviewer_id)
+select $1, r.answer_id, s.user_id
+from submitted s
+cross join generate_series(1, least($2, s.total - 1)) k
+join submitted r on r.pos = (s.pos + k) % s.total
+on conflict (answer_id, reviewer_id) do nothing
+`
+	peerReviewsRepoMarkAssignedQuery = `
+update public.task_peer_review set assigned_at = now() where task_id = $1
+`
+)
+
+// Assign creates the reviews of the task and returns how many were created.
+// Tasks assigned already are left alone and give repo.ErrConflict.
+func (r *PeerReviewsRepo) Assign(
+	ctx context.Context,
+	taskId, reviewsPerAnswer int64,
+) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var locked int64
+	if err = tx.GetContext(ctx, &locked, peerReviewsRepoLockSettingsQuery, taskId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, repo.ErrConflict
+		}
+		return 0, fmt.Errorf("tx.GetContext: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, peerReviewsRepoAssignQuery, taskId, reviewsPerAnswer)
+	if err != nil {
+		return 0, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	assigned, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, peerReviewsRepoMarkAssignedQuery, taskId); err != nil {
+		return 0, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("tx.Commit: %w", err)
+	}
+	return assigned, nil
+}
+
+const (
+	peerReviewsRepoSelect = `
+select
+    pr.id,
+    pr.task_id,
+    pr.answer_id,
+    pr.reviewer_id,
+    u.last_name || ' ' || u.first_name reviewer_name,
+    pr.state,
+    pr.score,
+    pr.text,
+    pr.moderation_comment,
+    pr.created_at,
+    pr.submitted_at,
+    pr.moderated_at
+from public.peer_review pr
+join public.user u on u.id = pr.reviewer_id
+join public.answer a on a.id = pr.answer_id
+`
+	peerReviewsRepoGetByIdQuery = peerReviewsRepoSelect + `
+where pr.id = $1
+  and a.deleted_at is null
+`
+	peerReviewsRepoGetCriteriaQuery = `
+select prc.peer_review_id, prc.criterion_id, rc.name criterion_name, prc.level_id, rl.name level_name, prc.points, prc.comment
+from public.peer_review_criterion prc
+join public.rubric_criterion rc on rc.id = prc.criterion_id
+join public.rubric_level rl on rl.id = prc.level_id
+where prc.peer_review_id = any ($1::bigint[])
+order by rc.position, rc.id
+`
+)
+
+func (r *PeerReviewsRepo) GetById(
+	ctx context.Context,
+	id int64,
+) (models.PeerReview, error) {
+	var pr peerReview
+	if err := r.db.GetContext(ctx, &pr, peerReviewsRepoGetByIdQuery, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.PeerReview{}, repo.ErrNotFound
+		}
+		return models.PeerReview{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	reviews, err := r.withCriteria(ctx, []peerReview{pr})
+	if err != nil {
+		return models.PeerReview{}, err
+	}
+	return reviews[0], nil
+}
+
+func (r *PeerReviewsRepo) withCriteria(
+	ctx context.Context,
+	reviews []peerReview,
+) ([]models.PeerReview, error) {
+	var criteria []peerReviewCriterion
+	if err := r.db.SelectContext(
+		ctx, &criteria, peerReviewsRepoGetCriteriaQuery,
+		lo.Map(reviews, func(item peerReview, _ int) int64 { return item.Id }),
+	); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	byReview := lo.GroupBy(criteria, func(item peerReviewCriterion) int64 { return item.PeerReviewId })
+	return lo.Map(
+		reviews,
+		func(item peerReview, _ int) models.PeerReview {
+			result := item.toServiceModel()
+			result.Criteria = lo.Map(
+				byReview[item.Id],
+				func(c peerReviewCriterion, _ int) models.MarkCriterion {
+					return c.toServiceModel()
+				},
+			)
+			return result
+		},
+	), nil
+}
+
+const peerReviewsRepoGetListQuery = peerReviewsRepoSelect + `
+where pr.task_id = $1
+  and a.deleted_at is null
+  and ($2::bigint is null or pr.reviewer_id = $2)
+  and ($3::bigint is null or pr.answer_id = $3)
+  and (not $4 or pr.state = 'submitted')
+order by pr.answer_id, pr.id
+`
+
+func (r *PeerReviewsRepo) GetList(
+	ctx context.Context,
+	opts repo.PeerReviewsRepoGetListOpts,
+) ([]models.PeerReview, error) {
+	var reviews []peerReview
+	if err := r.db.SelectContext(
+		ctx, &reviews, peerReviewsRepoGetListQuery,
+		opts.TaskId, opts.ReviewerId, opts.AnswerId, opts.SubmittedOnly,
+	); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return r.withCriteria(ctx, reviews)
+}
+
+const (
+	peerReviewsRepoSubmitQuery = `
+update public.peer_review
+set state = 'submitted',
+    score = $2,
+    text = $3,
+    submitted_at = now()
+where id = $1
+  and state <> 'rejected'
+`
+	peerReviewsRepoDeleteCriteriaQuery = `
+delete from public.peer_review_criterion where peer_review_id = $1
+`
+	peerReviewsRepoCreateCriterionQuery = `
+insert into public.peer_review_criterion (peer_review_id, criterion_id, level_id, points, comment)
+values ($1, $2, $3, $4, $5)
+`
+)
+
+// Submit saves the review. It can be rewritten until a moderator rejects it.
+func (r *PeerReviewsRepo) Submit(
+	ctx context.Context,
+	opts repo.PeerReviewsRepoSubmitOpts,
+) (models.PeerReview, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.PeerReview{}, fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, peerReviewsRepoSubmitQuery, opts.Id, opts.Score, opts.Text)
+	if err != nil {
+		return models.PeerReview{}, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return models.PeerReview{}, fmt.Errorf("res.RowsAffected: %w", err)
+	} else if affected == 0 {
+		return models.PeerReview{}, repo.ErrNotFound
+	}
+
+	if _, err = tx.ExecContext(ctx, peerReviewsRepoDeleteCriteriaQuery, opts.Id); err != nil {
+		return models.PeerReview{}, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	for _, criterion := range opts.Criteria {
+		if _, err = tx.ExecContext(
+			ctx, peerReviewsRepoCreateCriterionQuery,
+			opts.Id, criterion.CriterionId, criterion.LevelId, criterion.Points, criterion.Comment,
+		); err != nil {
+			return models.PeerReview{}, fmt.Errorf("tx.ExecContext: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.PeerReview{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+	return r.GetById(ctx, opts.Id)
+}
+
+// peerReviewsRepoModerateQuery rejects the review or lets it back in, in the
+// state it would have had without moderation.
+const peerReviewsRepoModerateQuery = `
+update public.peer_review
+set state = case
+        when $2 then 'rejected'
+        when submitted_at is not null then 'submitted'
+        else 'assigned'
+    end,
+    moderation_comment = $3,
+    moderated_at = now()
+where id = $1
+`
+
+func (r *PeerReviewsRepo) Moderate(
+	ctx context.Context,
+	opts repo.PeerReviewsRepoModerateOpts,
+) (models.PeerReview, error) {
+	res, err := r.db.ExecContext(ctx, peerReviewsRepoModerateQuery, opts.Id, opts.Rejected, opts.Comment)
+	if err != nil {
+		return models.PeerReview{}, fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return models.PeerReview{}, fmt.Errorf("res.RowsAffected: %w", err)
+	} else if affected == 0 {
+		return models.PeerReview{}, repo.ErrNotFound
+	}
+	return r.GetById(ctx, opts.Id)
+}
+
+const peerReviewsRepoGetScoreQuery = `
+select round(avg(pr.score))::bigint
+from public.peer_review pr
+where pr.answer_id = $1
+  and pr.state = 'submitted'
+  and pr.score is not null
+`
+
+// GetScore returns the average score of the submitted reviews of the answer,
+// or nil when none of them has a score.
+func (r *PeerReviewsRepo) GetScore(
+	ctx context.Context,
+	answerId int64,
+) (*int64, error) {
+	var score *int64
+	if err := r.db.GetContext(ctx, &score, peerReviewsRepoGetScoreQuery, answerId); err != nil {
+		return nil, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return score, nil
+}
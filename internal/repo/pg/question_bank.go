@@ -0,0 +1,327 @@
+package pg
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/samber/lo"
+)
+
+type questionBank struct {
+	Id          int64      `db:"id"`
+	Name        string     `db:"name"`
+	Description *string    `db:"description"`
+	CreatedBy   int64      `db:"created_by"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   *time.Time `db:"updated_at"`
+}
+
+func (b questionBank) toServiceModel() models.QuestionBank {
+	return models.QuestionBank{
+		Id:          b.Id,
+		Name:        b.Name,
+		Description: b.Description,
+		CreatedBy:   b.CreatedBy,
+		CreatedAt:   b.CreatedAt,
+		UpdatedAt:   b.UpdatedAt,
+	}
+}
+
+// questionAnswerKey is the shape of question.answer_key, kept apart from the
+// options so that the key never leaks into queries that only need prompts.
+type questionAnswerKey struct {
+	CorrectOptions  []int64  `json:"correctOptions,omitempty"`
+	Answer          *float64 `json:"answer,omitempty"`
+	Tolerance       *float64 `json:"tolerance,omitempty"`
+	AcceptedAnswers []string `json:"acceptedAnswers,omitempty"`
+}
+
+type question struct {
+	Id        int64  `db:"id"`
+	BankId    int64  `db:"bank_id"`
+	Position  int64  `db:"position"`
+	Kind      string `db:"kind"`
+	Text      string `db:"text"`
+	Points    int64  `db:"points"`
+	Options   []byte `db:"options"`
+	AnswerKey []byte `db:"answer_key"`
+}
+
+func (q question) toServiceModel() (models.Question, error) {
+	var options []string
+	if err := jsoniter.Unmarshal(q.Options, &options); err != nil {
+		return models.Question{}, fmt.Errorf("jsoniter.Unmarshal: %w", err)
+	}
+
+	var key questionAnswerKey
+	if err := jsoniter.Unmarshal(q.AnswerKey, &key); err != nil {
+		return models.Question{}, fmt.Errorf("jsoniter.Unmarshal: %w", err)
+	}
+
+	return models.Question{
+		Id:              q.Id,
+		BankId:          q.BankId,
+		Position:        q.Position,
+		Kind:            q.Kind,
+		Text:            q.Text,
+		Points:          q.Points,
+		Options:         options,
+		CorrectOptions:  key.CorrectOptions,
+		Answer:          key.Answer,
+		Tolerance:       key.Tolerance,
+		AcceptedAnswers: key.AcceptedAnswers,
+	}, nil
+}
+
+type QuestionBanksRepo struct {
+	db *sqlx.DB
+}
+
+func NewQuestionBanksRepo(db *sqlx.DB) *QuestionBanksRepo {
+	return &QuestionBanksRepo{db: db}
+}
+
+const (
+	questionBanksRepoGetByIdQuery = `
+select qb.id, qb.name, qb.description, qb.created_by, qb.created_at, qb.updated_at
+from public.question_bank qb
+where qb.id = $1
+`
+	questionBanksRepoGetQuestionsQuery = `
+select q.id, q.bank_id, q.position, q.kind, q.text, q.points, q.options, q.answer_key
+from public.question q
+where q.bank_id = $1
+order by q.position, q.id
+`
+)
+
+func (r *QuestionBanksRepo) GetById(
+	ctx context.Context,
+	id int64,
+) (models.QuestionBank, error) {
+	var qb questionBank
+	if err := r.db.GetContext(ctx, &qb, questionBanksRepoGetByIdQuery, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.QuestionBank{}, repo.ErrNotFound
+		}
+		return models.QuestionBank{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+
+	var questions []question
+	if err := r.db.SelectContext(ctx, &questions, questionBanksRepoGetQuestionsQuery, id); err != nil {
+		return models.QuestionBank{}, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	result := qb.toServiceModel()
+	result.Questions = make([]models.Question, 0, len(questions))
+	for _, item := range questions {
+		q, err := item.toServiceModel()
+		if err != nil {
+			return models.QuestionBank{}, fmt.Errorf("item.toServiceModel: %w", err)
+		}
+		result.Questions = append(result.Questions, q)
+	}
+	return result, nil
+}
+
+const questionBanksRepoGetListQuery = `
+select qb.id, qb.name, qb.description, qb.created_by, qb.created_at, qb.updated_at
+from public.question_bank qb
+order by qb.id desc
+limit $1
+offset $2
+`
+
+func (r *QuestionBanksRepo) GetList(
+	ctx context.Context,
+	opts repo.QuestionBanksRepoGetListOpts,
+) ([]models.QuestionBank, error) {
+	var banks []questionBank
+	if err := r.db.SelectContext(ctx, &banks, questionBanksRepoGetListQuery, opts.Limit, opts.Offset); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return lo.Map(
+		banks,
+		func(item questionBank, _ int) models.QuestionBank {
+			return item.toServiceModel()
+		},
+	), nil
+}
+
+const questionBanksRepoGetCountQuery = `
+select count(*) from public.question_bank
+`
+
+func (r *QuestionBanksRepo) GetCount(
+	ctx context.Context,
+) (int64, error) {
+	var count int64
+	if err := r.db.GetContext(ctx, &count, questionBanksRepoGetCountQuery); err != nil {
+		return 0, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return count, nil
+}
+
+const (
+	questionBanksRepoCreateQuery = `
+insert into public.question_bank (name, description, created_by)
+values ($1, $2, $3)
+returning id
+`
+	questionBanksRepoUpdateQuery = `
+update public.question_bank
+set name = $2, description = $3, updated_at = now()
+where id = $1
+`
+	questionBanksRepoDeleteQuestionsQuery = `
+delete from public.question where bank_id = $1
+`
+	questionBanksRepoCreateQuestionQuery = `
+insert into public.question (bank_id, position, kind, text, points, options, answer_key)
+values ($1, $2, $3, $4, $5, $6::jsonb, $7::jsonb)
+`
+)
+
+func (r *QuestionBanksRepo) Create(
+	ctx context.Context,
+	opts repo.QuestionBanksRepoCreateOpts,
+) (models.QuestionBank, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.QuestionBank{}, fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	if err = tx.GetContext(ctx, &id, questionBanksRepoCreateQuery, opts.Name, opts.Description, opts.CreatedBy); err != nil {
+		return models.QuestionBank{}, fmt.Errorf("tx.GetContext: %w", err)
+	}
+	if err = r.createQuestions(ctx, tx, id, opts.Questions); err != nil {
+		return models.QuestionBank{}, fmt.Errorf("r.createQuestions: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.QuestionBank{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+	return r.GetById(ctx, id)
+}
+
+func (r *QuestionBanksRepo) Update(
+	ctx context.Context,
+	opts repo.QuestionBanksRepoUpdateOpts,
+) (models.QuestionBank, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.QuestionBank{}, fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, questionBanksRepoUpdateQuery, opts.Id, opts.Name, opts.Description)
+	if err != nil {
+		return models.QuestionBank{}, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return models.QuestionBank{}, fmt.Errorf("res.RowsAffected: %w", err)
+	} else if affected == 0 {
+		return models.QuestionBank{}, repo.ErrNotFound
+	}
+
+	if _, err = tx.ExecContext(ctx, questionBanksRepoDeleteQuestionsQuery, opts.Id); err != nil {
+		return models.QuestionBank{}, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	if err = r.createQuestions(ctx, tx, opts.Id, opts.Questions); err != nil {
+		return models.QuestionBank{}, fmt.Errorf("r.createQuestions: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.QuestionBank{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+	return r.GetById(ctx, opts.Id)
+}
+
+func (r *QuestionBanksRepo) createQuestions(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	bankId int64,
+	questions []models.Question,
+) error {
+	for i, q := range questions {
+		options, err := jsoniter.Marshal(lo.Ternary(q.Options == nil, []string{}, q.Options))
+		if err != nil {
+			return fmt.Errorf("jsoniter.Marshal: %w", err)
+		}
+		key, err := jsoniter.Marshal(questionAnswerKey{
+			CorrectOptions:  q.CorrectOptions,
+			Answer:          q.Answer,
+			Tolerance:       q.Tolerance,
+			AcceptedAnswers: q.AcceptedAnswers,
+		})
+		if err != nil {
+			return fmt.Errorf("jsoniter.Marshal: %w", err)
+		}
+
+		if _, err = tx.ExecContext(
+			ctx, questionBanksRepoCreateQuestionQuery,
+			bankId, i, q.Kind, q.Text, q.Points, string(options), string(key),
+		); err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+	}
+	return nil
+}
+
+const questionBanksRepoDeleteQuery = `
+delete from public.question_bank where id = $1
+`
+
+func (r *QuestionBanksRepo) Delete(
+	ctx context.Context,
+	id int64,
+) error {
+	if _, err := r.db.ExecContext(ctx, questionBanksRepoDeleteQuery, id); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+const questionBanksRepoIsAttachedQuery = `
+select exists (select 1 from public.task_quiz tq where tq.bank_id = $1)
+`
+
+func (r *QuestionBanksRepo) IsAttached(
+	ctx context.Context,
+	id int64,
+) (bool, error) {
+	var ok bool
+	if err := r.db.GetContext(ctx, &ok, questionBanksRepoIsAttachedQuery, id); err != nil {
+		return false, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return ok, nil
+}
+
+const questionBanksRepoIsAnsweredQuery = `
+select exists (
+    select 1
+    from public.quiz_response qr
+    join public.question q on q.id = qr.question_id
+    where q.bank_id = $1
+)
+`
+
+func (r *QuestionBanksRepo) IsAnswered(
+	ctx context.Context,
+	id int64,
+) (bool, error) {
+	var ok bool
+	if err := r.db.GetContext(ctx, &ok, questionBanksRepoIsAnsweredQuery, id); err != nil {
+		return false, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return ok, nil
+}
@@ -0,0 +1,243 @@
+package pg
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	jsoniter "github.com/json-iterator/go"
+)
+
+type taskQuiz struct {
+	TaskId        int64      `db:"task_id"`
+	BankId        int64      `db:"bank_id"`
+	QuestionCount *int64     `db:"question_count"`
+	Shuffle       bool       `db:"shuffle"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     *time.Time `db:"updated_at"`
+}
+
+func (q taskQuiz) toServiceModel() models.TaskQuiz {
+	return models.TaskQuiz{
+		TaskId:        q.TaskId,
+		BankId:        q.BankId,
+		QuestionCount: q.QuestionCount,
+		Shuffle:       q.Shuffle,
+		CreatedAt:     q.CreatedAt,
+		UpdatedAt:     q.UpdatedAt,
+	}
+}
+
+// quizResponseBody is the shape of quiz_response.response.
+type quizResponseBody struct {
+	Choices []int64  `json:"choices,omitempty"`
+	Number  *float64 `json:"number,omitempty"`
+	Text    *string  `json:"text,omitempty"`
+}
+
+type quizResponse struct {
+	QuestionId int64  `db:"question_id"`
+	Response   []byte `db:"response"`
+	IsCorrect  bool   `db:"is_correct"`
+	Points     int64  `db:"points"`
+}
+
+func (r quizResponse) toServiceModel() (models.QuizResponse, error) {
+	var body quizResponseBody
+	if err := jsoniter.Unmarshal(r.Response, &body); err != nil {
+		return models.QuizResponse{}, fmt.Errorf("jsoniter.Unmarshal: %w", err)
+	}
+	return models.QuizResponse{
+		QuestionId: r.QuestionId,
+		Choices:    body.Choices,
+		Number:     body.Number,
+		Text:       body.Text,
+		IsCorrect:  r.IsCorrect,
+		Points:     r.Points,
+	}, nil
+}
+
+type QuizzesRepo struct {
+	db *sqlx.DB
+}
+
+func NewQuizzesRepo(db *sqlx.DB) *QuizzesRepo {
+	return &QuizzesRepo{db: db}
+}
+
+const quizzesRepoGetByTaskIdQuery = `
+select tq.task_id, tq.bank_id, tq.question_count, tq.shuffle, tq.created_at, tq.updated_at
+from public.task_quiz tq
+where tq.task_id = $1
+`
+
+func (r *QuizzesRepo) GetByTaskId(
+	ctx context.Context,
+	taskId int64,
+) (models.TaskQuiz, error) {
+	var quiz taskQuiz
+	if err := r.db.GetContext(ctx, &quiz, quizzesRepoGetByTaskIdQuery, taskId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TaskQuiz{}, repo.ErrNotFound
+		}
+		return models.TaskQuiz{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return quiz.toServiceModel(), nil
+}
+
+const quizzesRepoSetQuery = `
+insert into public.task_quiz (task_id, bank_id, question_count, shuffle)
+values ($1, $2, $3, $4)
+on conflict (task_id) do update
+set bank_id = excluded.bank_id,
+    question_count = excluded.question_count,
+    shuffle = excluded.shuffle,
+    updated_at = now()
+returning task_id, bank_id, question_count, shuffle, created_at, updated_at
+`
+
+func (r *QuizzesRepo) Set(
+	ctx context.Context,
+	opts repo.QuizzesRepoSetOpts,
+) (models.TaskQuiz, error) {
+	var quiz taskQuiz
+	if err := r.db.GetContext(
+		ctx, &quiz, quizzesRepoSetQuery,
+		opts.TaskId, opts.BankId, opts.QuestionCount, opts.Shuffle,
+	); err != nil {
+		return models.TaskQuiz{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return quiz.toServiceModel(), nil
+}
+
+const quizzesRepoDeleteQuery = `
+delete from public.task_quiz where task_id = $1
+`
+
+func (r *QuizzesRepo) Delete(
+	ctx context.Context,
+	taskId int64,
+) error {
+	res, err := r.db.ExecContext(ctx, quizzesRepoDeleteQuery, taskId)
+	if err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	} else if affected == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+const quizzesRepoIsAnsweredQuery = `
+select exists (
+    select 1
+    from public.quiz_response qr
+    join public.answer a on a.id = qr.answer_id
+    where a.task_id = $1
+)
+`
+
+func (r *QuizzesRepo) IsAnswered(
+	ctx context.Context,
+	taskId int64,
+) (bool, error) {
+	var ok bool
+	if err := r.db.GetContext(ctx, &ok, quizzesRepoIsAnsweredQuery, taskId); err != nil {
+		return false, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return ok, nil
+}
+
+const quizzesRepoGetResponsesQuery = `
+select qr.question_id, qr.response, qr.is_correct, qr.points
+from public.quiz_response qr
+where qr.answer_id = $1
+order by qr.position
+`
+
+func (r *QuizzesRepo) GetResponses(
+	ctx context.Context,
+	answerId int64,
+) ([]models.QuizResponse, error) {
+	var responses []quizResponse
+	if err := r.db.SelectContext(ctx, &responses, quizzesRepoGetResponsesQuery, answerId); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	result := make([]models.QuizResponse, 0, len(responses))
+	for _, item := range responses {
+		response, err := item.toServiceModel()
+		if err != nil {
+			return nil, fmt.Errorf("item.toServiceModel: %w", err)
+		}
+		result = append(result, response)
+	}
+	return result, nil
+}
+
+const quizzesRepoCreateResponseQuery = `
+insert into public.quiz_response (answer_id, question_id, position, response, is_correct, points)
+values ($1, $2, $3, $4::jsonb, $5, $6)
+`
+
+const quizzesRepoCreateAnswerQuery = `
+insert into public.answer (task_id, user_id, team_id, is_late, late_seconds)
+values ($1, $2, $3, $4, $5)
+returning id
+`
+
+// Submit stores the quiz answer together with its responses and its mark, so
+// that a failure leaves no answer without a mark behind.
+func (r *QuizzesRepo) Submit(
+	ctx context.Context,
+	opts repo.QuizzesRepoSubmitOpts,
+) (models.Mark, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Mark{}, fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var answerId int64
+	if err = tx.GetContext(
+		ctx, &answerId, quizzesRepoCreateAnswerQuery,
+		opts.TaskId, opts.UserId, opts.TeamId, opts.IsLate, opts.LateSeconds,
+	); err != nil {
+		return models.Mark{}, fmt.Errorf("tx.GetContext: %w", err)
+	}
+
+	for i, response := range opts.Responses {
+		body, err := jsoniter.Marshal(quizResponseBody{
+			Choices: response.Choices,
+			Number:  response.Number,
+			Text:    response.Text,
+		})
+		if err != nil {
+			return models.Mark{}, fmt.Errorf("jsoniter.Marshal: %w", err)
+		}
+
+		if _, err = tx.ExecContext(
+			ctx, quizzesRepoCreateResponseQuery,
+			answerId, response.QuestionId, i, string(body), response.IsCorrect, response.Points,
+		); err != nil {
+			return models.Mark{}, fmt.Errorf("tx.ExecContext: %w", err)
+		}
+	}
+
+	var m mark
+	if err = tx.GetContext(ctx, &m, marksRepoCreateQuery, opts.Mark, nil, answerId, opts.RawMark); err != nil {
+		return models.Mark{}, fmt.Errorf("tx.GetContext: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Mark{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+	return m.toServiceModel(), nil
+}
@@ -0,0 +1,367 @@
+package pg
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+type rubric struct {
+	Id          int64      `db:"id"`
+	Name        string     `db:"name"`
+	Description *string    `db:"description"`
+	CreatedBy   int64      `db:"created_by"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   *time.Time `db:"updated_at"`
+}
+
+func (r rubric) toServiceModel() models.Rubric {
+	return models.Rubric{
+		Id:          r.Id,
+		Name:        r.Name,
+		Description: r.Description,
+		CreatedBy:   r.CreatedBy,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+type rubricCriterion struct {
+	Id          int64   `db:"id"`
+	RubricId    int64   `db:"rubric_id"`
+	Position    int64   `db:"position"`
+	Name        string  `db:"name"`
+	Description *string `db:"description"`
+}
+
+func (c rubricCriterion) toServiceModel() models.RubricCriterion {
+	return models.RubricCriterion{
+		Id:          c.Id,
+		RubricId:    c.RubricId,
+		Position:    c.Position,
+		Name:        c.Name,
+		Description: c.Description,
+	}
+}
+
+type rubricLevel struct {
+	Id          int64   `db:"id"`
+	CriterionId int64   `db:"criterion_id"`
+	Position    int64   `db:"position"`
+	Name        string  `db:"name"`
+	Description *string `db:"description"`
+	Points      int64   `db:"points"`
+}
+
+func (l rubricLevel) toServiceModel() models.RubricLevel {
+	return models.RubricLevel{
+		Id:          l.Id,
+		CriterionId: l.CriterionId,
+		Position:    l.Position,
+		Name:        l.Name,
+		Description: l.Description,
+		Points:      l.Points,
+	}
+}
+
+type RubricsRepo struct {
+	db *sqlx.DB
+}
+
+func NewRubricsRepo(db *sqlx.DB) *RubricsRepo {
+	return &RubricsRepo{db: db}
+}
+
+const (
+	rubricsRepoGetByIdQuery = `
+select r.id, r.name, r.description, r.created_by, r.created_at, r.updated_at
+from public.rubric r
+where r.id = $1
+`
+	rubricsRepoGetCriteriaQuery = `
+select rc.id, rc.rubric_id, rc.position, rc.name, rc.description
+from public.rubric_criterion rc
+where rc.rubric_id = $1
+order by rc.position, rc.id
+`
+	rubricsRepoGetLevelsQuery = `
+select rl.id, rl.criterion_id, rl.position, rl.name, rl.description, rl.points
+from public.rubric_level rl
+join public.rubric_criterion rc on rc.id = rl.criterion_id
+where rc.rubric_id = $1
+order by rl.position, rl.id
+`
+)
+
+func (r *RubricsRepo) GetById(
+	ctx context.Context,
+	id int64,
+) (models.Rubric, error) {
+	var rb rubric
+	if err := r.db.GetContext(ctx, &rb, rubricsRepoGetByIdQuery, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Rubric{}, repo.ErrNotFound
+		}
+		return models.Rubric{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+
+	var criteria []rubricCriterion
+	if err := r.db.SelectContext(ctx, &criteria, rubricsRepoGetCriteriaQuery, id); err != nil {
+		return models.Rubric{}, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	var levels []rubricLevel
+	if err := r.db.SelectContext(ctx, &levels, rubricsRepoGetLevelsQuery, id); err != nil {
+		return models.Rubric{}, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	levelsByCriterion := lo.GroupBy(levels, func(item rubricLevel) int64 { return item.CriterionId })
+
+	result := rb.toServiceModel()
+	result.Criteria = lo.Map(
+		criteria,
+		func(item rubricCriterion, _ int) models.RubricCriterion {
+			criterion := item.toServiceModel()
+			criterion.Levels = lo.Map(
+				levelsByCriterion[item.Id],
+				func(level rubricLevel, _ int) models.RubricLevel {
+					return level.toServiceModel()
+				},
+			)
+			return criterion
+		},
+	)
+	return result, nil
+}
+
+const rubricsRepoGetListQuery = `
+select r.id, r.name, r.description, r.created_by, r.created_at, r.updated_at
+from public.rubric r
+order by r.id desc
+limit $1
+offset $2
+`
+
+func (r *RubricsRepo) GetList(
+	ctx context.Context,
+	opts repo.RubricsRepoGetListOpts,
+) ([]models.Rubric, error) {
+	var rubrics []rubric
+	if err := r.db.SelectContext(ctx, &rubrics, rubricsRepoGetListQuery, opts.Limit, opts.Offset); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return lo.Map(
+		rubrics,
+		func(item rubric, _ int) models.Rubric {
+			return item.toServiceModel()
+		},
+	), nil
+}
+
+const rubricsRepoGetCountQuery = `
+select count(*) from public.rubric
+`
+
+func (r *RubricsRepo) GetCount(
+	ctx context.Context,
+) (int64, error) {
+	var count int64
+	if err := r.db.GetContext(ctx, &count, rubricsRepoGetCountQuery); err != nil {
+		return 0, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return count, nil
+}
+
+const (
+	rubricsRepoCreateQuery = `
+insert into public.rubric (name, description, created_by)
+values ($1, $2, $3)
+returning id
+`
+	rubricsRepoUpdateQuery = `
+update public.rubric
+set name = $2, description = $3, updated_at = now()
+where id = $1
+`
+	rubricsRepoDeleteCriteriaQuery = `
+delete from public.rubric_criterion where rubric_id = $1
+`
+	rubricsRepoCreateCriterionQuery = `
+insert into public.rubric_criterion (rubric_id, position, name, description)
+values ($1, $2, $3, $4)
+returning id
+`
+	rubricsRepoCreateLevelQuery = `
+insert into public.rubric_level (criterion_id, position, name, description, points)
+values ($1, $2, $3, $4, $5)
+`
+)
+
+func (r *RubricsRepo) Create(
+	ctx context.Context,
+	opts repo.RubricsRepoCreateOpts,
+) (models.Rubric, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Rubric{}, fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int64
+	if err = tx.GetContext(ctx, &id, rubricsRepoCreateQuery, opts.Name, opts.Description, opts.CreatedBy); err != nil {
+		return models.Rubric{}, fmt.Errorf("tx.GetContext: %w", err)
+	}
+	if err = r.createCriteria(ctx, tx, id, opts.Criteria); err != nil {
+		return models.Rubric{}, fmt.Errorf("r.createCriteria: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Rubric{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+	return r.GetById(ctx, id)
+}
+
+func (r *RubricsRepo) Update(
+	ctx context.Context,
+	opts repo.RubricsRepoUpdateOpts,
+) (models.Rubric, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Rubric{}, fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, rubricsRepoUpdateQuery, opts.Id, opts.Name, opts.Description)
+	if err != nil {
+		return models.Rubric{}, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return models.Rubric{}, fmt.Errorf("res.RowsAffected: %w", err)
+	} else if affected == 0 {
+		return models.Rubric{}, repo.ErrNotFound
+	}
+
+	if _, err = tx.ExecContext(ctx, rubricsRepoDeleteCriteriaQuery, opts.Id); err != nil {
+		return models.Rubric{}, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	if err = r.createCriteria(ctx, tx, opts.Id, opts.Criteria); err != nil {
+		return models.Rubric{}, fmt.Errorf("r.createCriteria: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Rubric{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+	return r.GetById(ctx, opts.Id)
+}
+
+func (r *RubricsRepo) createCriteria(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	rubricId int64,
+	criteria []models.RubricCriterion,
+) error {
+	for i, criterion := range criteria {
+		var criterionId int64
+		if err := tx.GetContext(
+			ctx, &criterionId, rubricsRepoCreateCriterionQuery,
+			rubricId, i, criterion.Name, criterion.Description,
+		); err != nil {
+			return fmt.Errorf("tx.GetContext: %w", err)
+		}
+		for j, level := range criterion.Levels {
+			if _, err := tx.ExecContext(
+				ctx, rubricsRepoCreateLevelQuery,
+				criterionId, j, level.Name, level.Description, level.Points,
+			); err != nil {
+				return fmt.Errorf("tx.ExecContext: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+const rubricsRepoDeleteQuery = `
+delete from public.rubric where id = $1
+`
+
+func (r *RubricsRepo) Delete(
+	ctx context.Context,
+	id int64,
+) error {
+	if _, err := r.db.ExecContext(ctx, rubricsRepoDeleteQuery, id); err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// rubricsRepoIsAttachedQuery looks for tasks graded with the rubric and for
+// tasks peer reviewed with it.
+const rubricsRepoIsAttachedQuery = `
+select exists (select 1 from public.task t where t.rubric_id = $1)
+    or exists (select 1 from public.task_peer_review tpr where tpr.rubric_id = $1)
+`
+
+func (r *RubricsRepo) IsAttached(
+	ctx context.Context,
+	id int64,
+) (bool, error) {
+	var ok bool
+	if err := r.db.GetContext(ctx, &ok, rubricsRepoIsAttachedQuery, id); err != nil {
+		return false, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return ok, nil
+}
+
+const rubricsRepoGetMinCostQuery = `
+select min(t.cost)
+from public.task t
+where (t.rubric_id = $1
+   or t.id in (select tpr.task_id from public.task_peer_review tpr where tpr.rubric_id = $1))
+  and t.deleted_at is null
+`
+
+// GetMinCost returns the lowest cost of the tasks the rubric is attached to,
+// for grading or for peer review, nil when it is not attached to any.
+func (r *RubricsRepo) GetMinCost(
+	ctx context.Context,
+	id int64,
+) (*int64, error) {
+	var cost *int64
+	if err := r.db.GetContext(ctx, &cost, rubricsRepoGetMinCostQuery, id); err != nil {
+		return nil, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return cost, nil
+}
+
+// rubricsRepoIsGradedQuery looks for marks and peer reviews that refer to
+// the criteria of the rubric.
+const rubricsRepoIsGradedQuery = `
+select exists (
+    select 1
+    from public.mark_criterion mc
+    join public.rubric_criterion rc on rc.id = mc.criterion_id
+    where rc.rubric_id = $1
+) or exists (
+    select 1
+    from public.peer_review_criterion prc
+    join public.rubric_criterion rc on rc.id = prc.criterion_id
+    where rc.rubric_id = $1
+)
+`
+
+func (r *RubricsRepo) IsGraded(
+	ctx context.Context,
+	id int64,
+) (bool, error) {
+	var ok bool
+	if err := r.db.GetContext(ctx, &ok, rubricsRepoIsGradedQuery, id); err != nil {
+		return false, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return ok, nil
+}
@@ -10,9 +10,12 @@ import (
 )
 
 type statistics struct {
-	UserName  string `db:"user_name"`
-	GroupName string `db:"group_name"`
-	Score     int64  `db:"score"`
+	UserName   string  `db:"user_name"`
+	GroupName  string  `db:"group_name"`
+	UnitName   string  `db:"unit_name"`
+	Score      int64   `db:"score"`
+	MaxScore   int64   `db:"max_score"`
+	Percentage float64 `db:"percentage"`
 }
 
 type StatisticsRepo struct {
@@ -24,44 +27,101 @@ func NewStatisticsRepo(db *sqlx.DB) *StatisticsRepo {
 }
 
 const statisticsRepoGetStatisticsQuery = `
-select 
-	u.last_name || ' ' || u.first_name || ' ' || coalesce(u.middle_name, '') user_name,
-	g.name group_name,
-	sum(m.mark) score
-from public.mark m
-left join public.answer a on a.id = m.answer_id
-left join public."user" u on a.user_id = u.id
-left join public."group" g on g.id = u.group_id 
-where coalesce(m.updated_at, m.created_at) > $1 and coalesce(m.updated_at, m.created_at) < $2
-group by u.last_name, u.middle_name, u.first_name, g.name
-order by score desc 
-limit $3
-offset $4
+with recursive unit_path as (
+    select g.id group_id, ou.id unit_id, ou.parent_id, ou.kind, ou.name
+    from public."group" g
+    join public.org_unit ou on ou.id = g.org_unit_id
+    union all
+    select up.group_id, ou.id, ou.parent_id, ou.kind, ou.name
+    from unit_path up
+    join public.org_unit ou on ou.id = up.parent_id
+), scores as (
+    select 
+        u.id user_id,
+        u.last_name || ' ' || u.first_name || ' ' || coalesce(u.middle_name, '') user_name,
+        g.id group_id,
+        coalesce(g.name, '') group_name,
+        public.member_mark(m.mark, a.team_id, u.id) mark,
+        t.cost
+    from public.mark m
+    left join public.answer a on a.id = m.answer_id
+    left join public.task t on t.id = a.task_id
+    left join lateral (
+        select a.user_id
+        union
+        select tm.user_id from public.team_member tm where tm.team_id = a.team_id
+    ) r on true
+    left join public."user" u on r.user_id = u.id
+    left join public.group_membership gm on gm.user_id = u.id 
+        and a.created_at >= gm.effective_from 
+        and (gm.effective_till is null or a.created_at < gm.effective_till)
+    left join public."group" g on g.id = coalesce(gm.group_id, u.group_id) 
+    where coalesce(m.updated_at, m.created_at) > $1 and coalesce(m.updated_at, m.created_at) < $2
+      and a.deleted_at is null
+      and t.deleted_at is null
+      and ($3::bigint is null or public.sees_student($3, u.id, a.created_at))
+)
+select %s, %s
+from scores s
+%s
+group by %s
+order by %s desc 
+limit $4
+offset $5
 `
 
+const (
+	statisticsRepoUserColumns  = `s.user_name, s.group_name, '' unit_name`
+	statisticsRepoGroupColumns = `'' user_name, s.group_name, '' unit_name`
+	statisticsRepoUnitColumns  = `'' user_name, '' group_name, up.name unit_name`
+	statisticsRepoScoreColumns = `sum(s.mark) score,
+    sum(s.cost) max_score,
+    coalesce(100.0 * sum(s.mark) / nullif(sum(s.cost), 0), 0)::float8 percentage`
+	statisticsRepoUnitJoin = `join unit_path up on up.group_id = s.group_id and up.kind = $6`
+)
+
 func (r *StatisticsRepo) GetStatistics(
 	ctx context.Context,
 	opts repo.GetStatisticsOpts,
 ) ([]models.Statistics, error) {
-	var s []statistics
-	if err := r.db.SelectContext(
-		ctx,
-		&s,
-		statisticsRepoGetStatisticsQuery,
+	args := []any{
 		opts.From,
 		opts.To,
+		opts.ScopeUserId,
 		opts.Limit,
 		opts.Offset,
-	); err != nil {
+	}
+
+	orderBy := "score"
+	if opts.Normalize {
+		orderBy = "percentage"
+	}
+
+	var query string
+	switch opts.Level {
+	case models.StatisticsLevelUser:
+		query = fmt.Sprintf(statisticsRepoGetStatisticsQuery, statisticsRepoUserColumns, statisticsRepoScoreColumns, "", "s.user_id, s.user_name, s.group_name", orderBy)
+	case models.StatisticsLevelGroup:
+		query = fmt.Sprintf(statisticsRepoGetStatisticsQuery, statisticsRepoGroupColumns, statisticsRepoScoreColumns, "", "s.group_id, s.group_name", orderBy)
+	default:
+		query = fmt.Sprintf(statisticsRepoGetStatisticsQuery, statisticsRepoUnitColumns, statisticsRepoScoreColumns, statisticsRepoUnitJoin, "up.unit_id, up.name", orderBy)
+		args = append(args, opts.Level)
+	}
+
+	var s []statistics
+	if err := r.db.SelectContext(ctx, &s, query, args...); err != nil {
 		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
 	}
 	return lo.Map(
 		s,
 		func(item statistics, _ int) models.Statistics {
 			return models.Statistics{
-				UserName:  item.UserName,
-				GroupName: item.GroupName,
-				Score:     item.Score,
+				UserName:   item.UserName,
+				GroupName:  item.GroupName,
+				UnitName:   item.UnitName,
+				Score:      item.Score,
+				MaxScore:   item.MaxScore,
+				Percentage: item.Percentage,
 			}
 		},
 	), nil
@@ -4,6 +4,7 @@ import (
 	"backend/internal/models"
 	"backend/internal/repo"
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"time"
@@ -13,26 +14,48 @@ import (
 )
 
 type task struct {
-	Id            int64      `db:"id"`
-	Title         string     `db:"title"`
-	Text          *string    `db:"text"`
-	CreatedBy     int64      `db:"created_by"`
-	CreatedAt     time.Time  `db:"created_at"`
-	UpdatedAt     *time.Time `db:"updated_at"`
-	EffectiveFrom time.Time  `db:"effective_from"`
-	EffectiveTill time.Time  `db:"effective_till"`
+	Id             int64      `db:"id"`
+	Title          string     `db:"title"`
+	Text           *string    `db:"text"`
+	CreatedBy      int64      `db:"created_by"`
+	CreatedAt      time.Time  `db:"created_at"`
+	UpdatedAt      *time.Time `db:"updated_at"`
+	EffectiveFrom  time.Time  `db:"effective_from"`
+	EffectiveTill  time.Time  `db:"effective_till"`
+	LatePolicy     string     `db:"late_policy"`
+	LatePenalty    int64      `db:"late_penalty"`
+	LateGraceHours int64      `db:"late_grace_hours"`
+	Cost           int64      `db:"cost"`
+	RubricId       *int64     `db:"rubric_id"`
+	IsTemplate     bool       `db:"is_template"`
+	SeriesId       *int64     `db:"series_id"`
+	SeriesIndex    int64      `db:"series_index"`
+	State          string     `db:"state"`
+	PublishAt      *time.Time `db:"publish_at"`
+	PublishedAt    *time.Time `db:"published_at"`
 }
 
 func (t task) toServiceModel() models.Task {
 	return models.Task{
-		Id:            t.Id,
-		Title:         t.Title,
-		Text:          t.Text,
-		CreatedBy:     t.CreatedBy,
-		CreatedAt:     t.CreatedAt,
-		UpdatedAt:     t.UpdatedAt,
-		EffectiveFrom: t.EffectiveFrom,
-		EffectiveTill: t.EffectiveTill,
+		Id:             t.Id,
+		Title:          t.Title,
+		Text:           t.Text,
+		CreatedBy:      t.CreatedBy,
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
+		EffectiveFrom:  t.EffectiveFrom,
+		EffectiveTill:  t.EffectiveTill,
+		LatePolicy:     t.LatePolicy,
+		LatePenalty:    t.LatePenalty,
+		LateGraceHours: t.LateGraceHours,
+		Cost:           t.Cost,
+		RubricId:       t.RubricId,
+		IsTemplate:     t.IsTemplate,
+		SeriesId:       t.SeriesId,
+		SeriesIndex:    t.SeriesIndex,
+		State:          t.State,
+		PublishAt:      t.PublishAt,
+		PublishedAt:    t.PublishedAt,
 	}
 }
 
@@ -52,10 +75,22 @@ select
     t.created_by, 
     t.effective_from, 
     t.effective_till, 
+    t.late_policy, 
+    t.late_penalty, 
+    t.late_grace_hours, 
+    t.cost, 
+    t.rubric_id, 
+    t.is_template, 
+    t.series_id, 
+    t.series_index, 
+    t.state, 
+    t.publish_at, 
+    t.published_at, 
     t.created_at, 
     t.updated_at
 from public.task t
 where t.id = $1
+  and t.deleted_at is null
 `
 
 func (r *TasksRepo) GetById(
@@ -64,11 +99,88 @@ func (r *TasksRepo) GetById(
 ) (models.Task, error) {
 	var t task
 	if err := r.db.GetContext(ctx, &t, tasksRepoGetByIdQuery, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Task{}, repo.ErrNotFound
+		}
 		return models.Task{}, fmt.Errorf("r.db.GetContext: %w", err)
 	}
 	return t.toServiceModel(), nil
 }
 
+const tasksRepoGetByIdForUserQuery = `
+select 
+    t.id,
+    t.title, 
+    t.text, 
+    t.created_by, 
+    w.effective_from, 
+    w.effective_till, 
+    t.late_policy, 
+    t.late_penalty, 
+    t.late_grace_hours, 
+    t.cost, 
+    t.rubric_id, 
+    t.is_template, 
+    t.series_id, 
+    t.series_index, 
+    t.state, 
+    t.publish_at, 
+    t.published_at, 
+    t.created_at, 
+    t.updated_at
+from public.task t
+cross join public.task_window(t.id, $2) w
+where t.id = $1
+  and t.deleted_at is null
+`
+
+// GetByIdForUser returns the task with the dates that apply to the user.
+func (r *TasksRepo) GetByIdForUser(
+	ctx context.Context,
+	id, userId int64,
+) (models.Task, error) {
+	var t task
+	if err := r.db.GetContext(ctx, &t, tasksRepoGetByIdForUserQuery, id, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Task{}, repo.ErrNotFound
+		}
+		return models.Task{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return t.toServiceModel(), nil
+}
+
+var tasksRepoFilterFields = map[string]filterField{
+	"title":         {column: "t.title", ops: textOps, parse: parseString},
+	"createdBy":     {column: "t.created_by", parse: parseInt64},
+	"effectiveFrom": {column: "t.effective_from", ops: orderedOps, parse: parseTime},
+	"deadline":      {column: "t.effective_till", ops: orderedOps, parse: parseTime},
+	"cost":          {column: "t.cost", ops: orderedOps, parse: parseInt64},
+	"rubricId":      {column: "t.rubric_id", parse: parseInt64},
+	"seriesId":      {column: "t.series_id", parse: parseInt64},
+	"latePolicy":    {column: "t.late_policy", parse: parseString},
+	"state": {column: "t.state", parse: parseOneOf(
+		models.TaskStateDraft,
+		models.TaskStateScheduled,
+		models.TaskStatePublished,
+	)},
+}
+
+var tasksRepoSortFields = map[string]string{
+	"id":            "t.id",
+	"title":         "t.title",
+	"effectiveFrom": "t.effective_from",
+	"deadline":      "t.effective_till",
+	"cost":          "t.cost",
+	"createdAt":     "t.created_at",
+}
+
+var tasksRepoFilterSpec = filterSpec{
+	fields:       tasksRepoFilterFields,
+	sortFields:   tasksRepoSortFields,
+	searchColumn: "t.title",
+	defaultOrder: "t.id desc",
+}
+
 const tasksRepoGetListForCreatorQuery = `
 select 
     t.id, 
@@ -77,21 +189,41 @@ select
     t.text, 
     t.effective_from, 
     t.effective_till, 
+    t.late_policy, 
+    t.late_penalty, 
+    t.late_grace_hours, 
+    t.cost, 
+    t.rubric_id, 
+    t.is_template, 
+    t.series_id, 
+    t.series_index, 
+    t.state, 
+    t.publish_at, 
+    t.published_at, 
     t.created_at, 
     t.updated_at
 from public.task t
 where t.created_by = $1
-order by id desc 
-limit $2
-offset $3
+  and t.is_template = $2
+  and t.deleted_at is null %s
+order by %s
+limit $%d
+offset $%d
 `
 
 func (r *TasksRepo) GetListForCreator(
 	ctx context.Context,
 	opts repo.TasksRepoGetListForCreatorOpts,
 ) ([]models.Task, error) {
+	where, orderBy, args, err := tasksRepoFilterSpec.build(opts.Filter, []any{opts.CreatedBy, opts.IsTemplate})
+	if err != nil {
+		return nil, fmt.Errorf("tasksRepoFilterSpec.build: %w", err)
+	}
+	args = append(args, opts.Limit, opts.Offset)
+	query := fmt.Sprintf(tasksRepoGetListForCreatorQuery, where, orderBy, len(args)-1, len(args))
+
 	var tasks []task
-	if err := r.db.SelectContext(ctx, &tasks, tasksRepoGetListForCreatorQuery, opts.CreatedBy, opts.Limit, opts.Offset); err != nil {
+	if err = r.db.SelectContext(ctx, &tasks, query, args...); err != nil {
 		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
 	}
 	return lo.Map(
@@ -106,20 +238,263 @@ const tasksRepoGetCountForCreatorQuery = `
 select count(*)
 from public.task t
 where t.created_by = $1
+  and t.is_template = $2
+  and t.deleted_at is null %s
 `
 
 func (r *TasksRepo) GetCountForCreator(
 	ctx context.Context,
-	createdBy int64,
+	opts repo.TasksRepoGetListForCreatorOpts,
 ) (int64, error) {
+	where, _, args, err := tasksRepoFilterSpec.build(opts.Filter, []any{opts.CreatedBy, opts.IsTemplate})
+	if err != nil {
+		return 0, fmt.Errorf("tasksRepoFilterSpec.build: %w", err)
+	}
+
 	var count int64
-	if err := r.db.GetContext(ctx, &count, tasksRepoGetCountForCreatorQuery, createdBy); err != nil {
-		return 0, fmt.Errorf("r.db.GetContext: :%w", err)
+	if err = r.db.GetContext(ctx, &count, fmt.Sprintf(tasksRepoGetCountForCreatorQuery, where), args...); err != nil {
+		return 0, fmt.Errorf("r.db.GetContext: %w", err)
 	}
 	return count, nil
 }
 
+const tasksRepoIsVisibleQuery = `
+select t.created_by = $2 or exists (
+    select 1
+    from public.task_links tl
+    where tl.task_id = t.id
+      and (tl.group_id in (select public.visible_groups($2))
+       or tl.user_id is not null and public.sees_student($2, tl.user_id, now()))
+)
+from public.task t
+where t.id = $1
+  and t.deleted_at is null
+`
+
+// IsVisible tells whether the staff member created the task or sees any of
+// the groups and students it is assigned to.
+func (r *TasksRepo) IsVisible(
+	ctx context.Context,
+	id int64,
+	scopeUserId int64,
+) (bool, error) {
+	var visible bool
+	if err := r.db.GetContext(ctx, &visible, tasksRepoIsVisibleQuery, id, scopeUserId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, repo.ErrNotFound
+		}
+		return false, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return visible, nil
+}
+
+const tasksRepoIsAssignedQuery = `
+select exists (
+    select 1
+    from public.task_links tl
+    join public."user" u on u.id = $2
+    left join public."group" g on g.id = tl.group_id
+    where tl.task_id = t.id
+      and (tl.user_id = u.id or tl.group_id = u.group_id and g.deleted_at is null)
+)
+from public.task t
+where t.id = $1
+  and t.deleted_at is null
+`
+
+// IsAssigned tells whether the task is assigned to the student directly or
+// through the student's current group.
+func (r *TasksRepo) IsAssigned(
+	ctx context.Context,
+	id int64,
+	userId int64,
+) (bool, error) {
+	var assigned bool
+	if err := r.db.GetContext(ctx, &assigned, tasksRepoIsAssignedQuery, id, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, repo.ErrNotFound
+		}
+		return false, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return assigned, nil
+}
+
+const tasksRepoGetMaxMarkQuery = `
+select coalesce(max(m.raw_mark), 0)
+from public.mark m
+join public.answer a on a.id = m.answer_id
+where a.task_id = $1
+  and a.deleted_at is null
+`
+
+// GetMaxMark returns the highest raw mark given for the task, 0 when there are none.
+func (r *TasksRepo) GetMaxMark(
+	ctx context.Context,
+	id int64,
+) (int64, error) {
+	var mark int64
+	if err := r.db.GetContext(ctx, &mark, tasksRepoGetMaxMarkQuery, id); err != nil {
+		return 0, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return mark, nil
+}
+
+type userTask struct {
+	task
+	Status   string `db:"status"`
+	AnswerId *int64 `db:"answer_id"`
+	Mark     *int64 `db:"mark"`
+}
+
+func (t userTask) toServiceModel() models.UserTask {
+	return models.UserTask{
+		Task:     t.task.toServiceModel(),
+		Status:   t.Status,
+		AnswerId: t.AnswerId,
+		Mark:     t.Mark,
+	}
+}
+
+var tasksRepoUserFilterSpec = filterSpec{
+	fields: lo.Assign(tasksRepoFilterFields, map[string]filterField{
+		"status": {column: "t.status", parse: parseOneOf(
+			models.TaskStatusNotStarted,
+			models.TaskStatusSubmitted,
+			models.TaskStatusLate,
+			models.TaskStatusGraded,
+			models.TaskStatusReturned,
+		)},
+	}),
+	sortFields: lo.Assign(tasksRepoSortFields, map[string]string{
+		"status": `array_position(array ['not_started', 'late', 'submitted', 'returned', 'graded'], t.status)`,
+	}),
+	searchColumn: "t.title",
+	defaultOrder: "t.id desc",
+}
+
+// tasksRepoUserTasksQuery selects the tasks visible to the student $1 of the
+// group $2 together with the student's status on each of them. Dates are the
+// ones that apply to the student after link overrides. Links are checked with
+// exists so that a task linked both to the student and to the group is
+// returned once. On team tasks the answer of the student's team counts, with
+// the student's own mark adjustment.
+const tasksRepoUserTasksQuery = `
+select 
+    t.id, 
+    t.created_by, 
+    t.title, 
+    t.text, 
+    w.effective_from, 
+    w.effective_till, 
+    t.late_policy, 
+    t.late_penalty, 
+    t.late_grace_hours, 
+    t.cost, 
+    t.rubric_id, 
+    t.is_template, 
+    t.series_id, 
+    t.series_index, 
+    t.state, 
+    t.publish_at, 
+    t.published_at, 
+    t.created_at, 
+    t.updated_at,
+    a.id as answer_id,
+    m.mark,
+    case
+        when a.id is null then 'not_started'
+        when m.id is null and a.is_late then 'late'
+        when m.id is null then 'submitted'
+        when coalesce(a.updated_at, a.created_at) > coalesce(m.updated_at, m.created_at) then 'returned'
+        else 'graded'
+    end as status
+from public.task t
+cross join public.task_window(t.id, $1) w
+left join lateral (
+    select a.id, a.team_id, a.is_late, a.created_at, a.updated_at
+    from public.answer a
+    where a.task_id = t.id
+      and (a.user_id = $1 or a.team_id = public.team_of(t.id, $1))
+      and a.deleted_at is null
+    order by a.id desc
+    limit 1
+) a on true
+left join lateral (
+    select m.id, public.member_mark(m.mark, a.team_id, $1) as mark, m.created_at, m.updated_at
+    from public.mark m
+    where m.answer_id = a.id
+    order by m.id desc
+    limit 1
+) m on true
+where t.state = 'published'
+  and t.deleted_at is null
+  and w.effective_from <= now()
+  and (a.id is not null
+   or exists (
+       select 1
+       from public.task_links tl
+       where tl.task_id = t.id
+         and (tl.user_id = $1 or tl.group_id = $2 and not exists (
+             select 1 from public."group" g where g.id = $2 and g.deleted_at is not null
+         ))
+   ))
+`
+
 const tasksRepoGetListForUserQuery = `
+select *
+from (` + tasksRepoUserTasksQuery + `) t
+where true %s
+order by %s
+limit $%d
+offset $%d
+`
+
+func (r *TasksRepo) GetListForUser(
+	ctx context.Context,
+	opts repo.TasksRepoGetListForUserOpts,
+) ([]models.UserTask, error) {
+	where, orderBy, args, err := tasksRepoUserFilterSpec.build(opts.Filter, []any{opts.UserId, opts.GroupId})
+	if err != nil {
+		return nil, fmt.Errorf("tasksRepoUserFilterSpec.build: %w", err)
+	}
+	args = append(args, opts.Limit, opts.Offset)
+	query := fmt.Sprintf(tasksRepoGetListForUserQuery, where, orderBy, len(args)-1, len(args))
+
+	var tasks []userTask
+	if err = r.db.SelectContext(ctx, &tasks, query, args...); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return lo.Map(
+		tasks,
+		func(item userTask, _ int) models.UserTask {
+			return item.toServiceModel()
+		},
+	), nil
+}
+
+const tasksRepoGetCountForUserQuery = `
+select count(*)
+from (` + tasksRepoUserTasksQuery + `) t
+where true %s
+`
+
+func (r *TasksRepo) GetCountForUser(
+	ctx context.Context,
+	opts repo.TasksRepoGetListForUserOpts,
+) (int64, error) {
+	where, _, args, err := tasksRepoUserFilterSpec.build(opts.Filter, []any{opts.UserId, opts.GroupId})
+	if err != nil {
+		return 0, fmt.Errorf("tasksRepoUserFilterSpec.build: %w", err)
+	}
+
+	var count int64
+	if err = r.db.GetContext(ctx, &count, fmt.Sprintf(tasksRepoGetCountForUserQuery, where), args...); err != nil {
+		return 0, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return count, nil
+}
+
+const tasksRepoGetListForScopeQuery = `
 select 
     t.id, 
     t.created_by, 
@@ -127,28 +502,46 @@ select
     t.text, 
     t.effective_from, 
     t.effective_till, 
+    t.late_policy, 
+    t.late_penalty, 
+    t.late_grace_hours, 
+    t.cost, 
+    t.rubric_id, 
+    t.is_template, 
+    t.series_id, 
+    t.series_index, 
+    t.state, 
+    t.publish_at, 
+    t.published_at, 
     t.created_at, 
     t.updated_at
 from public.task t
-left join public.task_links tl on t.id = tl.task_id
-where tl.user_id = $1 or tl.group_id = $2
-order by id desc 
-limit $3
-offset $4
+where t.deleted_at is null
+  and exists (
+    select 1
+    from public.task_links tl
+    left join public.user u on u.id = tl.user_id
+    where tl.task_id = t.id
+      and coalesce(tl.group_id, u.group_id) in (select public.visible_groups($1))
+) %s
+order by %s
+limit $%d
+offset $%d
 `
 
-func (r *TasksRepo) GetListForUser(
+func (r *TasksRepo) GetListForScope(
 	ctx context.Context,
-	opts repo.TasksRepoGetListForUserOpts,
+	opts repo.TasksRepoGetListForScopeOpts,
 ) ([]models.Task, error) {
+	where, orderBy, args, err := tasksRepoFilterSpec.build(opts.Filter, []any{opts.ScopeUserId})
+	if err != nil {
+		return nil, fmt.Errorf("tasksRepoFilterSpec.build: %w", err)
+	}
+	args = append(args, opts.Limit, opts.Offset)
+	query := fmt.Sprintf(tasksRepoGetListForScopeQuery, where, orderBy, len(args)-1, len(args))
+
 	var tasks []task
-	if err := r.db.SelectContext(
-		ctx, &tasks, tasksRepoGetListForUserQuery,
-		opts.UserId,
-		opts.GroupId,
-		opts.Limit,
-		opts.Offset,
-	); err != nil {
+	if err = r.db.SelectContext(ctx, &tasks, query, args...); err != nil {
 		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
 	}
 	return lo.Map(
@@ -159,113 +552,362 @@ func (r *TasksRepo) GetListForUser(
 	), nil
 }
 
-const tasksRepoGetCountForUserQuery = `
+const tasksRepoGetCountForScopeQuery = `
 select count(*)
 from public.task t
-left join public.task_links tl on t.id = tl.task_id
-where tl.user_id = $1 or tl.group_id = $2
+where t.deleted_at is null
+  and exists (
+    select 1
+    from public.task_links tl
+    left join public.user u on u.id = tl.user_id
+    where tl.task_id = t.id
+      and coalesce(tl.group_id, u.group_id) in (select public.visible_groups($1))
+) %s
 `
 
-func (r *TasksRepo) GetCountForUser(
+func (r *TasksRepo) GetCountForScope(
 	ctx context.Context,
-	userId, groupId int64,
+	opts repo.TasksRepoGetListForScopeOpts,
 ) (int64, error) {
+	where, _, args, err := tasksRepoFilterSpec.build(opts.Filter, []any{opts.ScopeUserId})
+	if err != nil {
+		return 0, fmt.Errorf("tasksRepoFilterSpec.build: %w", err)
+	}
+
 	var count int64
-	if err := r.db.GetContext(ctx, &count, tasksRepoGetCountForUserQuery, userId, groupId); err != nil {
-		return 0, fmt.Errorf("r.db.GetContext: :%w", err)
+	if err = r.db.GetContext(ctx, &count, fmt.Sprintf(tasksRepoGetCountForScopeQuery, where), args...); err != nil {
+		return 0, fmt.Errorf("r.db.GetContext: %w", err)
 	}
 	return count, nil
 }
 
 const tasksRepoCreateQuery = `
-insert into public.task (created_by, title, text, effective_from, effective_till) 
-values (:created_by, :title, :text, :effective_from, :effective_till)
-returning id, created_at, title, text, effective_from, effective_till, created_at, updated_at
+insert into public.task (created_by, title, text, effective_from, effective_till, late_policy, late_penalty, late_grace_hours, cost, rubric_id, is_template, series_id, series_index, state, publish_at, published_at) 
+values (:created_by, :title, :text, :effective_from, :effective_till, :late_policy, :late_penalty, :late_grace_hours, :cost, :rubric_id, :is_template, :series_id, :series_index, :state, :publish_at, :published_at)
+returning id, created_by, title, text, effective_from, effective_till, late_policy, late_penalty, late_grace_hours, cost, rubric_id, is_template, series_id, series_index, state, publish_at, published_at, created_at, updated_at
+`
+
+const tasksRepoCreateFileQuery = `
+insert into public.file (name, filename, filepath, task_id, created_by)
+values ($1, $2, $3, $4, $5)
 `
 
+// Create inserts the task together with its assignees, the loose files of the
+// creator listed in FileIds and the rows of files copied for it, all or nothing.
 func (r *TasksRepo) Create(
 	ctx context.Context,
 	opts repo.TasksRepoCreateOpts,
 ) (models.Task, error) {
-	rows, err := r.db.NamedQueryContext(ctx, tasksRepoCreateQuery, struct {
-		CreatedBy     int64     `db:"created_by"`
-		Title         string    `db:"title"`
-		Text          *string   `db:"text"`
-		EffectiveFrom time.Time `db:"effective_from"`
-		EffectiveTill time.Time `db:"effective_till"`
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	query, args, err := tx.BindNamed(tasksRepoCreateQuery, struct {
+		CreatedBy      int64      `db:"created_by"`
+		Title          string     `db:"title"`
+		Text           *string    `db:"text"`
+		EffectiveFrom  time.Time  `db:"effective_from"`
+		EffectiveTill  time.Time  `db:"effective_till"`
+		LatePolicy     string     `db:"late_policy"`
+		LatePenalty    int64      `db:"late_penalty"`
+		LateGraceHours int64      `db:"late_grace_hours"`
+		Cost           int64      `db:"cost"`
+		RubricId       *int64     `db:"rubric_id"`
+		IsTemplate     bool       `db:"is_template"`
+		SeriesId       *int64     `db:"series_id"`
+		SeriesIndex    int64      `db:"series_index"`
+		State          string     `db:"state"`
+		PublishAt      *time.Time `db:"publish_at"`
+		PublishedAt    *time.Time `db:"published_at"`
 	}{
-		CreatedBy:     opts.CreatedBy,
-		Title:         opts.Title,
-		Text:          opts.Text,
-		EffectiveFrom: opts.EffectiveFrom,
-		EffectiveTill: opts.EffectiveTill,
+		CreatedBy:      opts.CreatedBy,
+		Title:          opts.Title,
+		Text:           opts.Text,
+		EffectiveFrom:  opts.EffectiveFrom,
+		EffectiveTill:  opts.EffectiveTill,
+		LatePolicy:     opts.LatePolicy,
+		LatePenalty:    opts.LatePenalty,
+		LateGraceHours: opts.LateGraceHours,
+		Cost:           opts.Cost,
+		RubricId:       opts.RubricId,
+		IsTemplate:     opts.IsTemplate,
+		SeriesId:       opts.SeriesId,
+		SeriesIndex:    opts.SeriesIndex,
+		State:          opts.State,
+		PublishAt:      opts.PublishAt,
+		PublishedAt:    opts.PublishedAt,
 	})
 	if err != nil {
-		return models.Task{}, fmt.Errorf("r.db.NamedQueryContext: %w", err)
+		return models.Task{}, fmt.Errorf("tx.BindNamed: %w", err)
 	}
 
-	defer rows.Close()
-
-	if !rows.Next() {
-		return models.Task{}, errors.New("no rows returned")
-	}
 	var t task
-	if err = rows.StructScan(&t); err != nil {
-		return models.Task{}, fmt.Errorf("rows.StructScan: %w", err)
+	if err = tx.GetContext(ctx, &t, query, args...); err != nil {
+		return models.Task{}, fmt.Errorf("tx.GetContext: %w", err)
+	}
+
+	for _, q := range []struct {
+		query string
+		args  []any
+	}{
+		{query: tasksRepoCreateUserLinksQuery, args: []any{t.Id, lo.Uniq(opts.UserIds)}},
+		{query: tasksRepoCreateGroupLinksQuery, args: []any{t.Id, lo.Uniq(opts.GroupIds)}},
+		{query: tasksRepoAttachFilesQuery, args: []any{t.Id, lo.Uniq(opts.FileIds), opts.CreatedBy}},
+	} {
+		if _, err = tx.ExecContext(ctx, q.query, q.args...); err != nil {
+			return models.Task{}, fmt.Errorf("tx.ExecContext: %w", err)
+		}
 	}
-	if rows.Err() != nil {
-		return models.Task{}, fmt.Errorf("rows.Err: %w", err)
+	for _, f := range opts.CopiedFiles {
+		if _, err = tx.ExecContext(ctx, tasksRepoCreateFileQuery, f.Name, f.Filename, f.Filepath, t.Id, opts.CreatedBy); err != nil {
+			return models.Task{}, fmt.Errorf("tx.ExecContext: %w", err)
+		}
 	}
 
+	if err = tx.Commit(); err != nil {
+		return models.Task{}, fmt.Errorf("tx.Commit: %w", err)
+	}
 	return t.toServiceModel(), nil
 }
 
-const tasksRepoUpdateQuery = `
+const (
+	tasksRepoUpdateQuery = `
 update public.task
 set (
      title, 
      text, 
      effective_from, 
-     effective_till
+     effective_till,
+     late_policy,
+     late_penalty,
+     late_grace_hours,
+     cost,
+     rubric_id,
+     updated_at
+    ) = (
+     $2, 
+     $3, 
+     $4, 
+     $5,
+     $6,
+     $7,
+     $8,
+     $9,
+     $10,
+     now()
+    )
+where id = $1
+  and deleted_at is null
+returning id, created_by, title, text, effective_from, effective_till, late_policy, late_penalty, late_grace_hours, cost, rubric_id, is_template, series_id, series_index, state, publish_at, published_at, created_at, updated_at
+`
+	tasksRepoDeleteStaleUserLinksQuery = `
+delete from public.task_links
+where task_id = $1
+  and user_id is not null
+  and user_id <> all ($2::bigint[])
+`
+	tasksRepoDeleteStaleGroupLinksQuery = `
+delete from public.task_links
+where task_id = $1
+  and group_id is not null
+  and group_id <> all ($2::bigint[])
+`
+	tasksRepoCreateUserLinksQuery = `
+insert into public.task_links (task_id, user_id)
+select $1, uid
+from unnest($2::bigint[]) uid
+on conflict do nothing
+`
+	tasksRepoCreateGroupLinksQuery = `
+insert into public.task_links (task_id, group_id)
+select $1, gid
+from unnest($2::bigint[]) gid
+on conflict do nothing
+`
+	tasksRepoDeleteStaleFilesQuery = `
+update public.file
+set deleted_at = now()
+where task_id = $1
+  and deleted_at is null
+  and id <> all ($2::bigint[])
+`
+	tasksRepoAttachFilesQuery = `
+update public.file
+set task_id = $1
+where id = any ($2::bigint[])
+  and deleted_at is null
+  and (task_id = $1
+   or created_by = $3 and task_id is null and answer_id is null and comment_id is null)
+`
+	tasksRepoUpdateSeriesQuery = `
+update public.task
+set (
+     title,
+     text,
+     late_policy,
+     late_penalty,
+     late_grace_hours,
+     cost,
+     rubric_id,
+     updated_at
     ) = (
-     :title, 
-     :text, 
-     :effective_from, 
-     :effective_till
+     $3,
+     $4,
+     $5,
+     $6,
+     $7,
+     $8,
+     $9,
+     now()
     )
-where id = :id
+where series_id = $1
+  and id <> $2
+  and deleted_at is null
+  and (series_index = 0 or effective_from > now())
 `
+)
 
 func (r *TasksRepo) Update(
 	ctx context.Context,
 	opts repo.TasksRepoUpdateOpts,
 ) (models.Task, error) {
-	_, err := r.db.NamedExecContext(ctx, tasksRepoUpdateQuery, struct {
-		Id            int64     `db:"id"`
-		GroupId       int64     `db:"group_id"`
-		Title         string    `db:"title"`
-		Text          string    `db:"text"`
-		EffectiveFrom time.Time `db:"effective_from"`
-		EffectiveTill time.Time `db:"effective_till"`
-		Cost          int64     `db:"cost"`
-	}{
-		Id:            opts.Id,
-		GroupId:       opts.GroupId,
-		Title:         opts.Title,
-		Text:          opts.Text,
-		EffectiveFrom: opts.EffectiveFrom,
-		EffectiveTill: opts.EffectiveTill,
-		Cost:          opts.Cost,
-	})
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return models.Task{}, fmt.Errorf("r.db.NamedExecContext: %w", err)
+		return models.Task{}, fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var t task
+	if err = tx.GetContext(
+		ctx, &t, tasksRepoUpdateQuery,
+		opts.Id,
+		opts.Title,
+		opts.Text,
+		opts.EffectiveFrom,
+		opts.EffectiveTill,
+		opts.LatePolicy,
+		opts.LatePenalty,
+		opts.LateGraceHours,
+		opts.Cost,
+		opts.RubricId,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Task{}, repo.ErrNotFound
+		}
+		return models.Task{}, fmt.Errorf("tx.GetContext: %w", err)
+	}
+
+	userIds := lo.Uniq(opts.UserIds)
+	groupIds := lo.Uniq(opts.GroupIds)
+	fileIds := lo.Uniq(opts.FileIds)
+
+	for _, q := range []struct {
+		query string
+		ids   []int64
+	}{
+		{query: tasksRepoDeleteStaleUserLinksQuery, ids: userIds},
+		{query: tasksRepoDeleteStaleGroupLinksQuery, ids: groupIds},
+		{query: tasksRepoCreateUserLinksQuery, ids: userIds},
+		{query: tasksRepoCreateGroupLinksQuery, ids: groupIds},
+		{query: tasksRepoDeleteStaleFilesQuery, ids: fileIds},
+	} {
+		if _, err = tx.ExecContext(ctx, q.query, opts.Id, q.ids); err != nil {
+			return models.Task{}, fmt.Errorf("tx.ExecContext: %w", err)
+		}
 	}
+	// Only files already on the task and loose files of the editor can be attached.
+	if _, err = tx.ExecContext(ctx, tasksRepoAttachFilesQuery, opts.Id, fileIds, opts.UpdatedBy); err != nil {
+		return models.Task{}, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+
+	// The series source is updated too, so that instances generated later
+	// pick up the change.
+	if opts.ApplyToSeries && t.SeriesId != nil {
+		if _, err = tx.ExecContext(
+			ctx, tasksRepoUpdateSeriesQuery,
+			*t.SeriesId,
+			t.Id,
+			opts.Title,
+			opts.Text,
+			opts.LatePolicy,
+			opts.LatePenalty,
+			opts.LateGraceHours,
+			opts.Cost,
+			opts.RubricId,
+		); err != nil {
+			return models.Task{}, fmt.Errorf("tx.ExecContext: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Task{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+	return t.toServiceModel(), nil
+}
 
-	return models.Task{}, nil
+const tasksRepoSetStateQuery = `
+update public.task
+set (
+     state, 
+     publish_at, 
+     published_at, 
+     updated_at
+    ) = (
+     $2, 
+     $3, 
+     $4, 
+     now()
+    )
+where id = $1
+  and deleted_at is null
+returning id, created_by, title, text, effective_from, effective_till, late_policy, late_penalty, late_grace_hours, cost, rubric_id, is_template, series_id, series_index, state, publish_at, published_at, created_at, updated_at
+`
+
+func (r *TasksRepo) SetState(
+	ctx context.Context,
+	opts repo.TasksRepoSetStateOpts,
+) (models.Task, error) {
+	var t task
+	if err := r.db.GetContext(ctx, &t, tasksRepoSetStateQuery, opts.Id, opts.State, opts.PublishAt, opts.PublishedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Task{}, repo.ErrNotFound
+		}
+		return models.Task{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return t.toServiceModel(), nil
+}
+
+const tasksRepoPublishDueQuery = `
+update public.task
+set state = 'published', published_at = now(), updated_at = now()
+where state = 'scheduled'
+  and deleted_at is null
+  and publish_at <= $1
+returning id, created_by, title, text, effective_from, effective_till, late_policy, late_penalty, late_grace_hours, cost, rubric_id, is_template, series_id, series_index, state, publish_at, published_at, created_at, updated_at
+`
+
+// PublishDue publishes the scheduled tasks whose publication time has come
+// and returns them.
+func (r *TasksRepo) PublishDue(
+	ctx context.Context,
+	now time.Time,
+) ([]models.Task, error) {
+	var tasks []task
+	if err := r.db.SelectContext(ctx, &tasks, tasksRepoPublishDueQuery, now); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return lo.Map(
+		tasks,
+		func(item task, _ int) models.Task {
+			return item.toServiceModel()
+		},
+	), nil
 }
 
 const tasksRepoDeleteQuery = `
-delete from public.task where id = $1
+update public.task set deleted_at = now() where id = $1 and deleted_at is null
 `
 
 func (r *TasksRepo) Delete(
@@ -1,12 +1,36 @@
 package pg
 
 import (
+	"backend/internal/models"
 	"backend/internal/repo"
 	"context"
 	"fmt"
+	"time"
+
 	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
 )
 
+type taskLink struct {
+	Id            int64      `db:"id"`
+	TaskId        int64      `db:"task_id"`
+	UserId        *int64     `db:"user_id"`
+	GroupId       *int64     `db:"group_id"`
+	EffectiveFrom *time.Time `db:"effective_from"`
+	EffectiveTill *time.Time `db:"effective_till"`
+}
+
+func (l taskLink) toServiceModel() models.TaskLink {
+	return models.TaskLink{
+		Id:            l.Id,
+		TaskId:        l.TaskId,
+		UserId:        l.UserId,
+		GroupId:       l.GroupId,
+		EffectiveFrom: l.EffectiveFrom,
+		EffectiveTill: l.EffectiveTill,
+	}
+}
+
 type TaskLinksRepo struct {
 	db *sqlx.DB
 }
@@ -15,6 +39,29 @@ func NewTaskLinksRepo(db *sqlx.DB) *TaskLinksRepo {
 	return &TaskLinksRepo{db: db}
 }
 
+const taskLinksGetByTaskIdQuery = `
+select tl.id, tl.task_id, tl.user_id, tl.group_id, tl.effective_from, tl.effective_till
+from public.task_links tl
+where tl.task_id = $1
+order by tl.id
+`
+
+func (r *TaskLinksRepo) GetByTaskId(
+	ctx context.Context,
+	taskId int64,
+) ([]models.TaskLink, error) {
+	var links []taskLink
+	if err := r.db.SelectContext(ctx, &links, taskLinksGetByTaskIdQuery, taskId); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return lo.Map(
+		links,
+		func(item taskLink, _ int) models.TaskLink {
+			return item.toServiceModel()
+		},
+	), nil
+}
+
 const taskLinksCreateQuery = `
 insert into task_links (user_id, group_id, task_id) values ($1, $2, $3)
 `
@@ -28,3 +75,63 @@ func (r *TaskLinksRepo) Create(
 	}
 	return nil
 }
+
+const (
+	taskLinksSetUserWindowQuery = `
+update public.task_links
+set effective_from = $3, effective_till = $4
+where task_id = $1
+  and user_id = $2
+`
+	taskLinksSetGroupWindowQuery = `
+update public.task_links
+set effective_from = $3, effective_till = $4
+where task_id = $1
+  and group_id = $2
+`
+)
+
+// SetWindow stores the date overrides of the existing user or group link of
+// the task. It returns repo.ErrNotFound when the task has no such link.
+func (r *TaskLinksRepo) SetWindow(
+	ctx context.Context,
+	opts repo.TaskLinksRepoSetWindowOpts,
+) error {
+	query, assigneeId := taskLinksSetGroupWindowQuery, opts.GroupId
+	if opts.UserId != nil {
+		query, assigneeId = taskLinksSetUserWindowQuery, opts.UserId
+	}
+
+	res, err := r.db.ExecContext(ctx, query, opts.TaskId, assigneeId, opts.EffectiveFrom, opts.EffectiveTill)
+	if err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+const taskLinksGetAssigneeIdsQuery = `
+select distinct u.id
+from public.task_links tl
+left join public."group" g on g.id = tl.group_id
+join public."user" u on u.id = tl.user_id or u.group_id = tl.group_id and g.deleted_at is null
+where tl.task_id = $1
+order by u.id
+`
+
+func (r *TaskLinksRepo) GetAssigneeIds(
+	ctx context.Context,
+	taskId int64,
+) ([]int64, error) {
+	var ids []int64
+	if err := r.db.SelectContext(ctx, &ids, taskLinksGetAssigneeIdsQuery, taskId); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,220 @@
+package pg
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+type taskPrerequisite struct {
+	Id                 int64     `db:"id"`
+	TaskId             int64     `db:"task_id"`
+	PrerequisiteTaskId int64     `db:"prerequisite_task_id"`
+	Condition          string    `db:"condition"`
+	MinMark            *int64    `db:"min_mark"`
+	CreatedAt          time.Time `db:"created_at"`
+}
+
+func (p taskPrerequisite) toServiceModel() models.TaskPrerequisite {
+	return models.TaskPrerequisite{
+		Id:                 p.Id,
+		TaskId:             p.TaskId,
+		PrerequisiteTaskId: p.PrerequisiteTaskId,
+		Condition:          p.Condition,
+		MinMark:            p.MinMark,
+		CreatedAt:          p.CreatedAt,
+	}
+}
+
+type TaskPrerequisitesRepo struct {
+	db *sqlx.DB
+}
+
+func NewTaskPrerequisitesRepo(db *sqlx.DB) *TaskPrerequisitesRepo {
+	return &TaskPrerequisitesRepo{db: db}
+}
+
+const taskPrerequisitesRepoGetByTaskIdQuery = `
+select 
+    tp.id, 
+    tp.task_id, 
+    tp.prerequisite_task_id, 
+    tp.condition, 
+    tp.min_mark, 
+    tp.created_at
+from public.task_prerequisite tp
+where tp.task_id = $1
+order by tp.id
+`
+
+func (r *TaskPrerequisitesRepo) GetByTaskId(
+	ctx context.Context,
+	taskId int64,
+) ([]models.TaskPrerequisite, error) {
+	var prerequisites []taskPrerequisite
+	if err := r.db.SelectContext(ctx, &prerequisites, taskPrerequisitesRepoGetByTaskIdQuery, taskId); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return lo.Map(
+		prerequisites,
+		func(item taskPrerequisite, _ int) models.TaskPrerequisite {
+			return item.toServiceModel()
+		},
+	), nil
+}
+
+// taskPrerequisitesRepoGetUnmetQuery selects the prerequisites of the tasks $2
+// that the student $1 has not met yet, judged by the latest answer of the
+// student or of the student's team and its latest mark.
+const taskPrerequisitesRepoGetUnmetQuery = `
+select 
+    tp.id, 
+    tp.task_id, 
+    tp.prerequisite_task_id, 
+    tp.condition, 
+    tp.min_mark, 
+    tp.created_at
+from public.task_prerequisite tp
+join public.task pt on pt.id = tp.prerequisite_task_id
+left join lateral (
+    select a.id, a.team_id
+    from public.answer a
+    where a.task_id = tp.prerequisite_task_id
+      and (a.user_id = $1 or a.team_id = public.team_of(tp.prerequisite_task_id, $1))
+      and a.deleted_at is null
+    order by a.id desc
+    limit 1
+) a on true
+left join lateral (
+    select public.member_mark(m.mark, a.team_id, $1) as mark
+    from public.mark m
+    where m.answer_id = a.id
+    order by m.id desc
+    limit 1
+) m on true
+where tp.task_id = any ($2::bigint[])
+  and pt.deleted_at is null
+  and not case tp.condition
+      when 'submitted' then a.id is not null
+      when 'graded' then m.mark is not null
+      else coalesce(m.mark >= tp.min_mark, false)
+  end
+order by tp.task_id, tp.id
+`
+
+func (r *TaskPrerequisitesRepo) GetUnmet(
+	ctx context.Context,
+	userId int64,
+	taskIds []int64,
+) ([]models.TaskPrerequisite, error) {
+	var prerequisites []taskPrerequisite
+	if err := r.db.SelectContext(ctx, &prerequisites, taskPrerequisitesRepoGetUnmetQuery, userId, taskIds); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return lo.Map(
+		prerequisites,
+		func(item taskPrerequisite, _ int) models.TaskPrerequisite {
+			return item.toServiceModel()
+		},
+	), nil
+}
+
+const (
+	taskPrerequisitesRepoLockQuery = `
+lock table public.task_prerequisite in share row exclusive mode
+`
+	// taskPrerequisitesRepoReachesQuery tells whether the task $2 is among the
+	// direct or transitive prerequisites of the task $1.
+	taskPrerequisitesRepoReachesQuery = `
+with recursive chain as (
+    select tp.prerequisite_task_id id
+    from public.task_prerequisite tp
+    where tp.task_id = $1
+    union
+    select tp.prerequisite_task_id
+    from public.task_prerequisite tp
+    join chain c on tp.task_id = c.id
+)
+select exists (select 1 from chain where id = $2)
+`
+	taskPrerequisitesRepoCreateQuery = `
+insert into public.task_prerequisite (task_id, prerequisite_task_id, condition, min_mark)
+values ($1, $2, $3, $4)
+on conflict (task_id, prerequisite_task_id) do update
+set condition = excluded.condition, min_mark = excluded.min_mark
+returning id, task_id, prerequisite_task_id, condition, min_mark, created_at
+`
+)
+
+// Create adds the edge, or replaces the condition of an existing one. It
+// returns repo.ErrCycle when the prerequisite already depends on the task.
+// The table is locked so that two concurrent edges cannot close a cycle.
+func (r *TaskPrerequisitesRepo) Create(
+	ctx context.Context,
+	opts repo.TaskPrerequisitesRepoCreateOpts,
+) (models.TaskPrerequisite, error) {
+	if opts.TaskId == opts.PrerequisiteTaskId {
+		return models.TaskPrerequisite{}, repo.ErrCycle
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.TaskPrerequisite{}, fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, taskPrerequisitesRepoLockQuery); err != nil {
+		return models.TaskPrerequisite{}, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+
+	var cycle bool
+	if err = tx.GetContext(ctx, &cycle, taskPrerequisitesRepoReachesQuery, opts.PrerequisiteTaskId, opts.TaskId); err != nil {
+		return models.TaskPrerequisite{}, fmt.Errorf("tx.GetContext: %w", err)
+	}
+	if cycle {
+		return models.TaskPrerequisite{}, repo.ErrCycle
+	}
+
+	var p taskPrerequisite
+	if err = tx.GetContext(
+		ctx, &p, taskPrerequisitesRepoCreateQuery,
+		opts.TaskId,
+		opts.PrerequisiteTaskId,
+		opts.Condition,
+		opts.MinMark,
+	); err != nil {
+		return models.TaskPrerequisite{}, fmt.Errorf("tx.GetContext: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.TaskPrerequisite{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+	return p.toServiceModel(), nil
+}
+
+const taskPrerequisitesRepoDeleteQuery = `
+delete from public.task_prerequisite where task_id = $1 and id = $2
+`
+
+func (r *TaskPrerequisitesRepo) Delete(
+	ctx context.Context,
+	taskId, id int64,
+) error {
+	res, err := r.db.ExecContext(ctx, taskPrerequisitesRepoDeleteQuery, taskId, id)
+	if err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package pg
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+type taskSeries struct {
+	Id           int64      `db:"id"`
+	SourceTaskId int64      `db:"source_task_id"`
+	RRule        string     `db:"rrule"`
+	Generated    int64      `db:"generated"`
+	NextRunAt    *time.Time `db:"next_run_at"`
+	CancelledAt  *time.Time `db:"cancelled_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+	UpdatedAt    *time.Time `db:"updated_at"`
+}
+
+func (s taskSeries) toServiceModel() models.TaskSeries {
+	return models.TaskSeries{
+		Id:           s.Id,
+		SourceTaskId: s.SourceTaskId,
+		RRule:        s.RRule,
+		Generated:    s.Generated,
+		NextRunAt:    s.NextRunAt,
+		CancelledAt:  s.CancelledAt,
+		CreatedAt:    s.CreatedAt,
+		UpdatedAt:    s.UpdatedAt,
+	}
+}
+
+type TaskSeriesRepo struct {
+	db *sqlx.DB
+}
+
+func NewTaskSeriesRepo(db *sqlx.DB) *TaskSeriesRepo {
+	return &TaskSeriesRepo{db: db}
+}
+
+const taskSeriesRepoGetByIdQuery = `
+select 
+    ts.id, 
+    ts.source_task_id, 
+    ts.rrule, 
+    ts.generated, 
+    ts.next_run_at, 
+    ts.cancelled_at, 
+    ts.created_at, 
+    ts.updated_at
+from public.task_series ts
+where ts.id = $1
+`
+
+func (r *TaskSeriesRepo) GetById(
+	ctx context.Context,
+	id int64,
+) (models.TaskSeries, error) {
+	var s taskSeries
+	if err := r.db.GetContext(ctx, &s, taskSeriesRepoGetByIdQuery, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TaskSeries{}, repo.ErrNotFound
+		}
+		return models.TaskSeries{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return s.toServiceModel(), nil
+}
+
+const taskSeriesRepoHasInstanceQuery = `
+select exists (
+    select 1
+    from public.task t
+    where t.series_id = $1
+      and t.series_index = $2
+)
+`
+
+// HasInstance reports whether the instance with the given index of the series
+// has already been created, including one moved to the trash since.
+func (r *TaskSeriesRepo) HasInstance(
+	ctx context.Context,
+	id int64,
+	index int64,
+) (bool, error) {
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, taskSeriesRepoHasInstanceQuery, id, index); err != nil {
+		return false, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return exists, nil
+}
+
+const taskSeriesRepoGetDueQuery = `
+select 
+    ts.id, 
+    ts.source_task_id, 
+    ts.rrule, 
+    ts.generated, 
+    ts.next_run_at, 
+    ts.cancelled_at, 
+    ts.created_at, 
+    ts.updated_at
+from public.task_series ts
+where ts.cancelled_at is null
+  and ts.next_run_at <= $1
+  and exists (select 1 from public.task t where t.id = ts.source_task_id and t.deleted_at is null)
+order by ts.next_run_at
+`
+
+func (r *TaskSeriesRepo) GetDue(
+	ctx context.Context,
+	now time.Time,
+) ([]models.TaskSeries, error) {
+	var series []taskSeries
+	if err := r.db.SelectContext(ctx, &series, taskSeriesRepoGetDueQuery, now); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return lo.Map(
+		series,
+		func(item taskSeries, _ int) models.TaskSeries {
+			return item.toServiceModel()
+		},
+	), nil
+}
+
+const (
+	taskSeriesRepoCreateQuery = `
+insert into public.task_series (source_task_id, rrule, next_run_at) 
+values ($1, $2, $3)
+returning id, source_task_id, rrule, generated, next_run_at, cancelled_at, created_at, updated_at
+`
+	taskSeriesRepoAttachSourceQuery = `
+update public.task set series_id = $2, series_index = 0 where id = $1
+`
+)
+
+func (r *TaskSeriesRepo) Create(
+	ctx context.Context,
+	opts repo.TaskSeriesRepoCreateOpts,
+) (models.TaskSeries, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.TaskSeries{}, fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var s taskSeries
+	if err = tx.GetContext(ctx, &s, taskSeriesRepoCreateQuery, opts.SourceTaskId, opts.RRule, opts.NextRunAt); err != nil {
+		return models.TaskSeries{}, fmt.Errorf("tx.GetContext: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, taskSeriesRepoAttachSourceQuery, opts.SourceTaskId, s.Id); err != nil {
+		return models.TaskSeries{}, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.TaskSeries{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+	return s.toServiceModel(), nil
+}
+
+const taskSeriesRepoUpdateQuery = `
+update public.task_series
+set (
+     rrule, 
+     generated, 
+     next_run_at, 
+     updated_at
+    ) = (
+     $2, 
+     $3, 
+     $4, 
+     now()
+    )
+where id = $1
+returning id, source_task_id, rrule, generated, next_run_at, cancelled_at, created_at, updated_at
+`
+
+func (r *TaskSeriesRepo) Update(
+	ctx context.Context,
+	opts repo.TaskSeriesRepoUpdateOpts,
+) (models.TaskSeries, error) {
+	var s taskSeries
+	if err := r.db.GetContext(ctx, &s, taskSeriesRepoUpdateQuery, opts.Id, opts.RRule, opts.Generated, opts.NextRunAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TaskSeries{}, repo.ErrNotFound
+		}
+		return models.TaskSeries{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return s.toServiceModel(), nil
+}
+
+const (
+	taskSeriesRepoCancelQuery = `
+update public.task_series
+set cancelled_at = now(), next_run_at = null, updated_at = now()
+where id = $1
+  and cancelled_at is null
+`
+	taskSeriesRepoGetPendingQuery = `
+select t.id
+from public.task t
+where t.series_id = $1
+  and t.series_index > 0
+  and t.deleted_at is null
+  and t.effective_from > now()
+  and not exists (select 1 from public.answer a where a.task_id = t.id)
+`
+	taskSeriesRepoTrashFilesQuery = `
+update public.file
+set deleted_at = now()
+where task_id = any ($1::bigint[])
+  and deleted_at is null
+`
+	taskSeriesRepoTrashTasksQuery = `
+update public.task
+set deleted_at = now()
+where id = any ($1::bigint[])
+  and deleted_at is null
+`
+)
+
+// Cancel stops the series and moves to the trash its generated instances that
+// have not started yet and have no answers, together with their files. The
+// trash purge removes them and their contents for good later.
+func (r *TaskSeriesRepo) Cancel(
+	ctx context.Context,
+	id int64,
+) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, taskSeriesRepoCancelQuery, id)
+	if err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return repo.ErrNotFound
+	}
+
+	var taskIds []int64
+	if err = tx.SelectContext(ctx, &taskIds, taskSeriesRepoGetPendingQuery, id); err != nil {
+		return fmt.Errorf("tx.SelectContext: %w", err)
+	}
+	for _, query := range []string{taskSeriesRepoTrashFilesQuery, taskSeriesRepoTrashTasksQuery} {
+		if _, err = tx.ExecContext(ctx, query, taskIds); err != nil {
+			return fmt.Errorf("tx.ExecContext: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,480 @@
+package pg
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+type teamSettings struct {
+	TaskId     int64      `db:"task_id"`
+	MinSize    int64      `db:"min_size"`
+	MaxSize    int64      `db:"max_size"`
+	SelfSignup bool       `db:"self_signup"`
+	CreatedAt  time.Time  `db:"created_at"`
+	UpdatedAt  *time.Time `db:"updated_at"`
+}
+
+func (s teamSettings) toServiceModel() models.TeamSettings {
+	return models.TeamSettings{
+		TaskId:     s.TaskId,
+		MinSize:    s.MinSize,
+		MaxSize:    s.MaxSize,
+		SelfSignup: s.SelfSignup,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+type team struct {
+	Id        int64     `db:"id"`
+	TaskId    int64     `db:"task_id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (t team) toServiceModel() models.Team {
+	return models.Team{
+		Id:        t.Id,
+		TaskId:    t.TaskId,
+		Name:      t.Name,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+type teamMember struct {
+	TeamId            int64     `db:"team_id"`
+	UserId            int64     `db:"user_id"`
+	UserName          string    `db:"user_name"`
+	MarkAdjustment    int64     `db:"mark_adjustment"`
+	AdjustmentComment *string   `db:"adjustment_comment"`
+	JoinedAt          time.Time `db:"joined_at"`
+}
+
+func (m teamMember) toServiceModel() models.TeamMember {
+	return models.TeamMember{
+		UserId:            m.UserId,
+		UserName:          m.UserName,
+		MarkAdjustment:    m.MarkAdjustment,
+		AdjustmentComment: m.AdjustmentComment,
+		JoinedAt:          m.JoinedAt,
+	}
+}
+
+type TeamsRepo struct {
+	db *sqlx.DB
+}
+
+func NewTeamsRepo(db *sqlx.DB) *TeamsRepo {
+	return &TeamsRepo{db: db}
+}
+
+const teamsRepoGetSettingsQuery = `
+select tt.task_id, tt.min_size, tt.max_size, tt.self_signup, tt.created_at, tt.updated_at
+from public.task_team tt
+where tt.task_id = $1
+`
+
+func (r *TeamsRepo) GetSettings(
+	ctx context.Context,
+	taskId int64,
+) (models.TeamSettings, error) {
+	var s teamSettings
+	if err := r.db.GetContext(ctx, &s, teamsRepoGetSettingsQuery, taskId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TeamSettings{}, repo.ErrNotFound
+		}
+		return models.TeamSettings{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return s.toServiceModel(), nil
+}
+
+const teamsRepoSetSettingsQuery = `
+insert into public.task_team (task_id, min_size, max_size, self_signup)
+values ($1, $2, $3, $4)
+on conflict (task_id) do update
+set min_size = excluded.min_size,
+    max_size = excluded.max_size,
+    self_signup = excluded.self_signup,
+    updated_at = now()
+returning task_id, min_size, max_size, self_signup, created_at, updated_at
+`
+
+func (r *TeamsRepo) SetSettings(
+	ctx context.Context,
+	opts repo.TeamsRepoSetSettingsOpts,
+) (models.TeamSettings, error) {
+	var s teamSettings
+	if err := r.db.GetContext(
+		ctx, &s, teamsRepoSetSettingsQuery,
+		opts.TaskId,
+		opts.MinSize,
+		opts.MaxSize,
+		opts.SelfSignup,
+	); err != nil {
+		return models.TeamSettings{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return s.toServiceModel(), nil
+}
+
+const (
+	teamsRepoDeleteTeamsQuery = `
+delete from public.team where task_id = $1
+`
+	teamsRepoDeleteSettingsQuery = `
+delete from public.task_team where task_id = $1
+`
+)
+
+// DeleteSettings turns the task back into an individual one and drops its
+// teams.
+func (r *TeamsRepo) DeleteSettings(
+	ctx context.Context,
+	taskId int64,
+) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, teamsRepoDeleteTeamsQuery, taskId); err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, teamsRepoDeleteSettingsQuery, taskId)
+	if err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return repo.ErrNotFound
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+	return nil
+}
+
+const (
+	teamsRepoGetByTaskIdQuery = `
+select t.id, t.task_id, t.name, t.created_at
+from public.team t
+where t.task_id = $1
+order by t.id
+`
+	teamsRepoGetByIdQuery = `
+select t.id, t.task_id, t.name, t.created_at
+from public.team t
+where t.id = $1
+`
+	teamsRepoGetByUserQuery = `
+select t.id, t.task_id, t.name, t.created_at
+from public.team t
+where t.id = public.team_of($1, $2)
+`
+	teamsRepoGetMembersQuery = `
+select
+    tm.team_id,
+    tm.user_id,
+    u.last_name || ' ' || u.first_name user_name,
+    tm.mark_adjustment,
+    tm.adjustment_comment,
+    tm.joined_at
+from public.team_member tm
+join public.user u on u.id = tm.user_id
+where tm.team_id = any ($1::bigint[])
+order by tm.joined_at, tm.user_id
+`
+)
+
+func (r *TeamsRepo) GetByTaskId(
+	ctx context.Context,
+	taskId int64,
+) ([]models.Team, error) {
+	var teams []team
+	if err := r.db.SelectContext(ctx, &teams, teamsRepoGetByTaskIdQuery, taskId); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return r.withMembers(ctx, teams)
+}
+
+func (r *TeamsRepo) GetById(
+	ctx context.Context,
+	id int64,
+) (models.Team, error) {
+	var t team
+	if err := r.db.GetContext(ctx, &t, teamsRepoGetByIdQuery, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Team{}, repo.ErrNotFound
+		}
+		return models.Team{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	teams, err := r.withMembers(ctx, []team{t})
+	if err != nil {
+		return models.Team{}, err
+	}
+	return teams[0], nil
+}
+
+// GetByUser returns the team the user belongs to on the task.
+func (r *TeamsRepo) GetByUser(
+	ctx context.Context,
+	taskId, userId int64,
+) (models.Team, error) {
+	var t team
+	if err := r.db.GetContext(ctx, &t, teamsRepoGetByUserQuery, taskId, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Team{}, repo.ErrNotFound
+		}
+		return models.Team{}, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	teams, err := r.withMembers(ctx, []team{t})
+	if err != nil {
+		return models.Team{}, err
+	}
+	return teams[0], nil
+}
+
+func (r *TeamsRepo) withMembers(
+	ctx context.Context,
+	teams []team,
+) ([]models.Team, error) {
+	var members []teamMember
+	if err := r.db.SelectContext(
+		ctx, &members, teamsRepoGetMembersQuery,
+		lo.Map(teams, func(item team, _ int) int64 { return item.Id }),
+	); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+
+	byTeam := lo.GroupBy(members, func(item teamMember) int64 { return item.TeamId })
+	return lo.Map(
+		teams,
+		func(item team, _ int) models.Team {
+			result := item.toServiceModel()
+			result.Members = lo.Map(
+				byTeam[item.Id],
+				func(member teamMember, _ int) models.TeamMember {
+					return member.toServiceModel()
+				},
+			)
+			return result
+		},
+	), nil
+}
+
+const teamsRepoHasAnswersQuery = `
+select exists (
+    select 1
+    from public.answer a
+    where a.task_id = $1
+      and a.team_id is not null
+      and ($2::bigint is null or a.team_id = $2)
+      and a.deleted_at is null
+)
+`
+
+// HasAnswers tells whether the team, or any team of the task when teamId is
+// nil, has submitted an answer.
+func (r *TeamsRepo) HasAnswers(
+	ctx context.Context,
+	taskId int64,
+	teamId *int64,
+) (bool, error) {
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, teamsRepoHasAnswersQuery, taskId, teamId); err != nil {
+		return false, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return exists, nil
+}
+
+const (
+	teamsRepoCreateQuery = `
+insert into public.team (task_id, name)
+values ($1, $2)
+returning id, task_id, name, created_at
+`
+	teamsRepoLockTeamQuery = `
+select t.task_id from public.team t where t.id = $1 for update
+`
+	teamsRepoIsMemberQuery = `
+select exists (select 1 from public.team_member tm where tm.task_id = $1 and tm.user_id = $2)
+`
+	teamsRepoCountMembersQuery = `
+select count(*) from public.team_member tm where tm.team_id = $1
+`
+	teamsRepoAddMemberQuery = `
+insert into public.team_member (team_id, task_id, user_id)
+values ($1, $2, $3)
+`
+)
+
+func (r *TeamsRepo) Create(
+	ctx context.Context,
+	opts repo.TeamsRepoCreateOpts,
+) (models.Team, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return models.Team{}, fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var t team
+	if err = tx.GetContext(ctx, &t, teamsRepoCreateQuery, opts.TaskId, opts.Name); err != nil {
+		return models.Team{}, fmt.Errorf("tx.GetContext: %w", err)
+	}
+	for _, userId := range lo.Uniq(opts.UserIds) {
+		if err = addTeamMember(ctx, tx, t.Id, userId, opts.MaxSize); err != nil {
+			return models.Team{}, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return models.Team{}, fmt.Errorf("tx.Commit: %w", err)
+	}
+	return r.GetById(ctx, t.Id)
+}
+
+// AddMember adds the user to the team. It returns repo.ErrConflict when the
+// user is already in a team on the same task and repo.ErrLimitExceeded when
+// the team already has MaxSize members. The team row is locked so that
+// concurrent sign-ups cannot overfill it.
+func (r *TeamsRepo) AddMember(
+	ctx context.Context,
+	opts repo.TeamsRepoAddMemberOpts,
+) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err = addTeamMember(ctx, tx, opts.TeamId, opts.UserId, opts.MaxSize); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+	return nil
+}
+
+func addTeamMember(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	teamId, userId, maxSize int64,
+) error {
+	var taskId int64
+	if err := tx.GetContext(ctx, &taskId, teamsRepoLockTeamQuery, teamId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repo.ErrNotFound
+		}
+		return fmt.Errorf("tx.GetContext: %w", err)
+	}
+
+	var isMember bool
+	if err := tx.GetContext(ctx, &isMember, teamsRepoIsMemberQuery, taskId, userId); err != nil {
+		return fmt.Errorf("tx.GetContext: %w", err)
+	}
+	if isMember {
+		return repo.ErrConflict
+	}
+
+	var count int64
+	if err := tx.GetContext(ctx, &count, teamsRepoCountMembersQuery, teamId); err != nil {
+		return fmt.Errorf("tx.GetContext: %w", err)
+	}
+	if count >= maxSize {
+		return repo.ErrLimitExceeded
+	}
+
+	if _, err := tx.ExecContext(ctx, teamsRepoAddMemberQuery, teamId, taskId, userId); err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	return nil
+}
+
+const teamsRepoDeleteQuery = `
+delete from public.team where id = $1
+`
+
+func (r *TeamsRepo) Delete(
+	ctx context.Context,
+	id int64,
+) error {
+	res, err := r.db.ExecContext(ctx, teamsRepoDeleteQuery, id)
+	if err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+const teamsRepoRemoveMemberQuery = `
+delete from public.team_member where team_id = $1 and user_id = $2
+`
+
+func (r *TeamsRepo) RemoveMember(
+	ctx context.Context,
+	teamId, userId int64,
+) error {
+	res, err := r.db.ExecContext(ctx, teamsRepoRemoveMemberQuery, teamId, userId)
+	if err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+const teamsRepoSetAdjustmentQuery = `
+update public.team_member
+set mark_adjustment = $3, adjustment_comment = $4
+where team_id = $1 and user_id = $2
+`
+
+func (r *TeamsRepo) SetAdjustment(
+	ctx context.Context,
+	opts repo.TeamsRepoSetAdjustmentOpts,
+) error {
+	res, err := r.db.ExecContext(
+		ctx, teamsRepoSetAdjustmentQuery,
+		opts.TeamId,
+		opts.UserId,
+		opts.Adjustment,
+		opts.Comment,
+	)
+	if err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
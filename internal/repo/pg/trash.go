@@ -0,0 +1,322 @@
+package pg
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+type trashItem struct {
+	Kind      string    `db:"kind"`
+	Id        int64     `db:"id"`
+	Title     string    `db:"title"`
+	DeletedAt time.Time `db:"deleted_at"`
+}
+
+func (i trashItem) toServiceModel() models.TrashItem {
+	return models.TrashItem{
+		Kind:      i.Kind,
+		Id:        i.Id,
+		Title:     i.Title,
+		DeletedAt: i.DeletedAt,
+	}
+}
+
+type TrashRepo struct {
+	db *sqlx.DB
+}
+
+func NewTrashRepo(db *sqlx.DB) *TrashRepo {
+	return &TrashRepo{db: db}
+}
+
+// Every trash query takes the caller as $1 and, for administrators, the user
+// whose groups and org units scope them as $2. Callers see the items they
+// own; administrators also see those whose owner or assignees they see.
+// Administrators without org units see everything.
+const (
+	trashRepoGlobalAdminCond = `not exists (select 1 from public.org_unit_admin oua where oua.user_id = $2)`
+	// trashRepoTaskScopeCond takes the task id column.
+	trashRepoTaskScopeCond = `exists (
+      select 1
+      from public.task_links tl
+      where tl.task_id = %s
+        and (tl.group_id in (select public.visible_groups($2))
+         or tl.user_id is not null and public.sees_student($2, tl.user_id, now()))
+  )`
+)
+
+var (
+	trashRepoTaskCond = `(t.created_by = $1 or $2::bigint is not null and (` +
+		trashRepoGlobalAdminCond + ` or ` + fmt.Sprintf(trashRepoTaskScopeCond, "t.id") + `))`
+	trashRepoAnswerCond = `(a.user_id = $1 or $2::bigint is not null and public.sees_student($2, a.user_id, now()))`
+	trashRepoFileCond   = `(coalesce(t.created_by, a.user_id, c.author_id) = $1 or $2::bigint is not null and (` +
+		trashRepoGlobalAdminCond +
+		` or ` + fmt.Sprintf(trashRepoTaskScopeCond, "coalesce(f.task_id, c.task_id)") +
+		` or a.user_id is not null and public.sees_student($2, a.user_id, now())))`
+	trashRepoGroupCond = `$2::bigint is not null and g.id in (select public.visible_groups($2))`
+)
+
+// trashRepoItemsQuery selects the items deleted directly that the caller may
+// restore. Items hidden only because their parent is deleted are restored
+// with the parent and are not listed. A task belongs to its creator, an
+// answer to its author and a file to the owner of the task, answer or comment
+// it is attached to. Groups have no owner and are only listed to
+// administrators who see them.
+var trashRepoItemsQuery = `
+select 'task' as kind, t.id, t.title, t.deleted_at
+from public.task t
+where t.deleted_at is not null
+  and ` + trashRepoTaskCond + `
+union all
+select 'answer', a.id, t.title, a.deleted_at
+from public.answer a
+join public.task t on t.id = a.task_id
+where a.deleted_at is not null
+  and ` + trashRepoAnswerCond + `
+union all
+select 'file', f.id, f.name, f.deleted_at
+from public.file f
+left join public.task t on t.id = f.task_id
+left join public.answer a on a.id = f.answer_id
+left join public.comment c on c.id = f.comment_id
+where f.deleted_at is not null
+  and ` + trashRepoFileCond + `
+union all
+select 'group', g.id, g.name, g.deleted_at
+from public."group" g
+where g.deleted_at is not null
+  and ` + trashRepoGroupCond + `
+`
+
+var trashRepoGetListQuery = `
+select *
+from (` + trashRepoItemsQuery + `) i
+order by i.deleted_at desc, i.kind, i.id
+limit $3
+offset $4
+`
+
+func (r *TrashRepo) GetList(
+	ctx context.Context,
+	opts repo.TrashRepoGetListOpts,
+) ([]models.TrashItem, error) {
+	var items []trashItem
+	if err := r.db.SelectContext(
+		ctx, &items, trashRepoGetListQuery, opts.UserId, opts.ScopeUserId, opts.Limit, opts.Offset,
+	); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return lo.Map(
+		items,
+		func(item trashItem, _ int) models.TrashItem {
+			return item.toServiceModel()
+		},
+	), nil
+}
+
+var trashRepoGetCountQuery = `
+select count(*)
+from (` + trashRepoItemsQuery + `) i
+`
+
+func (r *TrashRepo) GetCount(
+	ctx context.Context,
+	opts repo.TrashRepoGetListOpts,
+) (int64, error) {
+	var count int64
+	if err := r.db.GetContext(ctx, &count, trashRepoGetCountQuery, opts.UserId, opts.ScopeUserId); err != nil {
+		return 0, fmt.Errorf("r.db.GetContext: %w", err)
+	}
+	return count, nil
+}
+
+var trashRepoRestoreQueries = map[string]string{
+	models.TrashKindTask: `
+update public.task t
+set deleted_at = null
+where t.id = $3
+  and t.deleted_at is not null
+  and ` + trashRepoTaskCond + `
+`,
+	models.TrashKindAnswer: `
+update public.answer a
+set deleted_at = null
+where a.id = $3
+  and a.deleted_at is not null
+  and ` + trashRepoAnswerCond + `
+`,
+	models.TrashKindFile: `
+update public.file f
+set deleted_at = null
+from public.file ff
+left join public.task t on t.id = ff.task_id
+left join public.answer a on a.id = ff.answer_id
+left join public.comment c on c.id = ff.comment_id
+where f.id = $3
+  and ff.id = f.id
+  and f.deleted_at is not null
+  and ` + trashRepoFileCond + `
+`,
+	models.TrashKindGroup: `
+update public."group" g
+set deleted_at = null
+where g.id = $3
+  and g.deleted_at is not null
+  and ` + trashRepoGroupCond + `
+`,
+}
+
+func (r *TrashRepo) Restore(
+	ctx context.Context,
+	opts repo.TrashRepoRestoreOpts,
+) error {
+	query, ok := trashRepoRestoreQueries[opts.Kind]
+	if !ok {
+		return repo.ErrNotFound
+	}
+
+	res, err := r.db.ExecContext(ctx, query, opts.UserId, opts.ScopeUserId, opts.Id)
+	if err != nil {
+		return fmt.Errorf("r.db.ExecContext: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("res.RowsAffected: %w", err)
+	} else if affected == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+const (
+	trashRepoExpiredTasksQuery = `
+select id from public.task where deleted_at < $1
+`
+	trashRepoExpiredAnswersQuery = `
+select id from public.answer where deleted_at < $1 or task_id = any ($2::bigint[])
+`
+	trashRepoExpiredGroupsQuery = `
+select g.id
+from public."group" g
+where g.deleted_at < $1
+  and not exists (select 1 from public."user" u where u.group_id = g.id)
+  and not exists (select 1 from public.group_membership gm where gm.group_id = g.id)
+`
+	trashRepoDeleteCheckersQuery = `
+delete from public.task_checker where task_id = any ($1::bigint[])
+`
+	// Files still used as tests by a remaining checker are kept, detached
+	// from the purged task or answer below.
+	trashRepoPurgeFilesQuery = `
+delete from public.file f
+where (f.deleted_at < $1 or f.task_id = any ($2::bigint[]) or f.answer_id = any ($3::bigint[])
+       or f.comment_id in (select c.id from public.comment c where c.task_id = any ($2::bigint[])))
+  and not exists (select 1 from public.task_checker tc where tc.tests_file_id = f.id)
+returning f.id, f.name, f.filename, f.filepath, f.created_at, f.updated_at, f.task_id, f.answer_id, f.comment_id
+`
+	trashRepoDetachTaskFilesQuery = `
+update public.file set task_id = null where task_id = any ($1::bigint[])
+`
+	trashRepoDetachAnswerFilesQuery = `
+update public.file set answer_id = null where answer_id = any ($1::bigint[])
+`
+	trashRepoDeleteMarksQuery = `
+delete from public.mark where answer_id = any ($1::bigint[])
+`
+	trashRepoDeleteAnswersQuery = `
+delete from public.answer where id = any ($1::bigint[])
+`
+	trashRepoDeleteTaskLinksQuery = `
+delete from public.task_links where task_id = any ($1::bigint[])
+`
+	trashRepoDetachSeriesQuery = `
+update public.task
+set series_id = null
+where series_id in (select ts.id from public.task_series ts where ts.source_task_id = any ($1::bigint[]))
+`
+	trashRepoDeleteSeriesQuery = `
+delete from public.task_series where source_task_id = any ($1::bigint[])
+`
+	trashRepoDeleteTasksQuery = `
+delete from public.task where id = any ($1::bigint[])
+`
+	trashRepoDeleteGroupLinksQuery = `
+delete from public.task_links where group_id = any ($1::bigint[])
+`
+	trashRepoDeleteGroupAssignmentsQuery = `
+delete from public.group_assignment where group_id = any ($1::bigint[])
+`
+	trashRepoDeleteGroupsQuery = `
+delete from public."group" where id = any ($1::bigint[])
+`
+)
+
+// Purge removes for good the items deleted before the given time together
+// with everything hidden under them, and returns the removed files so that
+// their contents can be deleted from disk. Groups still referenced by users
+// or by membership history stay in the trash.
+func (r *TrashRepo) Purge(
+	ctx context.Context,
+	before time.Time,
+) ([]models.File, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var taskIds, answerIds, groupIds []int64
+	if err = tx.SelectContext(ctx, &taskIds, trashRepoExpiredTasksQuery, before); err != nil {
+		return nil, fmt.Errorf("tx.SelectContext: %w", err)
+	}
+	if err = tx.SelectContext(ctx, &answerIds, trashRepoExpiredAnswersQuery, before, taskIds); err != nil {
+		return nil, fmt.Errorf("tx.SelectContext: %w", err)
+	}
+	if err = tx.SelectContext(ctx, &groupIds, trashRepoExpiredGroupsQuery, before); err != nil {
+		return nil, fmt.Errorf("tx.SelectContext: %w", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, trashRepoDeleteCheckersQuery, taskIds); err != nil {
+		return nil, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	var files []file
+	if err = tx.SelectContext(ctx, &files, trashRepoPurgeFilesQuery, before, taskIds, answerIds); err != nil {
+		return nil, fmt.Errorf("tx.SelectContext: %w", err)
+	}
+
+	for _, q := range []struct {
+		query string
+		ids   []int64
+	}{
+		{query: trashRepoDetachTaskFilesQuery, ids: taskIds},
+		{query: trashRepoDetachAnswerFilesQuery, ids: answerIds},
+		{query: trashRepoDeleteMarksQuery, ids: answerIds},
+		{query: trashRepoDeleteAnswersQuery, ids: answerIds},
+		{query: trashRepoDeleteTaskLinksQuery, ids: taskIds},
+		{query: trashRepoDetachSeriesQuery, ids: taskIds},
+		{query: trashRepoDeleteSeriesQuery, ids: taskIds},
+		{query: trashRepoDeleteTasksQuery, ids: taskIds},
+		{query: trashRepoDeleteGroupLinksQuery, ids: groupIds},
+		{query: trashRepoDeleteGroupAssignmentsQuery, ids: groupIds},
+		{query: trashRepoDeleteGroupsQuery, ids: groupIds},
+	} {
+		if _, err = tx.ExecContext(ctx, q.query, q.ids); err != nil {
+			return nil, fmt.Errorf("tx.ExecContext: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("tx.Commit: %w", err)
+	}
+	return lo.Map(
+		files,
+		func(item file, _ int) models.File {
+			return item.toServiceModel()
+		},
+	), nil
+}
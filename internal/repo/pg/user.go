@@ -22,6 +22,7 @@ type user struct {
 	FirstName  string     `db:"first_name"`
 	LastName   string     `db:"last_name"`
 	MiddleName *string    `db:"middle_name"`
+	IsActive   bool       `db:"is_active"`
 	CreatedAt  time.Time  `db:"created_at"`
 	UpdatedAt  *time.Time `db:"updated_at"`
 }
@@ -36,6 +37,7 @@ func (u user) toServiceModel() models.User {
 		FirstName:  u.FirstName,
 		LastName:   u.LastName,
 		MiddleName: u.MiddleName,
+		IsActive:   u.IsActive,
 		CreatedAt:  u.CreatedAt,
 		UpdatedAt:  u.UpdatedAt,
 	}
@@ -59,6 +61,7 @@ select
     u.first_name, 
     u.last_name, 
     u.middle_name, 
+    u.is_active, 
     u.created_at, 
     u.updated_at
 from public.user u
@@ -79,21 +82,58 @@ func (r *UsersRepo) GetById(
 	return u.toServiceModel(), nil
 }
 
-const usersRepoGetListByRoleIdQuery = `
-select *
-from public.user
-where role_id = $1
-order by id desc 
-limit $2
-offset $3
+var usersRepoFilterSpec = filterSpec{
+	fields: map[string]filterField{
+		"groupId":   {column: "u.group_id", parse: parseInt64},
+		"roleId":    {column: "u.role_id", parse: parseInt64},
+		"active":    {column: "u.is_active", parse: parseBool},
+		"email":     {column: "u.email", ops: textOps, parse: parseString},
+		"createdAt": {column: "u.created_at", ops: orderedOps, parse: parseTime},
+	},
+	sortFields: map[string]string{
+		"id":        "u.id",
+		"email":     "u.email",
+		"firstName": "u.first_name",
+		"lastName":  "u.last_name",
+		"createdAt": "u.created_at",
+	},
+	searchColumn: "(u.last_name || ' ' || u.first_name || ' ' || coalesce(u.middle_name, '') || ' ' || u.email)",
+	defaultOrder: "u.id desc",
+}
+
+const usersRepoGetListQuery = `
+select 
+    u.id, 
+    u.group_id, 
+    u.role_id,
+    u.email, 
+    u.password, 
+    u.first_name, 
+    u.last_name, 
+    u.middle_name, 
+    u.is_active, 
+    u.created_at, 
+    u.updated_at
+from public.user u
+where true %s
+order by %s
+limit $%d
+offset $%d
 `
 
-func (r *UsersRepo) GetListByRoleId(
+func (r *UsersRepo) GetList(
 	ctx context.Context,
-	opts repo.UsersRepoGetListByRoleIdOpts,
+	opts repo.UsersRepoGetListOpts,
 ) ([]models.User, error) {
+	where, orderBy, args, err := usersRepoFilterSpec.build(opts.Filter, nil)
+	if err != nil {
+		return nil, fmt.Errorf("usersRepoFilterSpec.build: %w", err)
+	}
+	args = append(args, opts.Limit, opts.Offset)
+	query := fmt.Sprintf(usersRepoGetListQuery, where, orderBy, len(args)-1, len(args))
+
 	var users []user
-	if err := r.db.SelectContext(ctx, &users, usersRepoGetListByRoleIdQuery, opts.RoleId, opts.Limit, opts.Offset); err != nil {
+	if err = r.db.SelectContext(ctx, &users, query, args...); err != nil {
 		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
 	}
 	return lo.Map(
@@ -104,18 +144,23 @@ func (r *UsersRepo) GetListByRoleId(
 	), nil
 }
 
-const usersRepoGetListByRoleIdCountQuery = `
+const usersRepoGetCountQuery = `
 select count(*)
-from public.user
-where role_id = $1
+from public.user u
+where true %s
 `
 
-func (r *UsersRepo) GetListByRoleIdCount(
+func (r *UsersRepo) GetCount(
 	ctx context.Context,
-	opts repo.UsersRepoGetListByRoleIdOpts,
-) (int, error) {
-	var count int
-	if err := r.db.GetContext(ctx, &count, usersRepoGetListByRoleIdCountQuery, opts.RoleId); err != nil {
+	filter models.Filter,
+) (int64, error) {
+	where, _, args, err := usersRepoFilterSpec.build(filter, nil)
+	if err != nil {
+		return 0, fmt.Errorf("usersRepoFilterSpec.build: %w", err)
+	}
+
+	var count int64
+	if err = r.db.GetContext(ctx, &count, fmt.Sprintf(usersRepoGetCountQuery, where), args...); err != nil {
 		return 0, fmt.Errorf("r.db.GetContext: %w", err)
 	}
 	return count, nil
@@ -131,10 +176,11 @@ select
     u.first_name, 
     u.last_name, 
     u.middle_name, 
+    u.is_active, 
     u.created_at, 
     u.updated_at
 from public.user u
-where u.email = $1 and u.password = $2
+where u.email = $1 and u.password = $2 and u.is_active
 `
 
 func (r *UsersRepo) GetByCredentials(
@@ -152,8 +198,15 @@ func (r *UsersRepo) GetByCredentials(
 }
 
 const createQuery = `
-insert into public.user (group_id, role_id, email, password, first_name, last_name, middle_name) 
-values (:group_id, :role_id, :email, :password, :first_name, :last_name, :middle_name)
+with u as (
+    insert into public.user (group_id, role_id, email, password, first_name, last_name, middle_name) 
+    values (:group_id, :role_id, :email, :password, :first_name, :last_name, :middle_name)
+    returning id, group_id, created_at
+)
+insert into public.group_membership (user_id, group_id, effective_from)
+select u.id, u.group_id, u.created_at
+from u
+where u.group_id is not null
 `
 
 func (r *UsersRepo) Create(
@@ -183,3 +236,108 @@ func (r *UsersRepo) Create(
 
 	return models.User{}, nil
 }
+
+type groupMembership struct {
+	Id            int64      `db:"id"`
+	UserId        int64      `db:"user_id"`
+	GroupId       int64      `db:"group_id"`
+	GroupName     string     `db:"group_name"`
+	EffectiveFrom time.Time  `db:"effective_from"`
+	EffectiveTill *time.Time `db:"effective_till"`
+}
+
+func (m groupMembership) toServiceModel() models.GroupMembership {
+	return models.GroupMembership{
+		Id:            m.Id,
+		UserId:        m.UserId,
+		GroupId:       m.GroupId,
+		GroupName:     m.GroupName,
+		EffectiveFrom: m.EffectiveFrom,
+		EffectiveTill: m.EffectiveTill,
+	}
+}
+
+const usersRepoGetMembershipsQuery = `
+select 
+    gm.id, 
+    gm.user_id, 
+    gm.group_id, 
+    g.name group_name, 
+    gm.effective_from, 
+    gm.effective_till
+from public.group_membership gm
+join public."group" g on g.id = gm.group_id
+where gm.user_id = $1
+order by gm.effective_from desc
+`
+
+func (r *UsersRepo) GetMemberships(
+	ctx context.Context,
+	userId int64,
+) ([]models.GroupMembership, error) {
+	var memberships []groupMembership
+	if err := r.db.SelectContext(ctx, &memberships, usersRepoGetMembershipsQuery, userId); err != nil {
+		return nil, fmt.Errorf("r.db.SelectContext: %w", err)
+	}
+	return lo.Map(
+		memberships,
+		func(item groupMembership, _ int) models.GroupMembership {
+			return item.toServiceModel()
+		},
+	), nil
+}
+
+const (
+	// usersRepoLockGroupQuery makes sure the target group exists and is not
+	// deleted until the transfer commits.
+	usersRepoLockGroupQuery = `
+select g.id from public."group" g where g.id = $1 and g.deleted_at is null for share
+`
+	usersRepoCloseMembershipQuery = `
+update public.group_membership
+set effective_till = $2
+where user_id = $1 and effective_till is null
+`
+	usersRepoOpenMembershipQuery = `
+insert into public.group_membership (user_id, group_id, effective_from)
+values ($1, $2, $3)
+`
+	usersRepoUpdateGroupQuery = `
+update public.user
+set group_id = $2, updated_at = now()
+where id = $1
+`
+)
+
+func (r *UsersRepo) Transfer(
+	ctx context.Context,
+	opts repo.UsersRepoTransferOpts,
+) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("r.db.BeginTxx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var groupId int64
+	if err = tx.GetContext(ctx, &groupId, usersRepoLockGroupQuery, opts.GroupId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repo.ErrNotFound
+		}
+		return fmt.Errorf("tx.GetContext: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, usersRepoCloseMembershipQuery, opts.UserId, opts.EffectiveFrom); err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, usersRepoOpenMembershipQuery, opts.UserId, opts.GroupId, opts.EffectiveFrom); err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, usersRepoUpdateGroupQuery, opts.UserId, opts.GroupId); err != nil {
+		return fmt.Errorf("tx.ExecContext: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+	return nil
+}
@@ -1,17 +1,22 @@
 package repo
 
 import (
+	"backend/internal/models"
 	"errors"
 	"time"
 )
 
 var (
-	ErrNotFound = errors.New("not found")
+	ErrNotFound      = errors.New("not found")
+	ErrInvalidFilter = errors.New("invalid filter")
+	ErrCycle         = errors.New("dependency cycle")
+	ErrConflict      = errors.New("conflict")
+	ErrLimitExceeded = errors.New("limit exceeded")
 )
 
 type (
-	UsersRepoGetListByRoleIdOpts struct {
-		RoleId int64
+	UsersRepoGetListOpts struct {
+		Filter models.Filter
 		Limit  int64
 		Offset int64
 	}
@@ -28,6 +33,11 @@ type (
 		LastName   string
 		MiddleName *string
 	}
+	UsersRepoTransferOpts struct {
+		UserId        int64
+		GroupId       int64
+		EffectiveFrom time.Time
+	}
 )
 
 type (
@@ -37,76 +47,151 @@ type (
 		Offset  int64
 	}
 	FilesRepoCreateOpts struct {
-		Name     string
-		Filename string
-		Filepath string
-		TaskId   *int64
-		AnswerId *int64
+		Name      string
+		Filename  string
+		Filepath  string
+		TaskId    *int64
+		AnswerId  *int64
+		CreatedBy *int64
 	}
 )
 
 type (
 	AnswersRepoGetListOpts struct {
-		TaskId int64
-		Limit  int64
-		Offset int64
+		TaskId      int64
+		ScopeUserId *int64
+		Filter      models.Filter
+		Limit       int64
+		Offset      int64
 	}
 	AnswersRepoCreateOpts struct {
-		TaskId  int64
-		UserId  int64
-		Comment *string
+		TaskId      int64
+		UserId      int64
+		TeamId      *int64
+		Comment     *string
+		IsLate      bool
+		LateSeconds int64
 	}
 	AnswersRepoUpdateOpts struct {
-		Id      int64
-		Comment string
+		Id          int64
+		Comment     string
+		IsLate      bool
+		LateSeconds int64
 	}
 )
 
 type (
 	MarksRepoGetListByUserIdOpts struct {
-		UserId int64
-		Limit  int64
-		Offset int64
+		UserId      int64
+		ScopeUserId *int64
+		Filter      models.Filter
+		Limit       int64
+		Offset      int64
 	}
 	MarksRepoCreateOpts struct {
 		AnswerId int64
+		RawMark  int64
 		Mark     int64
 		Comment  *string
+		Criteria []models.MarkCriterion
 	}
 	MarksRepoUpdateOpts struct {
-		Id      int64
-		Mark    int64
-		Comment *string
+		Id       int64
+		RawMark  int64
+		Mark     int64
+		Comment  *string
+		Criteria []models.MarkCriterion
 	}
 )
 
 type (
 	TasksRepoGetListForCreatorOpts struct {
-		CreatedBy int64
-		Limit     int64
-		Offset    int64
+		CreatedBy  int64
+		IsTemplate bool
+		Filter     models.Filter
+		Limit      int64
+		Offset     int64
 	}
 	TasksRepoGetListForUserOpts struct {
 		UserId  int64
 		GroupId int64
+		Filter  models.Filter
 		Limit   int64
 		Offset  int64
 	}
+	TasksRepoGetListForScopeOpts struct {
+		ScopeUserId int64
+		Filter      models.Filter
+		Limit       int64
+		Offset      int64
+	}
 	TasksRepoCreateOpts struct {
-		CreatedBy     int64
-		Title         string
-		Text          *string
-		EffectiveFrom time.Time
-		EffectiveTill time.Time
+		CreatedBy      int64
+		Title          string
+		Text           *string
+		EffectiveFrom  time.Time
+		EffectiveTill  time.Time
+		LatePolicy     string
+		LatePenalty    int64
+		LateGraceHours int64
+		Cost           int64
+		RubricId       *int64
+		IsTemplate     bool
+		SeriesId       *int64
+		SeriesIndex    int64
+		State          string
+		PublishAt      *time.Time
+		PublishedAt    *time.Time
+		UserIds        []int64
+		GroupIds       []int64
+		FileIds        []int64
+		CopiedFiles    []models.File
 	}
 	TasksRepoUpdateOpts struct {
-		Id            int64
-		GroupId       int64
-		Title         string
-		Text          string
-		EffectiveFrom time.Time
-		EffectiveTill time.Time
-		Cost          int64
+		Id             int64
+		UpdatedBy      int64
+		Title          string
+		Text           string
+		EffectiveFrom  time.Time
+		EffectiveTill  time.Time
+		LatePolicy     string
+		LatePenalty    int64
+		LateGraceHours int64
+		Cost           int64
+		RubricId       *int64
+		UserIds        []int64
+		GroupIds       []int64
+		FileIds        []int64
+		ApplyToSeries  bool
+	}
+	TasksRepoSetStateOpts struct {
+		Id          int64
+		State       string
+		PublishAt   *time.Time
+		PublishedAt *time.Time
+	}
+)
+
+type (
+	TaskSeriesRepoCreateOpts struct {
+		SourceTaskId int64
+		RRule        string
+		NextRunAt    *time.Time
+	}
+	TaskSeriesRepoUpdateOpts struct {
+		Id        int64
+		RRule     string
+		Generated int64
+		NextRunAt *time.Time
+	}
+)
+
+type (
+	TaskPrerequisitesRepoCreateOpts struct {
+		TaskId             int64
+		PrerequisiteTaskId int64
+		Condition          string
+		MinMark            *int64
 	}
 )
 
@@ -116,27 +201,254 @@ type (
 		UserId  *int64
 		GroupId *int64
 	}
+	TaskLinksRepoSetWindowOpts struct {
+		TaskId        int64
+		UserId        *int64
+		GroupId       *int64
+		EffectiveFrom *time.Time
+		EffectiveTill *time.Time
+	}
 )
 
 type (
 	GroupsRepoGetListOpts struct {
-		Limit  int64
-		Offset int64
+		ScopeUserId *int64
+		Filter      models.Filter
+		Limit       int64
+		Offset      int64
 	}
 	GroupsRepoCreateOpts struct {
-		Name string
+		Name      string
+		OrgUnitId *int64
 	}
 	GroupsRepoUpdateOpts struct {
-		Id   int64
-		Name string
+		Id        int64
+		Name      string
+		OrgUnitId *int64
+	}
+	GroupsRepoAssignmentOpts struct {
+		UserId  int64
+		GroupId int64
+	}
+	GroupsRepoIsAccessibleOpts struct {
+		ScopeUserId int64
+		GroupIds    []int64
+		UserIds     []int64
+	}
+)
+
+type (
+	OrgUnitsRepoCreateOpts struct {
+		ParentId *int64
+		Kind     string
+		Name     string
+	}
+	OrgUnitsRepoUpdateOpts struct {
+		Id       int64
+		ParentId *int64
+		Kind     string
+		Name     string
+	}
+	OrgUnitsRepoAdminOpts struct {
+		UserId    int64
+		OrgUnitId int64
 	}
 )
 
 type (
 	GetStatisticsOpts struct {
+		ScopeUserId *int64
+		Level       string
+		Normalize   bool
+		Limit       int64
+		Offset      int64
+		From        *time.Time
+		To          *time.Time
+	}
+)
+
+type (
+	RubricsRepoGetListOpts struct {
 		Limit  int64
 		Offset int64
-		From   *time.Time
-		To     *time.Time
+	}
+	RubricsRepoCreateOpts struct {
+		Name        string
+		Description *string
+		CreatedBy   int64
+		Criteria    []models.RubricCriterion
+	}
+	RubricsRepoUpdateOpts struct {
+		Id          int64
+		Name        string
+		Description *string
+		Criteria    []models.RubricCriterion
+	}
+)
+
+type (
+	QuestionBanksRepoGetListOpts struct {
+		Limit  int64
+		Offset int64
+	}
+	QuestionBanksRepoCreateOpts struct {
+		Name        string
+		Description *string
+		CreatedBy   int64
+		Questions   []models.Question
+	}
+	QuestionBanksRepoUpdateOpts struct {
+		Id          int64
+		Name        string
+		Description *string
+		Questions   []models.Question
+	}
+)
+
+type (
+	CheckersRepoSetOpts struct {
+		TaskId      int64
+		Kind        string
+		Command     string
+		TestsFileId int64
+		TimeoutMs   int64
+		MemoryMb    int64
+	}
+	CheckersRepoClaimChecksOpts struct {
+		Limit int64
+		// StaleAfter is how long a check may stay running before it is
+		// considered abandoned and claimed again.
+		StaleAfter time.Duration
+	}
+	CheckersRepoFinishCheckOpts struct {
+		Id           int64
+		Status       string
+		ProposedMark *int64
+		Error        *string
+		Results      []models.CheckResult
+	}
+)
+
+type (
+	QuizzesRepoSetOpts struct {
+		TaskId        int64
+		BankId        int64
+		QuestionCount *int64
+		Shuffle       bool
+	}
+
+	QuizzesRepoSubmitOpts struct {
+		TaskId      int64
+		UserId      int64
+		TeamId      *int64
+		IsLate      bool
+		LateSeconds int64
+		Responses   []models.QuizResponse
+		RawMark     int64
+		Mark        int64
+	}
+)
+
+type (
+	TrashRepoGetListOpts struct {
+		// UserId is the caller, who sees the items they own.
+		UserId int64
+		// ScopeUserId is set for administrators, who also see the items
+		// of the groups and students visible to that user.
+		ScopeUserId *int64
+		Limit       int64
+		Offset      int64
+	}
+	TrashRepoRestoreOpts struct {
+		Kind        string
+		Id          int64
+		UserId      int64
+		ScopeUserId *int64
+	}
+)
+
+type (
+	TeamsRepoSetSettingsOpts struct {
+		TaskId     int64
+		MinSize    int64
+		MaxSize    int64
+		SelfSignup bool
+	}
+	TeamsRepoCreateOpts struct {
+		TaskId  int64
+		Name    string
+		UserIds []int64
+		MaxSize int64
+	}
+	TeamsRepoAddMemberOpts struct {
+		TeamId  int64
+		UserId  int64
+		MaxSize int64
+	}
+	TeamsRepoSetAdjustmentOpts struct {
+		TeamId     int64
+		UserId     int64
+		Adjustment int64
+		Comment    *string
+	}
+)
+
+type (
+	CommentsRepoGetListOpts struct {
+		TaskId   int64
+		AnswerId *int64
+		Limit    int64
+		Offset   int64
+	}
+	CommentsRepoCreateOpts struct {
+		TaskId   int64
+		AnswerId *int64
+		AuthorId int64
+		Text     string
+		FileIds  []int64
+	}
+	CommentsRepoUpdateOpts struct {
+		Id      int64
+		Text    string
+		FileIds []int64
+	}
+	CommentsRepoThreadOpts struct {
+		UserId   int64
+		TaskId   int64
+		AnswerId *int64
+	}
+	CommentsRepoGetUnreadOpts struct {
+		UserId int64
+		TaskId int64
+		// AllAnswers counts the threads on every answer to the task instead
+		// of only those on the user's own or team answers.
+		AllAnswers bool
+	}
+)
+
+type (
+	PeerReviewsRepoSetSettingsOpts struct {
+		TaskId           int64
+		ReviewsPerAnswer int64
+		RubricId         *int64
+		MarkWeight       int64
+	}
+	PeerReviewsRepoGetListOpts struct {
+		TaskId     int64
+		ReviewerId *int64
+		AnswerId   *int64
+		// SubmittedOnly leaves out reviews not written yet or rejected.
+		SubmittedOnly bool
+	}
+	PeerReviewsRepoSubmitOpts struct {
+		Id       int64
+		Score    *int64
+		Text     *string
+		Criteria []models.MarkCriterion
+	}
+	PeerReviewsRepoModerateOpts struct {
+		Id       int64
+		Rejected bool
+		Comment  *string
 	}
 )
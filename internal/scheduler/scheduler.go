@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type Job struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Scheduler runs its jobs one after another every interval until the context
+// is cancelled.
+type Scheduler struct {
+	interval time.Duration
+	jobs     []Job
+	log      *zerolog.Logger
+}
+
+func New(interval time.Duration, log *zerolog.Logger, jobs ...Job) *Scheduler {
+	return &Scheduler{
+		interval: interval,
+		jobs:     jobs,
+		log:      log,
+	}
+}
+
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runJobs(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runJobs(ctx context.Context) {
+	for _, job := range s.jobs {
+		if err := job.Run(ctx); err != nil {
+			s.log.Error().Err(err).Str("job", job.Name).Msg("Scheduled job error")
+		}
+	}
+}
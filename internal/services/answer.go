@@ -7,35 +7,56 @@ import (
 	"errors"
 	"fmt"
 	"github.com/samber/lo"
+	"time"
 
 	"github.com/rs/zerolog"
 )
 
+var (
+	ErrTaskNotStarted = errors.New("task is not available yet")
+	ErrDeadlinePassed = errors.New("task deadline has passed")
+)
+
 type AnswerService interface {
 	GetById(ctx context.Context, id int64) (models.Answer, error)
 	GetList(ctx context.Context, opts AnswerServiceGetListOpts) ([]models.Answer, error)
-	GetCount(ctx context.Context, taskId int64) (int64, error)
+	GetCount(ctx context.Context, opts AnswerServiceGetListOpts) (int64, error)
 	GetByTaskIdAndUserId(ctx context.Context, userId int64, taskId int64) (models.Answer, error)
+	BelongsTo(ctx context.Context, answer models.Answer, userId int64) (bool, error)
+	CanView(ctx context.Context, answer models.Answer, userId int64, role int) (bool, error)
+	Prepare(ctx context.Context, opts AnswerServiceCreateOpts) (models.Answer, error)
 	Create(ctx context.Context, opts AnswerServiceCreateOpts) (models.Answer, error)
 	Update(ctx context.Context, opts AnswerServiceUpdateOpts) (models.Answer, error)
 	Delete(ctx context.Context, id int64) error
 }
 
 type AnswerServiceImpl struct {
-	repo         repo.AnswersRepo
-	filesService FileService
-	log          *zerolog.Logger
+	repo                repo.AnswersRepo
+	filesService        FileService
+	taskService         TaskService
+	prerequisiteService TaskPrerequisiteService
+	checkerService      CheckerService
+	teamService         TeamService
+	log                 *zerolog.Logger
 }
 
 func NewAnswerServiceImpl(
 	repo repo.AnswersRepo,
 	filesService FileService,
+	taskService TaskService,
+	prerequisiteService TaskPrerequisiteService,
+	checkerService CheckerService,
+	teamService TeamService,
 	log *zerolog.Logger,
 ) *AnswerServiceImpl {
 	return &AnswerServiceImpl{
-		repo:         repo,
-		filesService: filesService,
-		log:          log,
+		repo:                repo,
+		filesService:        filesService,
+		taskService:         taskService,
+		prerequisiteService: prerequisiteService,
+		checkerService:      checkerService,
+		teamService:         teamService,
+		log:                 log,
 	}
 }
 
@@ -55,9 +76,11 @@ func (s *AnswerServiceImpl) GetList(
 	opts AnswerServiceGetListOpts,
 ) ([]models.Answer, error) {
 	answers, err := s.repo.GetList(ctx, repo.AnswersRepoGetListOpts{
-		TaskId: opts.TaskId,
-		Limit:  opts.Limit,
-		Offset: opts.Offset,
+		TaskId:      opts.TaskId,
+		ScopeUserId: opts.ScopeUserId,
+		Filter:      opts.Filter,
+		Limit:       opts.Limit,
+		Offset:      opts.Offset,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("s.repo.GetList: %w", err)
@@ -77,25 +100,117 @@ func (s *AnswerServiceImpl) GetByTaskIdAndUserId(
 	return answer, nil
 }
 
+// BelongsTo tells whether the answer is the user's own or the answer of the
+// user's team.
+func (s *AnswerServiceImpl) BelongsTo(
+	ctx context.Context,
+	answer models.Answer,
+	userId int64,
+) (bool, error) {
+	if answer.UserId == userId {
+		return true, nil
+	}
+	if answer.TeamId == nil {
+		return false, nil
+	}
+
+	team, err := s.teamService.GetByUser(ctx, answer.TaskId, userId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("s.teamService.GetByUser: %w", err)
+	}
+	return team.Id == *answer.TeamId, nil
+}
+
+// CanView tells whether the user may read the answer: students their own and
+// team answers, staff the answers of the students they see.
+func (s *AnswerServiceImpl) CanView(
+	ctx context.Context,
+	answer models.Answer,
+	userId int64,
+	role int,
+) (bool, error) {
+	if role == models.UserRoleStudent {
+		return s.BelongsTo(ctx, answer, userId)
+	}
+
+	visible, err := s.repo.IsVisible(ctx, answer.Id, userId)
+	if err != nil {
+		return false, fmt.Errorf("s.repo.IsVisible: %w", err)
+	}
+	return visible, nil
+}
+
 func (s *AnswerServiceImpl) GetCount(
 	ctx context.Context,
-	taskId int64,
+	opts AnswerServiceGetListOpts,
 ) (int64, error) {
-	count, err := s.repo.GetCount(ctx, taskId)
+	count, err := s.repo.GetCount(ctx, repo.AnswersRepoGetListOpts{
+		TaskId:      opts.TaskId,
+		ScopeUserId: opts.ScopeUserId,
+		Filter:      opts.Filter,
+	})
 	if err != nil {
 		return 0, fmt.Errorf("s.repo.GetCount: %w", err)
 	}
 	return count, nil
 }
 
+// Prepare checks that the task is assigned to the user, who may submit an
+// answer to it now, and returns the answer Create would store, without
+// storing it.
+func (s *AnswerServiceImpl) Prepare(
+	ctx context.Context,
+	opts AnswerServiceCreateOpts,
+) (models.Answer, error) {
+	assigned, err := s.taskService.IsAssigned(ctx, opts.TaskId, opts.UserId)
+	if err != nil {
+		return models.Answer{}, fmt.Errorf("s.taskService.IsAssigned: %w", err)
+	}
+	if !assigned {
+		return models.Answer{}, ErrNotTaskAssignee
+	}
+
+	late, err := s.submissionLateness(ctx, opts.TaskId, opts.UserId)
+	if err != nil {
+		return models.Answer{}, fmt.Errorf("s.submissionLateness: %w", err)
+	}
+	if err = s.prerequisiteService.CheckUnlocked(ctx, opts.TaskId, opts.UserId); err != nil {
+		return models.Answer{}, fmt.Errorf("s.prerequisiteService.CheckUnlocked: %w", err)
+	}
+	teamId, err := s.teamService.GetSubmittingTeam(ctx, opts.TaskId, opts.UserId)
+	if err != nil {
+		return models.Answer{}, fmt.Errorf("s.teamService.GetSubmittingTeam: %w", err)
+	}
+
+	return models.Answer{
+		TaskId:      opts.TaskId,
+		UserId:      opts.UserId,
+		TeamId:      teamId,
+		Comment:     opts.Comment,
+		IsLate:      late > 0,
+		LateSeconds: int64(late.Seconds()),
+	}, nil
+}
+
 func (s *AnswerServiceImpl) Create(
 	ctx context.Context,
 	opts AnswerServiceCreateOpts,
 ) (models.Answer, error) {
+	prepared, err := s.Prepare(ctx, opts)
+	if err != nil {
+		return models.Answer{}, fmt.Errorf("s.Prepare: %w", err)
+	}
+
 	answer, err := s.repo.Create(ctx, repo.AnswersRepoCreateOpts{
-		TaskId:  opts.TaskId,
-		UserId:  opts.UserId,
-		Comment: opts.Comment,
+		TaskId:      prepared.TaskId,
+		UserId:      prepared.UserId,
+		TeamId:      prepared.TeamId,
+		Comment:     prepared.Comment,
+		IsLate:      prepared.IsLate,
+		LateSeconds: prepared.LateSeconds,
 	})
 	if err != nil {
 		return models.Answer{}, fmt.Errorf("s.repo.Create: %w", err)
@@ -107,6 +222,12 @@ func (s *AnswerServiceImpl) Create(
 		}
 	}
 
+	// The answer is already stored, so a failure to queue its check must not
+	// turn the submission into an error.
+	if err = s.checkerService.Enqueue(ctx, answer.Id, answer.TaskId); err != nil {
+		s.log.Error().Err(err).Int64("answer_id", answer.Id).Msg("Enqueue answer check error")
+	}
+
 	return answer, nil
 }
 
@@ -114,10 +235,25 @@ func (s *AnswerServiceImpl) Update(
 	ctx context.Context,
 	opts AnswerServiceUpdateOpts,
 ) (models.Answer, error) {
+	current, err := s.repo.GetById(ctx, opts.Id)
+	if err != nil {
+		return models.Answer{}, fmt.Errorf("s.repo.GetById: %w", err)
+	}
+
+	late, err := s.submissionLateness(ctx, current.TaskId, current.UserId)
+	if err != nil {
+		return models.Answer{}, fmt.Errorf("s.submissionLateness: %w", err)
+	}
+
 	answer, err := s.repo.Update(ctx, repo.AnswersRepoUpdateOpts{
-		Id:      opts.Id,
-		Comment: opts.Comment,
+		Id:          opts.Id,
+		Comment:     opts.Comment,
+		IsLate:      late > 0,
+		LateSeconds: int64(late.Seconds()),
 	})
+	if err != nil {
+		return models.Answer{}, fmt.Errorf("s.repo.Update: %w", err)
+	}
 
 	files, err := s.filesService.GetByAnswerId(ctx, opts.Id)
 	if err != nil {
@@ -142,30 +278,49 @@ func (s *AnswerServiceImpl) Update(
 		}
 	}
 
-	if err != nil {
-		return models.Answer{}, fmt.Errorf("s.repo.Update: %w", err)
-	}
 	return answer, nil
 }
 
-func (s *AnswerServiceImpl) Delete(
+// submissionLateness returns how late a submission of the user made now would
+// be, or an error when the task's late policy does not accept it. The user's
+// deadline overrides are taken into account.
+func (s *AnswerServiceImpl) submissionLateness(
 	ctx context.Context,
-	id int64,
-) error {
-	files, err := s.filesService.GetByAnswerId(ctx, id)
+	taskId, userId int64,
+) (time.Duration, error) {
+	task, err := s.taskService.GetByIdForUser(ctx, taskId, userId)
 	if err != nil {
-		if !errors.Is(err, repo.ErrNotFound) {
-			return fmt.Errorf("s.filesService.GetByAnswerId: %w", err)
-		}
+		return 0, fmt.Errorf("s.taskService.GetByIdForUser: %w", err)
 	}
 
-	for _, file := range files {
-		if err = s.filesService.Delete(ctx, file.Id); err != nil {
-			return fmt.Errorf("s.filesService.Delete: %w", err)
+	now := time.Now()
+	if task.IsTemplate || task.State != models.TaskStatePublished || now.Before(task.EffectiveFrom) {
+		return 0, ErrTaskNotStarted
+	}
+
+	late := now.Sub(task.EffectiveTill)
+	if late <= 0 {
+		return 0, nil
+	}
+
+	switch task.LatePolicy {
+	case models.LatePolicyFixed, models.LatePolicyPercentPerDay:
+		return late, nil
+	case models.LatePolicyGrace:
+		if late <= time.Duration(task.LateGraceHours)*time.Hour {
+			return late, nil
 		}
 	}
+	return 0, ErrDeadlinePassed
+}
 
-	if err = s.repo.Delete(ctx, id); err != nil {
+func (s *AnswerServiceImpl) Delete(
+	ctx context.Context,
+	id int64,
+) error {
+	// The answer is moved to the trash; its files stay attached and are
+	// hidden with it, so that a restore brings them back.
+	if err := s.repo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("s.repo.Delete: %w", err)
 	}
 	return nil
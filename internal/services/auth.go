@@ -57,16 +57,7 @@ func (s *AuthServiceImpl) SignIn(
 		return models.JWTPair{}, fmt.Errorf("s.userService.GetByCredentials: %w", err)
 	}
 
-	customClaims := map[string]any{
-		"sub":  user.Id,
-		"name": user.FullName(),
-		"grp":  nil,
-		"role": user.RoleId,
-	}
-	if user.GroupId != nil {
-		customClaims["grp"] = *user.GroupId
-	}
-	jwtPair, err := s.getJWTPair(ctx, customClaims)
+	jwtPair, err := s.getJWTPair(ctx, userClaims(user))
 	if err != nil {
 		return models.JWTPair{}, fmt.Errorf("s.getJWTPair: %w", err)
 	}
@@ -107,12 +98,12 @@ func (s *AuthServiceImpl) Refresh(
 		return models.JWTPair{}, ErrNotFoundRefreshToken
 	}
 
-	jwtPair, err := s.getJWTPair(ctx, map[string]any{
-		"sub":  claims["sub"],
-		"name": claims["name"],
-		"grp":  claims["grp"],
-		"role": claims["role"],
-	})
+	user, err := s.userService.GetById(ctx, int64(userId))
+	if err != nil {
+		return models.JWTPair{}, fmt.Errorf("s.userService.GetById: %w", err)
+	}
+
+	jwtPair, err := s.getJWTPair(ctx, userClaims(user))
 	if err != nil {
 		return models.JWTPair{}, fmt.Errorf("s.getJWTPair: %w", err)
 	}
@@ -124,6 +115,21 @@ func (s *AuthServiceImpl) Refresh(
 	return jwtPair, nil
 }
 
+// userClaims is rebuilt from the stored user on every refresh, so group
+// transfers and role changes reach the client without signing in again.
+func userClaims(user models.User) map[string]any {
+	claims := map[string]any{
+		"sub":  user.Id,
+		"name": user.FullName(),
+		"grp":  nil,
+		"role": user.RoleId,
+	}
+	if user.GroupId != nil {
+		claims["grp"] = *user.GroupId
+	}
+	return claims
+}
+
 func (s *AuthServiceImpl) RefreshTokenExpTime() time.Duration {
 	return s.jwtConfig.JWTRefreshExpirationTime
 }
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"backend/pkg/ical"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// calendarFeedLimit caps the number of tasks put in a feed.
+const calendarFeedLimit = 1000
+
+const calendarProdId = "-//backend//Task deadlines//EN"
+
+type CalendarService interface {
+	GetToken(ctx context.Context, userId int64) (string, error)
+	ResetToken(ctx context.Context, userId int64) (string, error)
+	GetFeed(ctx context.Context, token string) ([]byte, error)
+}
+
+type CalendarServiceImpl struct {
+	repo            repo.CalendarRepo
+	taskService     TaskService
+	userService     UserService
+	taskURL         string
+	gradingPeriod   time.Duration
+	refreshInterval time.Duration
+	log             *zerolog.Logger
+}
+
+func NewCalendarServiceImpl(
+	repo repo.CalendarRepo,
+	taskService TaskService,
+	userService UserService,
+	taskURL string,
+	gradingPeriod time.Duration,
+	refreshInterval time.Duration,
+	log *zerolog.Logger,
+) *CalendarServiceImpl {
+	return &CalendarServiceImpl{
+		repo:            repo,
+		taskService:     taskService,
+		userService:     userService,
+		taskURL:         taskURL,
+		gradingPeriod:   gradingPeriod,
+		refreshInterval: refreshInterval,
+		log:             log,
+	}
+}
+
+// GetToken returns the user's feed token, issuing one on first use.
+func (s *CalendarServiceImpl) GetToken(
+	ctx context.Context,
+	userId int64,
+) (string, error) {
+	token, err := s.repo.GetToken(ctx, userId)
+	if err == nil {
+		return token, nil
+	}
+	if !errors.Is(err, repo.ErrNotFound) {
+		return "", fmt.Errorf("s.repo.GetToken: %w", err)
+	}
+	return s.ResetToken(ctx, userId)
+}
+
+// ResetToken issues a new feed token, so that the feed URL given out before
+// stops working.
+func (s *CalendarServiceImpl) ResetToken(
+	ctx context.Context,
+	userId int64,
+) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+	token := hex.EncodeToString(b)
+
+	if err := s.repo.SetToken(ctx, userId, token); err != nil {
+		return "", fmt.Errorf("s.repo.SetToken: %w", err)
+	}
+	return token, nil
+}
+
+// GetFeed builds the calendar behind the token. Students get the tasks
+// assigned to them, with the dates that apply to them and their status.
+// Staff get the tasks they created, with the date grading is due by.
+func (s *CalendarServiceImpl) GetFeed(
+	ctx context.Context,
+	token string,
+) ([]byte, error) {
+	userId, err := s.repo.GetUserIdByToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetUserIdByToken: %w", err)
+	}
+	user, err := s.userService.GetById(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("s.userService.GetById: %w", err)
+	}
+	if !user.IsActive {
+		return nil, repo.ErrNotFound
+	}
+
+	calendar := ical.Calendar{
+		ProdId:          calendarProdId,
+		Name:            "Deadlines: " + user.FullName(),
+		RefreshInterval: s.refreshInterval,
+	}
+
+	if user.RoleId == models.UserRoleStudent {
+		var groupId int64
+		if user.GroupId != nil {
+			groupId = *user.GroupId
+		}
+		tasks, err := s.taskService.GetListForUser(ctx, TaskServiceGetListForUserOpts{
+			UserId:  user.Id,
+			GroupId: groupId,
+			Limit:   calendarFeedLimit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("s.taskService.GetListForUser: %w", err)
+		}
+		for _, task := range tasks {
+			calendar.Events = append(calendar.Events, s.studentEvents(task)...)
+		}
+		return calendar.Marshal(), nil
+	}
+
+	tasks, err := s.taskService.GetListForCreator(ctx, TaskServiceGetListForCreatorOpts{
+		CreatedBy: user.Id,
+		Limit:     calendarFeedLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.taskService.GetListForCreator: %w", err)
+	}
+	for _, task := range tasks {
+		calendar.Events = append(calendar.Events, s.creatorEvents(task)...)
+	}
+	return calendar.Marshal(), nil
+}
+
+func (s *CalendarServiceImpl) studentEvents(task models.UserTask) []ical.Event {
+	description := []string{"Status: " + strings.ReplaceAll(task.Status, "_", " ")}
+	if task.Mark != nil {
+		description = append(description, fmt.Sprintf("Mark: %d / %d", *task.Mark, task.Cost))
+	}
+	return s.taskEvents(task.Task, description, []string{task.Status})
+}
+
+func (s *CalendarServiceImpl) creatorEvents(task models.Task) []ical.Event {
+	description := []string{"State: " + task.State}
+	events := s.taskEvents(task, description, []string{task.State})
+
+	gradingDue := task.EffectiveTill.Add(s.gradingPeriod)
+	events = append(events, s.event(task, "grading", "Grading due: "+task.Title, gradingDue, description, []string{task.State}))
+	return events
+}
+
+// taskEvents turns the task window into an opening and a deadline event.
+func (s *CalendarServiceImpl) taskEvents(task models.Task, description, categories []string) []ical.Event {
+	return []ical.Event{
+		s.event(task, "open", "Opens: "+task.Title, task.EffectiveFrom, description, categories),
+		s.event(task, "due", "Due: "+task.Title, task.EffectiveTill, description, categories),
+	}
+}
+
+func (s *CalendarServiceImpl) event(
+	task models.Task,
+	kind, summary string,
+	at time.Time,
+	description, categories []string,
+) ical.Event {
+	event := ical.Event{
+		UID:          fmt.Sprintf("task-%d-%s@backend", task.Id, kind),
+		Summary:      summary,
+		Start:        at,
+		End:          at,
+		LastModified: task.CreatedAt,
+		Categories:   categories,
+	}
+	if task.UpdatedAt != nil {
+		event.LastModified = *task.UpdatedAt
+	}
+	if s.taskURL != "" {
+		event.URL = fmt.Sprintf(s.taskURL, task.Id)
+		description = append(description[:len(description):len(description)], event.URL)
+	}
+	event.Description = strings.Join(description, "\n")
+	return event
+}
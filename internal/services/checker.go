@@ -0,0 +1,258 @@
+package services
+
+import (
+	"backend/internal/checker"
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+)
+
+const (
+	defaultCheckTimeoutMs = 2000
+	defaultCheckMemoryMb  = 256
+)
+
+var ErrInvalidChecker = errors.New("invalid checker")
+
+type CheckerService interface {
+	GetByTaskId(ctx context.Context, taskId int64) (models.TaskChecker, error)
+	Set(ctx context.Context, opts CheckerServiceSetOpts) (models.TaskChecker, error)
+	Delete(ctx context.Context, taskId int64) error
+	GetCheck(ctx context.Context, answerId int64) (models.AnswerCheck, error)
+	Enqueue(ctx context.Context, answerId, taskId int64) error
+	RunPending(ctx context.Context) error
+}
+
+type CheckerServiceImpl struct {
+	repo        repo.CheckersRepo
+	checkers    map[string]checker.Checker
+	fileService FileService
+	taskService TaskService
+	batchSize   int64
+	staleAfter  time.Duration
+	log         *zerolog.Logger
+}
+
+func NewCheckerServiceImpl(
+	repo repo.CheckersRepo,
+	checkers map[string]checker.Checker,
+	fileService FileService,
+	taskService TaskService,
+	batchSize int64,
+	staleAfter time.Duration,
+	log *zerolog.Logger,
+) *CheckerServiceImpl {
+	return &CheckerServiceImpl{
+		repo:        repo,
+		checkers:    checkers,
+		fileService: fileService,
+		taskService: taskService,
+		batchSize:   batchSize,
+		staleAfter:  staleAfter,
+		log:         log,
+	}
+}
+
+func (s *CheckerServiceImpl) GetByTaskId(
+	ctx context.Context,
+	taskId int64,
+) (models.TaskChecker, error) {
+	taskChecker, err := s.repo.GetByTaskId(ctx, taskId)
+	if err != nil {
+		return models.TaskChecker{}, fmt.Errorf("s.repo.GetByTaskId: %w", err)
+	}
+	return taskChecker, nil
+}
+
+// Set adds or replaces the checker of the task. The tests must come from a
+// file attached to the task or uploaded by the editor.
+func (s *CheckerServiceImpl) Set(
+	ctx context.Context,
+	opts CheckerServiceSetOpts,
+) (models.TaskChecker, error) {
+	if _, ok := s.checkers[opts.Kind]; !ok || strings.TrimSpace(opts.Command) == "" {
+		return models.TaskChecker{}, ErrInvalidChecker
+	}
+	if opts.TimeoutMs < 0 || opts.MemoryMb < 0 {
+		return models.TaskChecker{}, ErrInvalidChecker
+	}
+
+	if _, err := s.taskService.GetById(ctx, opts.TaskId); err != nil {
+		return models.TaskChecker{}, fmt.Errorf("s.taskService.GetById: %w", err)
+	}
+	tests, err := s.fileService.GetById(ctx, opts.TestsFileId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return models.TaskChecker{}, ErrInvalidChecker
+		}
+		return models.TaskChecker{}, fmt.Errorf("s.fileService.GetById: %w", err)
+	}
+	attached := tests.TaskId != nil && *tests.TaskId == opts.TaskId
+	uploaded := tests.CreatedBy != nil && *tests.CreatedBy == opts.EditorId
+	if !attached && !uploaded {
+		return models.TaskChecker{}, fmt.Errorf("%w: tests file is neither attached to the task nor uploaded by the editor", ErrInvalidChecker)
+	}
+
+	taskChecker, err := s.repo.Set(ctx, repo.CheckersRepoSetOpts{
+		TaskId:      opts.TaskId,
+		Kind:        opts.Kind,
+		Command:     opts.Command,
+		TestsFileId: opts.TestsFileId,
+		TimeoutMs:   lo.Ternary(opts.TimeoutMs == 0, defaultCheckTimeoutMs, opts.TimeoutMs),
+		MemoryMb:    lo.Ternary(opts.MemoryMb == 0, defaultCheckMemoryMb, opts.MemoryMb),
+	})
+	if err != nil {
+		return models.TaskChecker{}, fmt.Errorf("s.repo.Set: %w", err)
+	}
+	return taskChecker, nil
+}
+
+func (s *CheckerServiceImpl) Delete(
+	ctx context.Context,
+	taskId int64,
+) error {
+	if err := s.repo.Delete(ctx, taskId); err != nil {
+		return fmt.Errorf("s.repo.Delete: %w", err)
+	}
+	return nil
+}
+
+func (s *CheckerServiceImpl) GetCheck(
+	ctx context.Context,
+	answerId int64,
+) (models.AnswerCheck, error) {
+	check, err := s.repo.GetLatestCheck(ctx, answerId)
+	if err != nil {
+		return models.AnswerCheck{}, fmt.Errorf("s.repo.GetLatestCheck: %w", err)
+	}
+	return check, nil
+}
+
+// Enqueue schedules a check of the answer when its task has a checker; the
+// check itself runs later in RunPending.
+func (s *CheckerServiceImpl) Enqueue(
+	ctx context.Context,
+	answerId, taskId int64,
+) error {
+	if _, err := s.repo.GetByTaskId(ctx, taskId); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("s.repo.GetByTaskId: %w", err)
+	}
+
+	if _, err := s.repo.CreateCheck(ctx, answerId); err != nil {
+		return fmt.Errorf("s.repo.CreateCheck: %w", err)
+	}
+	return nil
+}
+
+// RunPending claims a batch of queued checks and runs them one by one. A
+// check that cannot be carried out is stored as failed rather than retried.
+func (s *CheckerServiceImpl) RunPending(ctx context.Context) error {
+	checks, err := s.repo.ClaimChecks(ctx, repo.CheckersRepoClaimChecksOpts{
+		Limit:      s.batchSize,
+		StaleAfter: s.staleAfter,
+	})
+	if err != nil {
+		return fmt.Errorf("s.repo.ClaimChecks: %w", err)
+	}
+
+	for _, check := range checks {
+		opts := repo.CheckersRepoFinishCheckOpts{
+			Id:     check.Id,
+			Status: models.AnswerCheckDone,
+		}
+
+		opts.Results, opts.ProposedMark, err = s.run(ctx, check)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			s.log.Error().Err(err).Int64("check_id", check.Id).Msg("Answer check failed")
+			opts.Status = models.AnswerCheckFailed
+			opts.Error = lo.ToPtr(err.Error())
+		}
+
+		if err = s.repo.FinishCheck(ctx, opts); err != nil {
+			return fmt.Errorf("s.repo.FinishCheck: %w", err)
+		}
+	}
+	return nil
+}
+
+// run checks the answer files against the task's tests and proposes a mark
+// proportional to the share of passed tests.
+func (s *CheckerServiceImpl) run(
+	ctx context.Context,
+	check models.AnswerCheck,
+) ([]models.CheckResult, *int64, error) {
+	taskChecker, err := s.repo.GetByTaskId(ctx, check.TaskId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("s.repo.GetByTaskId: %w", err)
+	}
+	impl, ok := s.checkers[taskChecker.Kind]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidChecker, taskChecker.Kind)
+	}
+
+	files, err := s.fileService.GetByAnswerId(ctx, check.AnswerId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("s.fileService.GetByAnswerId: %w", err)
+	}
+	tests, err := s.fileService.GetById(ctx, taskChecker.TestsFileId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("s.fileService.GetById: %w", err)
+	}
+
+	results, err := impl.Check(ctx, checker.Request{
+		Command: taskChecker.Command,
+		Submission: lo.Map(files, func(item models.File, _ int) checker.File {
+			return checkerFile(item)
+		}),
+		Tests:       checkerFile(tests),
+		Timeout:     time.Duration(taskChecker.TimeoutMs) * time.Millisecond,
+		MemoryLimit: taskChecker.MemoryMb << 20,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("impl.Check: %w", err)
+	}
+	if len(results) == 0 {
+		return nil, nil, checker.ErrNoTests
+	}
+
+	task, err := s.taskService.GetById(ctx, check.TaskId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("s.taskService.GetById: %w", err)
+	}
+
+	passed := lo.CountBy(results, func(item checker.TestResult) bool {
+		return item.Status == checker.StatusPassed
+	})
+	mark := int64(math.Round(float64(passed) * float64(task.Cost) / float64(len(results))))
+
+	return lo.Map(results, func(item checker.TestResult, _ int) models.CheckResult {
+		return models.CheckResult{
+			Name:       item.Name,
+			Status:     item.Status,
+			DurationMs: item.Duration.Milliseconds(),
+			Output:     lo.EmptyableToPtr(item.Output),
+		}
+	}), &mark, nil
+}
+
+func checkerFile(file models.File) checker.File {
+	return checker.File{
+		Path: filepath.Join(file.Filepath, file.Filename),
+		Name: file.Name,
+	}
+}
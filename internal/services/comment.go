@@ -0,0 +1,175 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+var ErrInvalidComment = errors.New("invalid comment")
+
+type CommentService interface {
+	GetById(ctx context.Context, id int64) (models.Comment, error)
+	GetList(ctx context.Context, opts CommentServiceGetListOpts) ([]models.Comment, error)
+	GetCount(ctx context.Context, opts CommentServiceGetListOpts) (int64, error)
+	Create(ctx context.Context, opts CommentServiceCreateOpts) (models.Comment, error)
+	Update(ctx context.Context, opts CommentServiceUpdateOpts) (models.Comment, error)
+	Delete(ctx context.Context, id int64) error
+	MarkRead(ctx context.Context, opts CommentServiceThreadOpts) error
+	GetUnread(ctx context.Context, opts CommentServiceGetUnreadOpts) ([]models.CommentUnread, error)
+}
+
+type CommentServiceImpl struct {
+	repo          repo.CommentsRepo
+	answerService AnswerService
+	log           *zerolog.Logger
+}
+
+func NewCommentServiceImpl(
+	repo repo.CommentsRepo,
+	answerService AnswerService,
+	log *zerolog.Logger,
+) *CommentServiceImpl {
+	return &CommentServiceImpl{
+		repo:          repo,
+		answerService: answerService,
+		log:           log,
+	}
+}
+
+func (s *CommentServiceImpl) GetById(
+	ctx context.Context,
+	id int64,
+) (models.Comment, error) {
+	comment, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	return comment, nil
+}
+
+func (s *CommentServiceImpl) GetList(
+	ctx context.Context,
+	opts CommentServiceGetListOpts,
+) ([]models.Comment, error) {
+	comments, err := s.repo.GetList(ctx, repo.CommentsRepoGetListOpts{
+		TaskId:   opts.TaskId,
+		AnswerId: opts.AnswerId,
+		Limit:    opts.Limit,
+		Offset:   opts.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetList: %w", err)
+	}
+	return comments, nil
+}
+
+func (s *CommentServiceImpl) GetCount(
+	ctx context.Context,
+	opts CommentServiceGetListOpts,
+) (int64, error) {
+	count, err := s.repo.GetCount(ctx, repo.CommentsRepoGetListOpts{
+		TaskId:   opts.TaskId,
+		AnswerId: opts.AnswerId,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("s.repo.GetCount: %w", err)
+	}
+	return count, nil
+}
+
+// Create adds the comment to its thread. A comment on an answer always goes
+// to the answer's task, whatever task id was passed.
+func (s *CommentServiceImpl) Create(
+	ctx context.Context,
+	opts CommentServiceCreateOpts,
+) (models.Comment, error) {
+	opts.Text = strings.TrimSpace(opts.Text)
+	if opts.Text == "" {
+		return models.Comment{}, fmt.Errorf("%w: text is required", ErrInvalidComment)
+	}
+
+	if opts.AnswerId != nil {
+		answer, err := s.answerService.GetById(ctx, *opts.AnswerId)
+		if err != nil {
+			return models.Comment{}, fmt.Errorf("s.answerService.GetById: %w", err)
+		}
+		opts.TaskId = answer.TaskId
+	}
+
+	comment, err := s.repo.Create(ctx, repo.CommentsRepoCreateOpts{
+		TaskId:   opts.TaskId,
+		AnswerId: opts.AnswerId,
+		AuthorId: opts.AuthorId,
+		Text:     opts.Text,
+		FileIds:  opts.FileIds,
+	})
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("s.repo.Create: %w", err)
+	}
+	return comment, nil
+}
+
+func (s *CommentServiceImpl) Update(
+	ctx context.Context,
+	opts CommentServiceUpdateOpts,
+) (models.Comment, error) {
+	opts.Text = strings.TrimSpace(opts.Text)
+	if opts.Text == "" {
+		return models.Comment{}, fmt.Errorf("%w: text is required", ErrInvalidComment)
+	}
+
+	comment, err := s.repo.Update(ctx, repo.CommentsRepoUpdateOpts{
+		Id:      opts.Id,
+		Text:    opts.Text,
+		FileIds: opts.FileIds,
+	})
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("s.repo.Update: %w", err)
+	}
+	return comment, nil
+}
+
+func (s *CommentServiceImpl) Delete(
+	ctx context.Context,
+	id int64,
+) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("s.repo.Delete: %w", err)
+	}
+	return nil
+}
+
+func (s *CommentServiceImpl) MarkRead(
+	ctx context.Context,
+	opts CommentServiceThreadOpts,
+) error {
+	if err := s.repo.MarkRead(ctx, repo.CommentsRepoThreadOpts{
+		UserId:   opts.UserId,
+		TaskId:   opts.TaskId,
+		AnswerId: opts.AnswerId,
+	}); err != nil {
+		return fmt.Errorf("s.repo.MarkRead: %w", err)
+	}
+	return nil
+}
+
+func (s *CommentServiceImpl) GetUnread(
+	ctx context.Context,
+	opts CommentServiceGetUnreadOpts,
+) ([]models.CommentUnread, error) {
+	unread, err := s.repo.GetUnread(ctx, repo.CommentsRepoGetUnreadOpts{
+		UserId:     opts.UserId,
+		TaskId:     opts.TaskId,
+		AllAnswers: opts.AllAnswers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetUnread: %w", err)
+	}
+	return unread, nil
+}
@@ -5,6 +5,11 @@ import (
 	"backend/internal/repo"
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
 
 	"github.com/rs/zerolog"
 )
@@ -72,11 +77,12 @@ func (s *FileServiceImpl) Create(
 	opts FileServiceCreateOpts,
 ) (models.File, error) {
 	file, err := s.repo.Create(ctx, repo.FilesRepoCreateOpts{
-		Name:     opts.Name,
-		Filename: opts.Filename,
-		Filepath: opts.Filepath,
-		TaskId:   opts.TaskId,
-		AnswerId: opts.AnswerId,
+		Name:      opts.Name,
+		Filename:  opts.Filename,
+		Filepath:  opts.Filepath,
+		TaskId:    opts.TaskId,
+		AnswerId:  opts.AnswerId,
+		CreatedBy: opts.CreatedBy,
 	})
 	if err != nil {
 		return models.File{}, fmt.Errorf("s.repo.Create: %w", err)
@@ -84,6 +90,55 @@ func (s *FileServiceImpl) Create(
 	return file, nil
 }
 
+// copyStored duplicates the stored content of the file under a new name and
+// returns the file pointing to the copy, without saving it.
+func copyStored(file models.File) (models.File, error) {
+	filename := uuid.Must(uuid.NewV7()).String() + filepath.Ext(file.Filename)
+	if err := copyFile(
+		filepath.Join(file.Filepath, file.Filename),
+		filepath.Join(file.Filepath, filename),
+	); err != nil {
+		return models.File{}, fmt.Errorf("copyFile: %w", err)
+	}
+	file.Filename = filename
+	return file, nil
+}
+
+// removeStored deletes copies that did not make it into the database.
+func removeStored(files []models.File) {
+	for _, file := range files {
+		_ = os.Remove(filepath.Join(file.Filepath, file.Filename))
+	}
+}
+
+// copyFile copies src to dst, leaving no partial dst behind on failure.
+func copyFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("os.Open: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("os.Create: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(dst)
+		}
+	}()
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("io.Copy: %w", err)
+	}
+	if err = out.Close(); err != nil {
+		return fmt.Errorf("out.Close: %w", err)
+	}
+	return nil
+}
+
 func (s *FileServiceImpl) UpdateAnswerId(
 	ctx context.Context,
 	answerId int64,
@@ -4,32 +4,47 @@ import (
 	"backend/internal/models"
 	"backend/internal/repo"
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/rs/zerolog"
 )
 
+var (
+	ErrGroupNotAssigned = errors.New("group is not assigned to user")
+	ErrInvalidAssignee  = errors.New("invalid assignee")
+)
+
 type GroupService interface {
 	GetById(ctx context.Context, id int64) (models.Group, error)
 	GetList(ctx context.Context, opts GroupServiceGetListOpts) ([]models.Group, error)
-	GetCount(ctx context.Context) (int64, error)
+	GetCount(ctx context.Context, opts GroupServiceGetListOpts) (int64, error)
 	Create(ctx context.Context, opts GroupServiceCreateOpts) (models.Group, error)
 	Update(ctx context.Context, opts GroupServiceUpdateOpts) (models.Group, error)
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, opts GroupServiceDeleteOpts) error
+	Assign(ctx context.Context, opts GroupServiceAssignmentOpts) error
+	Unassign(ctx context.Context, opts GroupServiceAssignmentOpts) error
+	CheckAccess(ctx context.Context, opts GroupServiceCheckAccessOpts) error
 }
 
 type GroupServiceImpl struct {
-	repo repo.GroupsRepo
-	log  *zerolog.Logger
+	repo           repo.GroupsRepo
+	userService    UserService
+	orgUnitService OrgUnitService
+	log            *zerolog.Logger
 }
 
 func NewGroupServiceImpl(
 	repo repo.GroupsRepo,
+	userService UserService,
+	orgUnitService OrgUnitService,
 	log *zerolog.Logger,
 ) *GroupServiceImpl {
 	return &GroupServiceImpl{
-		repo: repo,
-		log:  log,
+		repo:           repo,
+		userService:    userService,
+		orgUnitService: orgUnitService,
+		log:            log,
 	}
 }
 
@@ -49,8 +64,10 @@ func (s *GroupServiceImpl) GetList(
 	opts GroupServiceGetListOpts,
 ) ([]models.Group, error) {
 	groups, err := s.repo.GetList(ctx, repo.GroupsRepoGetListOpts{
-		Limit:  opts.Limit,
-		Offset: opts.Offset,
+		ScopeUserId: opts.ScopeUserId,
+		Filter:      opts.Filter,
+		Limit:       opts.Limit,
+		Offset:      opts.Offset,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("s.repo.GetList: %w", err)
@@ -60,8 +77,12 @@ func (s *GroupServiceImpl) GetList(
 
 func (s *GroupServiceImpl) GetCount(
 	ctx context.Context,
+	opts GroupServiceGetListOpts,
 ) (int64, error) {
-	count, err := s.repo.GetCount(ctx)
+	count, err := s.repo.GetCount(ctx, repo.GroupsRepoGetListOpts{
+		ScopeUserId: opts.ScopeUserId,
+		Filter:      opts.Filter,
+	})
 	if err != nil {
 		return 0, fmt.Errorf("s.repo.GetCount: %w", err)
 	}
@@ -72,8 +93,18 @@ func (s *GroupServiceImpl) Create(
 	ctx context.Context,
 	opts GroupServiceCreateOpts,
 ) (models.Group, error) {
+	if err := s.orgUnitService.CheckManaged(ctx, opts.OrgUnitId, opts.ScopeUserId); err != nil {
+		return models.Group{}, fmt.Errorf("s.orgUnitService.CheckManaged: %w", err)
+	}
+	if opts.OrgUnitId != nil {
+		if err := s.orgUnitService.CheckLeaf(ctx, *opts.OrgUnitId); err != nil {
+			return models.Group{}, fmt.Errorf("s.orgUnitService.CheckLeaf: %w", err)
+		}
+	}
+
 	group, err := s.repo.Create(ctx, repo.GroupsRepoCreateOpts{
-		Name: opts.Name,
+		Name:      opts.Name,
+		OrgUnitId: opts.OrgUnitId,
 	})
 	if err != nil {
 		return models.Group{}, fmt.Errorf("s.repo.Create: %w", err)
@@ -85,9 +116,22 @@ func (s *GroupServiceImpl) Update(
 	ctx context.Context,
 	opts GroupServiceUpdateOpts,
 ) (models.Group, error) {
+	if err := s.checkGroup(ctx, opts.Id, opts.ScopeUserId); err != nil {
+		return models.Group{}, err
+	}
+	if err := s.orgUnitService.CheckManaged(ctx, opts.OrgUnitId, opts.ScopeUserId); err != nil {
+		return models.Group{}, fmt.Errorf("s.orgUnitService.CheckManaged: %w", err)
+	}
+	if opts.OrgUnitId != nil {
+		if err := s.orgUnitService.CheckLeaf(ctx, *opts.OrgUnitId); err != nil {
+			return models.Group{}, fmt.Errorf("s.orgUnitService.CheckLeaf: %w", err)
+		}
+	}
+
 	group, err := s.repo.Update(ctx, repo.GroupsRepoUpdateOpts{
-		Id:   opts.Id,
-		Name: opts.Name,
+		Id:        opts.Id,
+		Name:      opts.Name,
+		OrgUnitId: opts.OrgUnitId,
 	})
 	if err != nil {
 		return models.Group{}, fmt.Errorf("s.repo.Update: %w", err)
@@ -97,10 +141,89 @@ func (s *GroupServiceImpl) Update(
 
 func (s *GroupServiceImpl) Delete(
 	ctx context.Context,
-	id int64,
+	opts GroupServiceDeleteOpts,
 ) error {
-	if err := s.repo.Delete(ctx, id); err != nil {
+	if err := s.checkGroup(ctx, opts.Id, opts.ScopeUserId); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, opts.Id); err != nil {
 		return fmt.Errorf("s.repo.Delete: %w", err)
 	}
 	return nil
 }
+
+func (s *GroupServiceImpl) Assign(
+	ctx context.Context,
+	opts GroupServiceAssignmentOpts,
+) error {
+	if err := s.checkGroup(ctx, opts.GroupId, opts.ScopeUserId); err != nil {
+		return err
+	}
+
+	user, err := s.userService.GetById(ctx, opts.UserId)
+	if err != nil {
+		return fmt.Errorf("s.userService.GetById: %w", err)
+	}
+	if user.RoleId != models.UserRoleTeacher && user.RoleId != models.UserRoleObserver {
+		return fmt.Errorf("%w: user %d is neither a teacher nor an observer", ErrInvalidAssignee, user.Id)
+	}
+
+	if err = s.repo.CreateAssignment(ctx, repo.GroupsRepoAssignmentOpts{
+		UserId:  opts.UserId,
+		GroupId: opts.GroupId,
+	}); err != nil {
+		return fmt.Errorf("s.repo.CreateAssignment: %w", err)
+	}
+	return nil
+}
+
+func (s *GroupServiceImpl) Unassign(
+	ctx context.Context,
+	opts GroupServiceAssignmentOpts,
+) error {
+	if err := s.checkGroup(ctx, opts.GroupId, opts.ScopeUserId); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteAssignment(ctx, repo.GroupsRepoAssignmentOpts{
+		UserId:  opts.UserId,
+		GroupId: opts.GroupId,
+	}); err != nil {
+		return fmt.Errorf("s.repo.DeleteAssignment: %w", err)
+	}
+	return nil
+}
+
+func (s *GroupServiceImpl) CheckAccess(
+	ctx context.Context,
+	opts GroupServiceCheckAccessOpts,
+) error {
+	ok, err := s.repo.IsAccessible(ctx, repo.GroupsRepoIsAccessibleOpts{
+		ScopeUserId: opts.ScopeUserId,
+		GroupIds:    opts.GroupIds,
+		UserIds:     opts.UserIds,
+	})
+	if err != nil {
+		return fmt.Errorf("s.repo.IsAccessible: %w", err)
+	}
+	if !ok {
+		return ErrGroupNotAssigned
+	}
+	return nil
+}
+
+// checkGroup makes sure the group is one of the administrator's visible groups.
+func (s *GroupServiceImpl) checkGroup(
+	ctx context.Context,
+	id int64,
+	scopeUserId int64,
+) error {
+	if err := s.CheckAccess(ctx, GroupServiceCheckAccessOpts{
+		ScopeUserId: scopeUserId,
+		GroupIds:    []int64{id},
+	}); err != nil {
+		return fmt.Errorf("s.CheckAccess: %w", err)
+	}
+	return nil
+}
@@ -4,30 +4,55 @@ import (
 	"backend/internal/models"
 	"backend/internal/repo"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+	"math"
+)
+
+var (
+	ErrMarkOutOfRange  = errors.New("mark is out of task cost range")
+	ErrInvalidCriteria = errors.New("criteria do not match task rubric")
 )
 
 type MarkService interface {
 	GetById(ctx context.Context, id int64) (models.Mark, error)
 	GetByAnswerId(ctx context.Context, id int64) (models.Mark, error)
 	GetListByUserId(ctx context.Context, opts MarkServiceGetListByUserIdOpts) ([]models.Mark, error)
-	GetCountByUserId(ctx context.Context, userId int64) (int64, error)
+	GetCountByUserId(ctx context.Context, opts MarkServiceGetListByUserIdOpts) (int64, error)
+	Grade(ctx context.Context, answer models.Answer, raw int64) (models.Mark, error)
 	Create(ctx context.Context, opts MarkServiceCreateOpts) (models.Mark, error)
 	Update(ctx context.Context, opts MarkServiceUpdateOpts) (models.Mark, error)
+	Regrade(ctx context.Context, answerId int64) error
 	Delete(ctx context.Context, id int64) error
 }
 
 type MarkServiceImpl struct {
-	repo repo.MarkRepo
-	log  *zerolog.Logger
+	repo          repo.MarkRepo
+	answerService AnswerService
+	taskService   TaskService
+	rubricService RubricService
+	peerService   PeerReviewService
+	log           *zerolog.Logger
 }
 
 func NewMarkServiceImpl(
 	repo repo.MarkRepo,
+	answerService AnswerService,
+	taskService TaskService,
+	rubricService RubricService,
+	peerService PeerReviewService,
 	log *zerolog.Logger,
 ) *MarkServiceImpl {
-	return &MarkServiceImpl{repo: repo, log: log}
+	return &MarkServiceImpl{
+		repo:          repo,
+		answerService: answerService,
+		taskService:   taskService,
+		rubricService: rubricService,
+		peerService:   peerService,
+		log:           log,
+	}
 }
 
 func (s *MarkServiceImpl) GetById(ctx context.Context, id int64) (models.Mark, error) {
@@ -51,9 +76,11 @@ func (s *MarkServiceImpl) GetListByUserId(
 	opts MarkServiceGetListByUserIdOpts,
 ) ([]models.Mark, error) {
 	marks, err := s.repo.GetListByUserId(ctx, repo.MarksRepoGetListByUserIdOpts{
-		Limit:  opts.Limit,
-		Offset: opts.Offset,
-		UserId: opts.UserId,
+		UserId:      opts.UserId,
+		ScopeUserId: opts.ScopeUserId,
+		Filter:      opts.Filter,
+		Limit:       opts.Limit,
+		Offset:      opts.Offset,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("s.repo.GetListByUserId: %w", err)
@@ -63,23 +90,53 @@ func (s *MarkServiceImpl) GetListByUserId(
 
 func (s *MarkServiceImpl) GetCountByUserId(
 	ctx context.Context,
-	userId int64,
+	opts MarkServiceGetListByUserIdOpts,
 ) (int64, error) {
-	count, err := s.repo.GetCountByUserId(ctx, userId)
+	count, err := s.repo.GetCountByUserId(ctx, repo.MarksRepoGetListByUserIdOpts{
+		UserId:      opts.UserId,
+		ScopeUserId: opts.ScopeUserId,
+		Filter:      opts.Filter,
+	})
 	if err != nil {
 		return 0, fmt.Errorf("s.repo.GetCountByUserId: %w", err)
 	}
 	return count, nil
 }
 
+// Grade computes the mark the answer gets for the raw score, without storing
+// it, for answers that are stored together with their mark.
+func (s *MarkServiceImpl) Grade(
+	ctx context.Context,
+	answer models.Answer,
+	raw int64,
+) (models.Mark, error) {
+	grade, err := s.gradeAnswer(ctx, answer, raw, nil)
+	if err != nil {
+		return models.Mark{}, fmt.Errorf("s.gradeAnswer: %w", err)
+	}
+	return models.Mark{
+		AnswerId: answer.Id,
+		TaskId:   answer.TaskId,
+		RawMark:  grade.raw,
+		Mark:     grade.final,
+	}, nil
+}
+
 func (s *MarkServiceImpl) Create(
 	ctx context.Context,
 	opts MarkServiceCreateOpts,
 ) (models.Mark, error) {
+	grade, err := s.grade(ctx, opts.AnswerId, opts.Mark, opts.Criteria)
+	if err != nil {
+		return models.Mark{}, fmt.Errorf("s.grade: %w", err)
+	}
+
 	mark, err := s.repo.Create(ctx, repo.MarksRepoCreateOpts{
 		AnswerId: opts.AnswerId,
-		Mark:     opts.Mark,
+		RawMark:  grade.raw,
+		Mark:     grade.final,
 		Comment:  opts.Comment,
+		Criteria: grade.criteria,
 	})
 	if err != nil {
 		return models.Mark{}, fmt.Errorf("s.repo.Create: %w", err)
@@ -91,10 +148,22 @@ func (s *MarkServiceImpl) Update(
 	ctx context.Context,
 	opts MarkServiceUpdateOpts,
 ) (models.Mark, error) {
+	current, err := s.repo.GetById(ctx, opts.Id)
+	if err != nil {
+		return models.Mark{}, fmt.Errorf("s.repo.GetById: %w", err)
+	}
+
+	grade, err := s.grade(ctx, current.AnswerId, opts.Mark, opts.Criteria)
+	if err != nil {
+		return models.Mark{}, fmt.Errorf("s.grade: %w", err)
+	}
+
 	mark, err := s.repo.Update(ctx, repo.MarksRepoUpdateOpts{
-		Id:      opts.Id,
-		Mark:    opts.Mark,
-		Comment: opts.Comment,
+		Id:       opts.Id,
+		RawMark:  grade.raw,
+		Mark:     grade.final,
+		Comment:  opts.Comment,
+		Criteria: grade.criteria,
 	})
 	if err != nil {
 		return models.Mark{}, fmt.Errorf("s.repo.Update: %w", err)
@@ -102,6 +171,152 @@ func (s *MarkServiceImpl) Update(
 	return mark, nil
 }
 
+// Regrade computes the mark of the answer again from the stored raw mark and
+// criteria, for when the peer score it blends in has changed. Answers without
+// a mark are left alone.
+func (s *MarkServiceImpl) Regrade(
+	ctx context.Context,
+	answerId int64,
+) error {
+	current, err := s.repo.GetByAnswerId(ctx, answerId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("s.repo.GetByAnswerId: %w", err)
+	}
+
+	grade, err := s.grade(ctx, answerId, current.RawMark, current.Criteria)
+	if err != nil {
+		return fmt.Errorf("s.grade: %w", err)
+	}
+	if grade.final == current.Mark {
+		return nil
+	}
+
+	if _, err = s.repo.Update(ctx, repo.MarksRepoUpdateOpts{
+		Id:       current.Id,
+		RawMark:  grade.raw,
+		Mark:     grade.final,
+		Comment:  current.Comment,
+		Criteria: grade.criteria,
+	}); err != nil {
+		return fmt.Errorf("s.repo.Update: %w", err)
+	}
+	return nil
+}
+
+type markGrade struct {
+	raw      int64
+	final    int64
+	criteria []models.MarkCriterion
+}
+
+// grade loads the answer and grades it with gradeAnswer.
+func (s *MarkServiceImpl) grade(
+	ctx context.Context,
+	answerId int64,
+	raw int64,
+	criteria []models.MarkCriterion,
+) (markGrade, error) {
+	answer, err := s.answerService.GetById(ctx, answerId)
+	if err != nil {
+		return markGrade{}, fmt.Errorf("s.answerService.GetById: %w", err)
+	}
+	return s.gradeAnswer(ctx, answer, raw, criteria)
+}
+
+// gradeAnswer computes the raw mark, from the task rubric when it has one,
+// and validates it against the task cost. The final mark blends in the peer
+// score when the task asks for it and deducts the penalty of the task's late
+// policy. The raw mark is kept as graded so that the final one can be
+// computed again when the peer score changes.
+func (s *MarkServiceImpl) gradeAnswer(
+	ctx context.Context,
+	answer models.Answer,
+	raw int64,
+	criteria []models.MarkCriterion,
+) (markGrade, error) {
+	task, err := s.taskService.GetById(ctx, answer.TaskId)
+	if err != nil {
+		return markGrade{}, fmt.Errorf("s.taskService.GetById: %w", err)
+	}
+
+	if task.RubricId != nil {
+		rubric, err := s.rubricService.GetById(ctx, *task.RubricId)
+		if err != nil {
+			return markGrade{}, fmt.Errorf("s.rubricService.GetById: %w", err)
+		}
+		if raw, criteria, err = scoreRubric(rubric, criteria); err != nil {
+			return markGrade{}, err
+		}
+	} else if len(criteria) > 0 {
+		return markGrade{}, ErrInvalidCriteria
+	}
+
+	if raw < 0 || raw > task.Cost {
+		return markGrade{}, ErrMarkOutOfRange
+	}
+	blended, err := s.peerService.ApplyScore(ctx, task, answer.Id, raw)
+	if err != nil {
+		return markGrade{}, fmt.Errorf("s.peerService.ApplyScore: %w", err)
+	}
+	if !answer.IsLate {
+		return markGrade{raw: raw, final: blended, criteria: criteria}, nil
+	}
+
+	final := blended
+	switch task.LatePolicy {
+	case models.LatePolicyFixed:
+		final = blended - task.LatePenalty
+	case models.LatePolicyPercentPerDay:
+		days := int64(math.Ceil(float64(answer.LateSeconds) / (24 * 60 * 60)))
+		final = blended * (100 - task.LatePenalty*days) / 100
+	}
+	return markGrade{raw: raw, final: max(final, 0), criteria: criteria}, nil
+}
+
+// scoreRubric checks that every rubric criterion is graded with one of its
+// levels and returns the total points with the criteria filled in.
+func scoreRubric(
+	rubric models.Rubric,
+	criteria []models.MarkCriterion,
+) (int64, []models.MarkCriterion, error) {
+	if len(criteria) != len(rubric.Criteria) {
+		return 0, nil, ErrInvalidCriteria
+	}
+
+	byId := lo.SliceToMap(criteria, func(item models.MarkCriterion) (int64, models.MarkCriterion) {
+		return item.CriterionId, item
+	})
+
+	var total int64
+	scored := make([]models.MarkCriterion, 0, len(rubric.Criteria))
+	for _, criterion := range rubric.Criteria {
+		graded, ok := byId[criterion.Id]
+		if !ok {
+			return 0, nil, ErrInvalidCriteria
+		}
+		level, ok := lo.Find(criterion.Levels, func(item models.RubricLevel) bool {
+			return item.Id == graded.LevelId
+		})
+		if !ok {
+			return 0, nil, ErrInvalidCriteria
+		}
+
+		total += level.Points
+		scored = append(scored, models.MarkCriterion{
+			CriterionId:   criterion.Id,
+			CriterionName: criterion.Name,
+			LevelId:       level.Id,
+			LevelName:     level.Name,
+			Points:        level.Points,
+			Comment:       graded.Comment,
+		})
+	}
+	return total, scored, nil
+}
+
 func (s *MarkServiceImpl) Delete(
 	ctx context.Context,
 	id int64,
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"backend/internal/models"
+	"context"
+	"github.com/rs/zerolog"
+)
+
+// Notifier delivers events to the users they concern.
+type Notifier interface {
+	TaskPublished(ctx context.Context, task models.Task, userIds []int64) error
+}
+
+// LogNotifier writes notifications to the log, it is used until a delivery
+// channel is configured.
+type LogNotifier struct {
+	log *zerolog.Logger
+}
+
+func NewLogNotifier(log *zerolog.Logger) *LogNotifier {
+	return &LogNotifier{log: log}
+}
+
+func (n *LogNotifier) TaskPublished(
+	_ context.Context,
+	task models.Task,
+	userIds []int64,
+) error {
+	n.log.Info().
+		Int64("taskId", task.Id).
+		Ints64("userIds", userIds).
+		Msg("Task published")
+	return nil
+}
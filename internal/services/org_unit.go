@@ -0,0 +1,269 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrInvalidOrgUnit    = errors.New("invalid org unit")
+	ErrOrgUnitNotManaged = errors.New("org unit is outside of your units")
+)
+
+type OrgUnitService interface {
+	GetById(ctx context.Context, id int64) (models.OrgUnit, error)
+	GetList(ctx context.Context) ([]models.OrgUnit, error)
+	Create(ctx context.Context, opts OrgUnitServiceCreateOpts) (models.OrgUnit, error)
+	Update(ctx context.Context, opts OrgUnitServiceUpdateOpts) (models.OrgUnit, error)
+	Delete(ctx context.Context, opts OrgUnitServiceDeleteOpts) error
+	AssignAdmin(ctx context.Context, opts OrgUnitServiceAdminOpts) error
+	UnassignAdmin(ctx context.Context, opts OrgUnitServiceAdminOpts) error
+	CheckLeaf(ctx context.Context, id int64) error
+	CheckManaged(ctx context.Context, id *int64, userId int64) error
+}
+
+type OrgUnitServiceImpl struct {
+	repo        repo.OrgUnitsRepo
+	userService UserService
+	log         *zerolog.Logger
+}
+
+func NewOrgUnitServiceImpl(
+	repo repo.OrgUnitsRepo,
+	userService UserService,
+	log *zerolog.Logger,
+) *OrgUnitServiceImpl {
+	return &OrgUnitServiceImpl{
+		repo:        repo,
+		userService: userService,
+		log:         log,
+	}
+}
+
+func (s *OrgUnitServiceImpl) GetById(
+	ctx context.Context,
+	id int64,
+) (models.OrgUnit, error) {
+	unit, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return models.OrgUnit{}, fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	return unit, nil
+}
+
+func (s *OrgUnitServiceImpl) GetList(
+	ctx context.Context,
+) ([]models.OrgUnit, error) {
+	units, err := s.repo.GetList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetList: %w", err)
+	}
+	return units, nil
+}
+
+func (s *OrgUnitServiceImpl) Create(
+	ctx context.Context,
+	opts OrgUnitServiceCreateOpts,
+) (models.OrgUnit, error) {
+	if err := checkOrgUnitKind(opts.Kind); err != nil {
+		return models.OrgUnit{}, err
+	}
+	if err := s.CheckManaged(ctx, opts.ParentId, opts.ScopeUserId); err != nil {
+		return models.OrgUnit{}, err
+	}
+	if opts.ParentId != nil {
+		if err := s.checkParent(ctx, *opts.ParentId); err != nil {
+			return models.OrgUnit{}, fmt.Errorf("s.checkParent: %w", err)
+		}
+	}
+
+	unit, err := s.repo.Create(ctx, repo.OrgUnitsRepoCreateOpts{
+		ParentId: opts.ParentId,
+		Kind:     opts.Kind,
+		Name:     opts.Name,
+	})
+	if err != nil {
+		return models.OrgUnit{}, fmt.Errorf("s.repo.Create: %w", err)
+	}
+	return unit, nil
+}
+
+func (s *OrgUnitServiceImpl) Update(
+	ctx context.Context,
+	opts OrgUnitServiceUpdateOpts,
+) (models.OrgUnit, error) {
+	if err := checkOrgUnitKind(opts.Kind); err != nil {
+		return models.OrgUnit{}, err
+	}
+	if err := s.CheckManaged(ctx, &opts.Id, opts.ScopeUserId); err != nil {
+		return models.OrgUnit{}, err
+	}
+	if err := s.CheckManaged(ctx, opts.ParentId, opts.ScopeUserId); err != nil {
+		return models.OrgUnit{}, err
+	}
+	if opts.ParentId != nil {
+		cycle, err := s.repo.IsInSubtree(ctx, opts.Id, *opts.ParentId)
+		if err != nil {
+			return models.OrgUnit{}, fmt.Errorf("s.repo.IsInSubtree: %w", err)
+		}
+		if cycle {
+			return models.OrgUnit{}, fmt.Errorf("%w: unit %d can not be moved under its own subtree", ErrInvalidOrgUnit, opts.Id)
+		}
+		if err = s.checkParent(ctx, *opts.ParentId); err != nil {
+			return models.OrgUnit{}, fmt.Errorf("s.checkParent: %w", err)
+		}
+	}
+
+	unit, err := s.repo.Update(ctx, repo.OrgUnitsRepoUpdateOpts{
+		Id:       opts.Id,
+		ParentId: opts.ParentId,
+		Kind:     opts.Kind,
+		Name:     opts.Name,
+	})
+	if err != nil {
+		return models.OrgUnit{}, fmt.Errorf("s.repo.Update: %w", err)
+	}
+	return unit, nil
+}
+
+func (s *OrgUnitServiceImpl) Delete(
+	ctx context.Context,
+	opts OrgUnitServiceDeleteOpts,
+) error {
+	id := opts.Id
+	if err := s.CheckManaged(ctx, &id, opts.ScopeUserId); err != nil {
+		return err
+	}
+
+	hasChildren, err := s.repo.HasChildren(ctx, id)
+	if err != nil {
+		return fmt.Errorf("s.repo.HasChildren: %w", err)
+	}
+	hasGroups, err := s.repo.HasGroups(ctx, id)
+	if err != nil {
+		return fmt.Errorf("s.repo.HasGroups: %w", err)
+	}
+	if hasChildren || hasGroups {
+		return fmt.Errorf("%w: unit %d is not empty", ErrInvalidOrgUnit, id)
+	}
+
+	if err = s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("s.repo.Delete: %w", err)
+	}
+	return nil
+}
+
+func (s *OrgUnitServiceImpl) AssignAdmin(
+	ctx context.Context,
+	opts OrgUnitServiceAdminOpts,
+) error {
+	if err := s.CheckManaged(ctx, &opts.OrgUnitId, opts.ScopeUserId); err != nil {
+		return err
+	}
+
+	user, err := s.userService.GetById(ctx, opts.UserId)
+	if err != nil {
+		return fmt.Errorf("s.userService.GetById: %w", err)
+	}
+	if user.RoleId != models.UserRoleAdministrator {
+		return fmt.Errorf("%w: user %d is not an administrator", ErrInvalidAssignee, user.Id)
+	}
+
+	if err = s.repo.CreateAdmin(ctx, repo.OrgUnitsRepoAdminOpts{
+		UserId:    opts.UserId,
+		OrgUnitId: opts.OrgUnitId,
+	}); err != nil {
+		return fmt.Errorf("s.repo.CreateAdmin: %w", err)
+	}
+	return nil
+}
+
+func (s *OrgUnitServiceImpl) UnassignAdmin(
+	ctx context.Context,
+	opts OrgUnitServiceAdminOpts,
+) error {
+	if err := s.CheckManaged(ctx, &opts.OrgUnitId, opts.ScopeUserId); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteAdmin(ctx, repo.OrgUnitsRepoAdminOpts{
+		UserId:    opts.UserId,
+		OrgUnitId: opts.OrgUnitId,
+	}); err != nil {
+		return fmt.Errorf("s.repo.DeleteAdmin: %w", err)
+	}
+	return nil
+}
+
+func (s *OrgUnitServiceImpl) CheckLeaf(
+	ctx context.Context,
+	id int64,
+) error {
+	if _, err := s.repo.GetById(ctx, id); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fmt.Errorf("%w: unit %d not found", ErrInvalidOrgUnit, id)
+		}
+		return fmt.Errorf("s.repo.GetById: %w", err)
+	}
+
+	hasChildren, err := s.repo.HasChildren(ctx, id)
+	if err != nil {
+		return fmt.Errorf("s.repo.HasChildren: %w", err)
+	}
+	if hasChildren {
+		return fmt.Errorf("%w: groups can be attached only to leaf units", ErrInvalidOrgUnit)
+	}
+	return nil
+}
+
+// CheckManaged makes sure the administrator may change the unit, or create
+// root units when id is nil.
+func (s *OrgUnitServiceImpl) CheckManaged(
+	ctx context.Context,
+	id *int64,
+	userId int64,
+) error {
+	ok, err := s.repo.IsManagedBy(ctx, id, userId)
+	if err != nil {
+		return fmt.Errorf("s.repo.IsManagedBy: %w", err)
+	}
+	if !ok {
+		return ErrOrgUnitNotManaged
+	}
+	return nil
+}
+
+func checkOrgUnitKind(kind string) error {
+	switch kind {
+	case models.OrgUnitKindFaculty, models.OrgUnitKindDepartment:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown kind %q", ErrInvalidOrgUnit, kind)
+}
+
+// checkParent keeps groups on leaves: a unit that already holds groups can not get children.
+func (s *OrgUnitServiceImpl) checkParent(
+	ctx context.Context,
+	parentId int64,
+) error {
+	if _, err := s.repo.GetById(ctx, parentId); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fmt.Errorf("%w: parent unit %d not found", ErrInvalidOrgUnit, parentId)
+		}
+		return fmt.Errorf("s.repo.GetById: %w", err)
+	}
+
+	hasGroups, err := s.repo.HasGroups(ctx, parentId)
+	if err != nil {
+		return fmt.Errorf("s.repo.HasGroups: %w", err)
+	}
+	if hasGroups {
+		return fmt.Errorf("%w: unit %d has groups attached", ErrInvalidOrgUnit, parentId)
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrInvalidPeerReview  = errors.New("invalid peer review")
+	ErrPeerReviewRejected = errors.New("peer review has been rejected by a moderator")
+)
+
+type PeerReviewService interface {
+	GetSettings(ctx context.Context, taskId int64) (models.PeerReviewSettings, error)
+	SetSettings(ctx context.Context, opts PeerReviewServiceSetSettingsOpts) (models.PeerReviewSettings, error)
+	DeleteSettings(ctx context.Context, taskId int64) error
+	AssignDue(ctx context.Context) error
+	GetById(ctx context.Context, id int64) (models.PeerReview, error)
+	GetList(ctx context.Context, opts PeerReviewServiceGetListOpts) ([]models.PeerReview, error)
+	Submit(ctx context.Context, opts PeerReviewServiceSubmitOpts) (models.PeerReview, error)
+	Moderate(ctx context.Context, opts PeerReviewServiceModerateOpts) (models.PeerReview, error)
+	ApplyScore(ctx context.Context, task models.Task, answerId, raw int64) (int64, error)
+}
+
+type PeerReviewServiceImpl struct {
+	repo          repo.PeerReviewsRepo
+	taskService   TaskService
+	rubricService RubricService
+	log           *zerolog.Logger
+}
+
+func NewPeerReviewServiceImpl(
+	repo repo.PeerReviewsRepo,
+	taskService TaskService,
+	rubricService RubricService,
+	log *zerolog.Logger,
+) *PeerReviewServiceImpl {
+	return &PeerReviewServiceImpl{
+		repo:          repo,
+		taskService:   taskService,
+		rubricService: rubricService,
+		log:           log,
+	}
+}
+
+func (s *PeerReviewServiceImpl) GetSettings(
+	ctx context.Context,
+	taskId int64,
+) (models.PeerReviewSettings, error) {
+	settings, err := s.repo.GetSettings(ctx, taskId)
+	if err != nil {
+		return models.PeerReviewSettings{}, fmt.Errorf("s.repo.GetSettings: %w", err)
+	}
+	return settings, nil
+}
+
+// SetSettings turns peer review on or changes it. Changing the number of
+// reviews after they have been assigned has no effect on them.
+func (s *PeerReviewServiceImpl) SetSettings(
+	ctx context.Context,
+	opts PeerReviewServiceSetSettingsOpts,
+) (models.PeerReviewSettings, error) {
+	task, err := s.taskService.GetById(ctx, opts.TaskId)
+	if err != nil {
+		return models.PeerReviewSettings{}, fmt.Errorf("s.taskService.GetById: %w", err)
+	}
+	if task.IsTemplate {
+		return models.PeerReviewSettings{}, fmt.Errorf("%w: templates cannot be peer reviewed", ErrInvalidPeerReview)
+	}
+	if opts.ReviewsPerAnswer < 1 {
+		return models.PeerReviewSettings{}, fmt.Errorf("%w: reviewsPerAnswer must be at least 1", ErrInvalidPeerReview)
+	}
+	if opts.MarkWeight < 0 || opts.MarkWeight > 100 {
+		return models.PeerReviewSettings{}, fmt.Errorf("%w: markWeight must be between 0 and 100", ErrInvalidPeerReview)
+	}
+	if opts.RubricId != nil {
+		rubric, err := s.rubricService.GetById(ctx, *opts.RubricId)
+		if err != nil {
+			if errors.Is(err, repo.ErrNotFound) {
+				return models.PeerReviewSettings{}, ErrInvalidRubric
+			}
+			return models.PeerReviewSettings{}, fmt.Errorf("s.rubricService.GetById: %w", err)
+		}
+		if rubricMaxPoints(rubric) > task.Cost {
+			return models.PeerReviewSettings{}, ErrRubricExceedsCost
+		}
+	}
+
+	settings, err := s.repo.SetSettings(ctx, repo.PeerReviewsRepoSetSettingsOpts{
+		TaskId:           opts.TaskId,
+		ReviewsPerAnswer: opts.ReviewsPerAnswer,
+		RubricId:         opts.RubricId,
+		MarkWeight:       opts.MarkWeight,
+	})
+	if err != nil {
+		return models.PeerReviewSettings{}, fmt.Errorf("s.repo.SetSettings: %w", err)
+	}
+	return settings, nil
+}
+
+func (s *PeerReviewServiceImpl) DeleteSettings(
+	ctx context.Context,
+	taskId int64,
+) error {
+	if err := s.repo.DeleteSettings(ctx, taskId); err != nil {
+		return fmt.Errorf("s.repo.DeleteSettings: %w", err)
+	}
+	return nil
+}
+
+// AssignDue hands out the reviews of every peer reviewed task that is over.
+// Answers submitted after that are not reviewed. A failing task does not
+// stop the others.
+func (s *PeerReviewServiceImpl) AssignDue(ctx context.Context) error {
+	due, err := s.repo.GetDue(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("s.repo.GetDue: %w", err)
+	}
+
+	var errs []error
+	for _, settings := range due {
+		assigned, err := s.repo.Assign(ctx, settings.TaskId, settings.ReviewsPerAnswer)
+		if err != nil {
+			if errors.Is(err, repo.ErrConflict) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("s.repo.Assign: %d:%w", settings.TaskId, err))
+			continue
+		}
+		s.log.Info().Int64("taskId", settings.TaskId).Int64("reviews", assigned).Msg("Peer reviews assigned")
+	}
+	return errors.Join(errs...)
+}
+
+func (s *PeerReviewServiceImpl) GetById(
+	ctx context.Context,
+	id int64,
+) (models.PeerReview, error) {
+	review, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return models.PeerReview{}, fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	return review, nil
+}
+
+func (s *PeerReviewServiceImpl) GetList(
+	ctx context.Context,
+	opts PeerReviewServiceGetListOpts,
+) ([]models.PeerReview, error) {
+	reviews, err := s.repo.GetList(ctx, repo.PeerReviewsRepoGetListOpts{
+		TaskId:        opts.TaskId,
+		ReviewerId:    opts.ReviewerId,
+		AnswerId:      opts.AnswerId,
+		SubmittedOnly: opts.SubmittedOnly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetList: %w", err)
+	}
+	return reviews, nil
+}
+
+// Submit saves the review written by its reviewer. With a rubric the score
+// is the sum of the chosen levels, otherwise it is optional and the review
+// must at least have some text.
+func (s *PeerReviewServiceImpl) Submit(
+	ctx context.Context,
+	opts PeerReviewServiceSubmitOpts,
+) (models.PeerReview, error) {
+	review, err := s.repo.GetById(ctx, opts.Id)
+	if err != nil {
+		return models.PeerReview{}, fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	if review.ReviewerId != opts.ReviewerId {
+		return models.PeerReview{}, repo.ErrNotFound
+	}
+	if review.State == models.PeerReviewStateRejected {
+		return models.PeerReview{}, ErrPeerReviewRejected
+	}
+
+	settings, err := s.repo.GetSettings(ctx, review.TaskId)
+	if err != nil {
+		return models.PeerReview{}, fmt.Errorf("s.repo.GetSettings: %w", err)
+	}
+	task, err := s.taskService.GetById(ctx, review.TaskId)
+	if err != nil {
+		return models.PeerReview{}, fmt.Errorf("s.taskService.GetById: %w", err)
+	}
+
+	if opts.Text != nil {
+		text := strings.TrimSpace(*opts.Text)
+		opts.Text = &text
+		if text == "" {
+			opts.Text = nil
+		}
+	}
+
+	if settings.RubricId != nil {
+		rubric, err := s.rubricService.GetById(ctx, *settings.RubricId)
+		if err != nil {
+			return models.PeerReview{}, fmt.Errorf("s.rubricService.GetById: %w", err)
+		}
+		score, criteria, err := scoreRubric(rubric, opts.Criteria)
+		if err != nil {
+			return models.PeerReview{}, err
+		}
+		opts.Score, opts.Criteria = &score, criteria
+	} else if len(opts.Criteria) > 0 {
+		return models.PeerReview{}, ErrInvalidCriteria
+	} else if opts.Text == nil {
+		return models.PeerReview{}, fmt.Errorf("%w: text is required", ErrInvalidPeerReview)
+	}
+
+	if opts.Score != nil && (*opts.Score < 0 || *opts.Score > task.Cost) {
+		return models.PeerReview{}, ErrMarkOutOfRange
+	}
+
+	review, err = s.repo.Submit(ctx, repo.PeerReviewsRepoSubmitOpts{
+		Id:       opts.Id,
+		Score:    opts.Score,
+		Text:     opts.Text,
+		Criteria: opts.Criteria,
+	})
+	if err != nil {
+		return models.PeerReview{}, fmt.Errorf("s.repo.Submit: %w", err)
+	}
+	return review, nil
+}
+
+// Moderate rejects a review, which hides it from the answer's author and
+// drops it from the peer score, or lets a rejected review back in.
+func (s *PeerReviewServiceImpl) Moderate(
+	ctx context.Context,
+	opts PeerReviewServiceModerateOpts,
+) (models.PeerReview, error) {
+	review, err := s.repo.Moderate(ctx, repo.PeerReviewsRepoModerateOpts{
+		Id:       opts.Id,
+		Rejected: opts.Rejected,
+		Comment:  opts.Comment,
+	})
+	if err != nil {
+		return models.PeerReview{}, fmt.Errorf("s.repo.Moderate: %w", err)
+	}
+	return review, nil
+}
+
+// ApplyScore blends the average peer score of the answer into the raw mark
+// according to the task's mark weight. Rubric scores are scaled from the
+// rubric's maximum to the task cost first. The raw mark is returned unchanged
+// when the task is not peer reviewed with a weight or no review has a score.
+func (s *PeerReviewServiceImpl) ApplyScore(
+	ctx context.Context,
+	task models.Task,
+	answerId, raw int64,
+) (int64, error) {
+	settings, err := s.repo.GetSettings(ctx, task.Id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return raw, nil
+		}
+		return 0, fmt.Errorf("s.repo.GetSettings: %w", err)
+	}
+	if settings.MarkWeight == 0 {
+		return raw, nil
+	}
+
+	score, err := s.repo.GetScore(ctx, answerId)
+	if err != nil {
+		return 0, fmt.Errorf("s.repo.GetScore: %w", err)
+	}
+	if score == nil {
+		return raw, nil
+	}
+
+	peer := *score
+	if settings.RubricId != nil {
+		rubric, err := s.rubricService.GetById(ctx, *settings.RubricId)
+		if err != nil {
+			return 0, fmt.Errorf("s.rubricService.GetById: %w", err)
+		}
+		if maxPoints := rubricMaxPoints(rubric); maxPoints > 0 {
+			peer = (peer*task.Cost + maxPoints/2) / maxPoints
+		}
+	}
+	return (raw*(100-settings.MarkWeight) + peer*settings.MarkWeight + 50) / 100, nil
+}
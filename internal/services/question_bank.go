@@ -0,0 +1,221 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+)
+
+var (
+	ErrInvalidQuestionBank  = errors.New("invalid question bank")
+	ErrQuestionBankInUse    = errors.New("question bank is in use")
+	ErrQuestionBankNotOwned = errors.New("question bank belongs to another user")
+)
+
+type QuestionBankService interface {
+	GetById(ctx context.Context, id int64) (models.QuestionBank, error)
+	GetList(ctx context.Context, opts QuestionBankServiceGetListOpts) ([]models.QuestionBank, error)
+	GetCount(ctx context.Context) (int64, error)
+	Create(ctx context.Context, opts QuestionBankServiceCreateOpts) (models.QuestionBank, error)
+	Update(ctx context.Context, opts QuestionBankServiceUpdateOpts) (models.QuestionBank, error)
+	Delete(ctx context.Context, opts QuestionBankServiceDeleteOpts) error
+}
+
+type QuestionBankServiceImpl struct {
+	repo repo.QuestionBanksRepo
+	log  *zerolog.Logger
+}
+
+func NewQuestionBankServiceImpl(
+	repo repo.QuestionBanksRepo,
+	log *zerolog.Logger,
+) *QuestionBankServiceImpl {
+	return &QuestionBankServiceImpl{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (s *QuestionBankServiceImpl) GetById(
+	ctx context.Context,
+	id int64,
+) (models.QuestionBank, error) {
+	bank, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return models.QuestionBank{}, fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	return bank, nil
+}
+
+func (s *QuestionBankServiceImpl) GetList(
+	ctx context.Context,
+	opts QuestionBankServiceGetListOpts,
+) ([]models.QuestionBank, error) {
+	banks, err := s.repo.GetList(ctx, repo.QuestionBanksRepoGetListOpts{
+		Limit:  opts.Limit,
+		Offset: opts.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetList: %w", err)
+	}
+	return banks, nil
+}
+
+func (s *QuestionBankServiceImpl) GetCount(
+	ctx context.Context,
+) (int64, error) {
+	count, err := s.repo.GetCount(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("s.repo.GetCount: %w", err)
+	}
+	return count, nil
+}
+
+func (s *QuestionBankServiceImpl) Create(
+	ctx context.Context,
+	opts QuestionBankServiceCreateOpts,
+) (models.QuestionBank, error) {
+	if err := validateQuestionBank(opts.Name, opts.Questions); err != nil {
+		return models.QuestionBank{}, err
+	}
+
+	bank, err := s.repo.Create(ctx, repo.QuestionBanksRepoCreateOpts{
+		Name:        opts.Name,
+		Description: opts.Description,
+		CreatedBy:   opts.CreatedBy,
+		Questions:   opts.Questions,
+	})
+	if err != nil {
+		return models.QuestionBank{}, fmt.Errorf("s.repo.Create: %w", err)
+	}
+	return bank, nil
+}
+
+func (s *QuestionBankServiceImpl) Update(
+	ctx context.Context,
+	opts QuestionBankServiceUpdateOpts,
+) (models.QuestionBank, error) {
+	if err := s.checkOwner(ctx, opts.Id, opts.UserId); err != nil {
+		return models.QuestionBank{}, err
+	}
+	if err := validateQuestionBank(opts.Name, opts.Questions); err != nil {
+		return models.QuestionBank{}, err
+	}
+
+	answered, err := s.repo.IsAnswered(ctx, opts.Id)
+	if err != nil {
+		return models.QuestionBank{}, fmt.Errorf("s.repo.IsAnswered: %w", err)
+	}
+	if answered {
+		return models.QuestionBank{}, ErrQuestionBankInUse
+	}
+
+	bank, err := s.repo.Update(ctx, repo.QuestionBanksRepoUpdateOpts{
+		Id:          opts.Id,
+		Name:        opts.Name,
+		Description: opts.Description,
+		Questions:   opts.Questions,
+	})
+	if err != nil {
+		return models.QuestionBank{}, fmt.Errorf("s.repo.Update: %w", err)
+	}
+	return bank, nil
+}
+
+func (s *QuestionBankServiceImpl) Delete(
+	ctx context.Context,
+	opts QuestionBankServiceDeleteOpts,
+) error {
+	if err := s.checkOwner(ctx, opts.Id, opts.UserId); err != nil {
+		return err
+	}
+
+	attached, err := s.repo.IsAttached(ctx, opts.Id)
+	if err != nil {
+		return fmt.Errorf("s.repo.IsAttached: %w", err)
+	}
+	if attached {
+		return ErrQuestionBankInUse
+	}
+
+	if err = s.repo.Delete(ctx, opts.Id); err != nil {
+		return fmt.Errorf("s.repo.Delete: %w", err)
+	}
+	return nil
+}
+
+// checkOwner makes sure only the author of the bank changes it.
+func (s *QuestionBankServiceImpl) checkOwner(
+	ctx context.Context,
+	id int64,
+	userId int64,
+) error {
+	bank, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	if bank.CreatedBy != userId {
+		return ErrQuestionBankNotOwned
+	}
+	return nil
+}
+
+func validateQuestionBank(name string, questions []models.Question) error {
+	if strings.TrimSpace(name) == "" || len(questions) == 0 {
+		return ErrInvalidQuestionBank
+	}
+	for _, q := range questions {
+		if err := validateQuestion(q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateQuestion(q models.Question) error {
+	if strings.TrimSpace(q.Text) == "" || q.Points <= 0 {
+		return ErrInvalidQuestionBank
+	}
+
+	switch q.Kind {
+	case models.QuestionSingleChoice, models.QuestionMultipleChoice:
+		if len(q.Options) < 2 || lo.Contains(lo.Map(q.Options, func(item string, _ int) string {
+			return strings.TrimSpace(item)
+		}), "") {
+			return ErrInvalidQuestionBank
+		}
+		if len(q.CorrectOptions) == 0 || len(lo.Uniq(q.CorrectOptions)) != len(q.CorrectOptions) {
+			return ErrInvalidQuestionBank
+		}
+		if q.Kind == models.QuestionSingleChoice && len(q.CorrectOptions) != 1 {
+			return ErrInvalidQuestionBank
+		}
+		for _, option := range q.CorrectOptions {
+			if option < 0 || option >= int64(len(q.Options)) {
+				return ErrInvalidQuestionBank
+			}
+		}
+	case models.QuestionNumeric:
+		if q.Answer == nil || (q.Tolerance != nil && *q.Tolerance < 0) {
+			return ErrInvalidQuestionBank
+		}
+	case models.QuestionShortText:
+		if len(q.AcceptedAnswers) == 0 {
+			return ErrInvalidQuestionBank
+		}
+		for _, answer := range q.AcceptedAnswers {
+			if normalizeShortText(answer) == "" {
+				return ErrInvalidQuestionBank
+			}
+		}
+	default:
+		return ErrInvalidQuestionBank
+	}
+	return nil
+}
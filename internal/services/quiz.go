@@ -0,0 +1,335 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+)
+
+var (
+	ErrInvalidQuiz         = errors.New("invalid quiz")
+	ErrInvalidQuizResponse = errors.New("responses do not match quiz questions")
+	ErrQuizAnswered        = errors.New("quiz already has responses")
+	ErrQuizSubmitted       = errors.New("quiz is already submitted")
+)
+
+type QuizService interface {
+	GetByTaskId(ctx context.Context, taskId int64) (models.TaskQuiz, error)
+	Set(ctx context.Context, opts QuizServiceSetOpts) (models.TaskQuiz, error)
+	Delete(ctx context.Context, taskId int64) error
+	GetQuestions(ctx context.Context, taskId, userId int64) ([]models.Question, error)
+	GetResponses(ctx context.Context, answerId int64) ([]models.QuizResponse, error)
+	Submit(ctx context.Context, opts QuizServiceSubmitOpts) (models.Mark, error)
+}
+
+type QuizServiceImpl struct {
+	repo          repo.QuizzesRepo
+	bankService   QuestionBankService
+	taskService   TaskService
+	answerService AnswerService
+	markService   MarkService
+	log           *zerolog.Logger
+}
+
+func NewQuizServiceImpl(
+	repo repo.QuizzesRepo,
+	bankService QuestionBankService,
+	taskService TaskService,
+	answerService AnswerService,
+	markService MarkService,
+	log *zerolog.Logger,
+) *QuizServiceImpl {
+	return &QuizServiceImpl{
+		repo:          repo,
+		bankService:   bankService,
+		taskService:   taskService,
+		answerService: answerService,
+		markService:   markService,
+		log:           log,
+	}
+}
+
+func (s *QuizServiceImpl) GetByTaskId(
+	ctx context.Context,
+	taskId int64,
+) (models.TaskQuiz, error) {
+	quiz, err := s.repo.GetByTaskId(ctx, taskId)
+	if err != nil {
+		return models.TaskQuiz{}, fmt.Errorf("s.repo.GetByTaskId: %w", err)
+	}
+	return quiz, nil
+}
+
+// Set turns the task into a quiz drawn from the bank. The task must not use a
+// rubric, since quiz marks are computed rather than graded by criteria.
+func (s *QuizServiceImpl) Set(
+	ctx context.Context,
+	opts QuizServiceSetOpts,
+) (models.TaskQuiz, error) {
+	task, err := s.taskService.GetById(ctx, opts.TaskId)
+	if err != nil {
+		return models.TaskQuiz{}, fmt.Errorf("s.taskService.GetById: %w", err)
+	}
+	if task.RubricId != nil {
+		return models.TaskQuiz{}, ErrInvalidQuiz
+	}
+
+	bank, err := s.bankService.GetById(ctx, opts.BankId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return models.TaskQuiz{}, ErrInvalidQuiz
+		}
+		return models.TaskQuiz{}, fmt.Errorf("s.bankService.GetById: %w", err)
+	}
+	if opts.QuestionCount != nil && (*opts.QuestionCount <= 0 || *opts.QuestionCount > int64(len(bank.Questions))) {
+		return models.TaskQuiz{}, ErrInvalidQuiz
+	}
+
+	if err = s.checkUnanswered(ctx, opts.TaskId); err != nil {
+		return models.TaskQuiz{}, err
+	}
+
+	quiz, err := s.repo.Set(ctx, repo.QuizzesRepoSetOpts{
+		TaskId:        opts.TaskId,
+		BankId:        opts.BankId,
+		QuestionCount: opts.QuestionCount,
+		Shuffle:       opts.Shuffle,
+	})
+	if err != nil {
+		return models.TaskQuiz{}, fmt.Errorf("s.repo.Set: %w", err)
+	}
+	return quiz, nil
+}
+
+func (s *QuizServiceImpl) Delete(
+	ctx context.Context,
+	taskId int64,
+) error {
+	if err := s.checkUnanswered(ctx, taskId); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, taskId); err != nil {
+		return fmt.Errorf("s.repo.Delete: %w", err)
+	}
+	return nil
+}
+
+func (s *QuizServiceImpl) checkUnanswered(ctx context.Context, taskId int64) error {
+	answered, err := s.repo.IsAnswered(ctx, taskId)
+	if err != nil {
+		return fmt.Errorf("s.repo.IsAnswered: %w", err)
+	}
+	if answered {
+		return ErrQuizAnswered
+	}
+	return nil
+}
+
+// GetQuestions returns the questions drawn for the student. The answer keys
+// are left in place; callers facing students must strip them.
+func (s *QuizServiceImpl) GetQuestions(
+	ctx context.Context,
+	taskId, userId int64,
+) ([]models.Question, error) {
+	if err := s.checkAssigned(ctx, taskId, userId); err != nil {
+		return nil, err
+	}
+
+	task, err := s.taskService.GetByIdForUser(ctx, taskId, userId)
+	if err != nil {
+		return nil, fmt.Errorf("s.taskService.GetByIdForUser: %w", err)
+	}
+	if task.State != models.TaskStatePublished || time.Now().Before(task.EffectiveFrom) {
+		return nil, ErrTaskNotStarted
+	}
+
+	questions, err := s.draw(ctx, taskId, userId)
+	if err != nil {
+		return nil, fmt.Errorf("s.draw: %w", err)
+	}
+	return questions, nil
+}
+
+// checkAssigned makes sure the quiz task is assigned to the student.
+func (s *QuizServiceImpl) checkAssigned(ctx context.Context, taskId, userId int64) error {
+	assigned, err := s.taskService.IsAssigned(ctx, taskId, userId)
+	if err != nil {
+		return fmt.Errorf("s.taskService.IsAssigned: %w", err)
+	}
+	if !assigned {
+		return ErrNotTaskAssignee
+	}
+	return nil
+}
+
+// draw picks the student's questions from the bank. The selection is seeded
+// by the task and the student, so it is stable between viewing and submitting
+// without being stored.
+func (s *QuizServiceImpl) draw(
+	ctx context.Context,
+	taskId, userId int64,
+) ([]models.Question, error) {
+	quiz, err := s.repo.GetByTaskId(ctx, taskId)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetByTaskId: %w", err)
+	}
+
+	bank, err := s.bankService.GetById(ctx, quiz.BankId)
+	if err != nil {
+		return nil, fmt.Errorf("s.bankService.GetById: %w", err)
+	}
+
+	questions := slices.Clone(bank.Questions)
+	if quiz.Shuffle {
+		r := rand.New(rand.NewSource(taskId<<32 ^ userId))
+		r.Shuffle(len(questions), func(i, j int) {
+			questions[i], questions[j] = questions[j], questions[i]
+		})
+	}
+	if quiz.QuestionCount != nil && *quiz.QuestionCount < int64(len(questions)) {
+		questions = questions[:*quiz.QuestionCount]
+	}
+	return questions, nil
+}
+
+func (s *QuizServiceImpl) GetResponses(
+	ctx context.Context,
+	answerId int64,
+) ([]models.QuizResponse, error) {
+	responses, err := s.repo.GetResponses(ctx, answerId)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetResponses: %w", err)
+	}
+	return responses, nil
+}
+
+// Submit stores the student's responses as an answer and grades it. The score
+// is scaled to the task cost and goes through the mark service, so late
+// penalties apply and teachers can override it like any other mark. The
+// answer, the responses and the mark are stored in one transaction.
+func (s *QuizServiceImpl) Submit(
+	ctx context.Context,
+	opts QuizServiceSubmitOpts,
+) (models.Mark, error) {
+	if err := s.checkAssigned(ctx, opts.TaskId, opts.UserId); err != nil {
+		return models.Mark{}, err
+	}
+
+	_, err := s.answerService.GetByTaskIdAndUserId(ctx, opts.UserId, opts.TaskId)
+	if err == nil {
+		return models.Mark{}, ErrQuizSubmitted
+	}
+	if !errors.Is(err, repo.ErrNotFound) {
+		return models.Mark{}, fmt.Errorf("s.answerService.GetByTaskIdAndUserId: %w", err)
+	}
+
+	questions, err := s.draw(ctx, opts.TaskId, opts.UserId)
+	if err != nil {
+		return models.Mark{}, fmt.Errorf("s.draw: %w", err)
+	}
+
+	responses := lo.SliceToMap(opts.Responses, func(item models.QuizResponse) (int64, models.QuizResponse) {
+		return item.QuestionId, item
+	})
+	if len(responses) != len(opts.Responses) {
+		return models.Mark{}, ErrInvalidQuizResponse
+	}
+	for id := range responses {
+		if !lo.ContainsBy(questions, func(item models.Question) bool { return item.Id == id }) {
+			return models.Mark{}, ErrInvalidQuizResponse
+		}
+	}
+
+	var earned, total int64
+	scored := make([]models.QuizResponse, 0, len(questions))
+	for _, q := range questions {
+		response := responses[q.Id]
+		response.QuestionId = q.Id
+		response.IsCorrect = scoreQuestion(q, response)
+		response.Points = lo.Ternary(response.IsCorrect, q.Points, 0)
+
+		earned += response.Points
+		total += q.Points
+		scored = append(scored, response)
+	}
+
+	answer, err := s.answerService.Prepare(ctx, AnswerServiceCreateOpts{
+		TaskId: opts.TaskId,
+		UserId: opts.UserId,
+	})
+	if err != nil {
+		return models.Mark{}, fmt.Errorf("s.answerService.Prepare: %w", err)
+	}
+
+	task, err := s.taskService.GetById(ctx, opts.TaskId)
+	if err != nil {
+		return models.Mark{}, fmt.Errorf("s.taskService.GetById: %w", err)
+	}
+
+	var raw int64
+	if total > 0 {
+		raw = int64(math.Round(float64(earned) * float64(task.Cost) / float64(total)))
+	}
+
+	grade, err := s.markService.Grade(ctx, answer, raw)
+	if err != nil {
+		return models.Mark{}, fmt.Errorf("s.markService.Grade: %w", err)
+	}
+
+	mark, err := s.repo.Submit(ctx, repo.QuizzesRepoSubmitOpts{
+		TaskId:      answer.TaskId,
+		UserId:      answer.UserId,
+		TeamId:      answer.TeamId,
+		IsLate:      answer.IsLate,
+		LateSeconds: answer.LateSeconds,
+		Responses:   scored,
+		RawMark:     grade.RawMark,
+		Mark:        grade.Mark,
+	})
+	if err != nil {
+		return models.Mark{}, fmt.Errorf("s.repo.Submit: %w", err)
+	}
+	return mark, nil
+}
+
+// scoreQuestion checks a response against the question's answer key. Choice
+// questions are all-or-nothing: every correct option and nothing else.
+func scoreQuestion(q models.Question, response models.QuizResponse) bool {
+	switch q.Kind {
+	case models.QuestionSingleChoice, models.QuestionMultipleChoice:
+		choices := lo.Uniq(response.Choices)
+		return len(choices) == len(response.Choices) &&
+			len(choices) == len(q.CorrectOptions) &&
+			lo.Every(q.CorrectOptions, choices)
+	case models.QuestionNumeric:
+		if response.Number == nil || q.Answer == nil {
+			return false
+		}
+		return math.Abs(*response.Number-*q.Answer) <= lo.FromPtr(q.Tolerance)
+	case models.QuestionShortText:
+		if response.Text == nil {
+			return false
+		}
+		text := normalizeShortText(*response.Text)
+		return text != "" && lo.ContainsBy(q.AcceptedAnswers, func(item string) bool {
+			return normalizeShortText(item) == text
+		})
+	}
+	return false
+}
+
+// normalizeShortText makes short text comparison ignore case and whitespace
+// differences.
+func normalizeShortText(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrInvalidRubric  = errors.New("invalid rubric")
+	ErrRubricInUse    = errors.New("rubric is in use")
+	ErrRubricNotOwned = errors.New("rubric belongs to another user")
+)
+
+type RubricService interface {
+	GetById(ctx context.Context, id int64) (models.Rubric, error)
+	GetList(ctx context.Context, opts RubricServiceGetListOpts) ([]models.Rubric, error)
+	GetCount(ctx context.Context) (int64, error)
+	Create(ctx context.Context, opts RubricServiceCreateOpts) (models.Rubric, error)
+	Update(ctx context.Context, opts RubricServiceUpdateOpts) (models.Rubric, error)
+	Delete(ctx context.Context, opts RubricServiceDeleteOpts) error
+}
+
+type RubricServiceImpl struct {
+	repo repo.RubricsRepo
+	log  *zerolog.Logger
+}
+
+func NewRubricServiceImpl(
+	repo repo.RubricsRepo,
+	log *zerolog.Logger,
+) *RubricServiceImpl {
+	return &RubricServiceImpl{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (s *RubricServiceImpl) GetById(
+	ctx context.Context,
+	id int64,
+) (models.Rubric, error) {
+	rubric, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return models.Rubric{}, fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	return rubric, nil
+}
+
+func (s *RubricServiceImpl) GetList(
+	ctx context.Context,
+	opts RubricServiceGetListOpts,
+) ([]models.Rubric, error) {
+	rubrics, err := s.repo.GetList(ctx, repo.RubricsRepoGetListOpts{
+		Limit:  opts.Limit,
+		Offset: opts.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetList: %w", err)
+	}
+	return rubrics, nil
+}
+
+func (s *RubricServiceImpl) GetCount(
+	ctx context.Context,
+) (int64, error) {
+	count, err := s.repo.GetCount(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("s.repo.GetCount: %w", err)
+	}
+	return count, nil
+}
+
+func (s *RubricServiceImpl) Create(
+	ctx context.Context,
+	opts RubricServiceCreateOpts,
+) (models.Rubric, error) {
+	if err := validateRubric(opts.Name, opts.Criteria); err != nil {
+		return models.Rubric{}, err
+	}
+
+	rubric, err := s.repo.Create(ctx, repo.RubricsRepoCreateOpts{
+		Name:        opts.Name,
+		Description: opts.Description,
+		CreatedBy:   opts.CreatedBy,
+		Criteria:    opts.Criteria,
+	})
+	if err != nil {
+		return models.Rubric{}, fmt.Errorf("s.repo.Create: %w", err)
+	}
+	return rubric, nil
+}
+
+func (s *RubricServiceImpl) Update(
+	ctx context.Context,
+	opts RubricServiceUpdateOpts,
+) (models.Rubric, error) {
+	if err := validateRubric(opts.Name, opts.Criteria); err != nil {
+		return models.Rubric{}, err
+	}
+	if err := s.checkOwner(ctx, opts.Id, opts.UserId); err != nil {
+		return models.Rubric{}, err
+	}
+
+	// Tasks already using the rubric must still be able to hold its maximum.
+	minCost, err := s.repo.GetMinCost(ctx, opts.Id)
+	if err != nil {
+		return models.Rubric{}, fmt.Errorf("s.repo.GetMinCost: %w", err)
+	}
+	if minCost != nil && rubricMaxPoints(models.Rubric{Criteria: opts.Criteria}) > *minCost {
+		return models.Rubric{}, ErrRubricExceedsCost
+	}
+
+	graded, err := s.repo.IsGraded(ctx, opts.Id)
+	if err != nil {
+		return models.Rubric{}, fmt.Errorf("s.repo.IsGraded: %w", err)
+	}
+	if graded {
+		return models.Rubric{}, ErrRubricInUse
+	}
+
+	rubric, err := s.repo.Update(ctx, repo.RubricsRepoUpdateOpts{
+		Id:          opts.Id,
+		Name:        opts.Name,
+		Description: opts.Description,
+		Criteria:    opts.Criteria,
+	})
+	if err != nil {
+		return models.Rubric{}, fmt.Errorf("s.repo.Update: %w", err)
+	}
+	return rubric, nil
+}
+
+func (s *RubricServiceImpl) Delete(
+	ctx context.Context,
+	opts RubricServiceDeleteOpts,
+) error {
+	if err := s.checkOwner(ctx, opts.Id, opts.UserId); err != nil {
+		return err
+	}
+
+	attached, err := s.repo.IsAttached(ctx, opts.Id)
+	if err != nil {
+		return fmt.Errorf("s.repo.IsAttached: %w", err)
+	}
+	if attached {
+		return ErrRubricInUse
+	}
+
+	if err = s.repo.Delete(ctx, opts.Id); err != nil {
+		return fmt.Errorf("s.repo.Delete: %w", err)
+	}
+	return nil
+}
+
+// checkOwner makes sure only the author of the rubric changes it.
+func (s *RubricServiceImpl) checkOwner(
+	ctx context.Context,
+	id int64,
+	userId int64,
+) error {
+	rubric, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	if rubric.CreatedBy != userId {
+		return ErrRubricNotOwned
+	}
+	return nil
+}
+
+func validateRubric(name string, criteria []models.RubricCriterion) error {
+	if strings.TrimSpace(name) == "" || len(criteria) == 0 {
+		return ErrInvalidRubric
+	}
+	for _, criterion := range criteria {
+		if strings.TrimSpace(criterion.Name) == "" || len(criterion.Levels) == 0 {
+			return ErrInvalidRubric
+		}
+		for _, level := range criterion.Levels {
+			if strings.TrimSpace(level.Name) == "" || level.Points < 0 {
+				return ErrInvalidRubric
+			}
+		}
+	}
+	return nil
+}
+
+// rubricMaxPoints returns the total of the highest level of every criterion.
+func rubricMaxPoints(rubric models.Rubric) int64 {
+	var total int64
+	for _, criterion := range rubric.Criteria {
+		var best int64
+		for _, level := range criterion.Levels {
+			best = max(best, level.Points)
+		}
+		total += best
+	}
+	return total
+}
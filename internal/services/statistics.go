@@ -4,6 +4,7 @@ import (
 	"backend/internal/models"
 	"backend/internal/repo"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/samber/lo"
 	"time"
@@ -11,6 +12,8 @@ import (
 
 var zeroTime = time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)
 
+var ErrInvalidStatisticsLevel = errors.New("invalid statistics level")
+
 type StatisticsService interface {
 	GetStatistics(ctx context.Context, opts GetStatisticsOpts) ([]models.Statistics, error)
 }
@@ -33,12 +36,23 @@ func (s *StatisticsServiceImpl) GetStatistics(
 	if opts.From == nil {
 		opts.From = lo.ToPtr(zeroTime)
 	}
+	switch opts.Level {
+	case "":
+		opts.Level = models.StatisticsLevelUser
+	case models.StatisticsLevelUser, models.StatisticsLevelGroup,
+		models.OrgUnitKindFaculty, models.OrgUnitKindDepartment:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidStatisticsLevel, opts.Level)
+	}
 
 	statistics, err := s.repo.GetStatistics(ctx, repo.GetStatisticsOpts{
-		Limit:  opts.Limit,
-		Offset: opts.Offset,
-		From:   opts.From,
-		To:     opts.To,
+		ScopeUserId: opts.ScopeUserId,
+		Level:       opts.Level,
+		Normalize:   opts.Normalize,
+		Limit:       opts.Limit,
+		Offset:      opts.Offset,
+		From:        opts.From,
+		To:          opts.To,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("s.repo.GetStatistics: %w", err)
@@ -4,6 +4,7 @@ import (
 	"backend/internal/models"
 	"backend/internal/repo"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/samber/lo"
 	"time"
@@ -11,14 +12,37 @@ import (
 	"github.com/rs/zerolog"
 )
 
+var (
+	ErrInvalidLatePolicy = errors.New("invalid late policy")
+	ErrInvalidTaskCost   = errors.New("task cost must be positive")
+	ErrCostBelowMarks    = errors.New("task cost is below marks already given")
+	ErrRubricExceedsCost = errors.New("rubric maximum exceeds task cost")
+	ErrTemplateAssigned  = errors.New("templates cannot have assignees")
+	ErrTemplatePublished = errors.New("templates cannot be published")
+	ErrTaskPublished     = errors.New("task is already published")
+	ErrInvalidExtension  = errors.New("invalid deadline extension")
+	ErrNotTaskAssignee   = errors.New("user or group is not assigned to the task")
+)
+
 type TaskService interface {
 	GetById(ctx context.Context, id int64) (models.Task, error)
+	GetByIdForUser(ctx context.Context, id, userId int64) (models.Task, error)
+	GetLinks(ctx context.Context, id int64) ([]models.TaskLink, error)
+	CanView(ctx context.Context, id, userId int64) (bool, error)
+	IsAssigned(ctx context.Context, id, userId int64) (bool, error)
+	CanManage(ctx context.Context, task models.Task, userId int64, role int) (bool, error)
 	GetListForCreator(ctx context.Context, opts TaskServiceGetListForCreatorOpts) ([]models.Task, error)
-	GetCountForCreator(ctx context.Context, createdBy int64) (int64, error)
-	GetListForUser(ctx context.Context, opts TaskServiceGetListForUserOpts) ([]models.Task, error)
-	GetCountForUser(ctx context.Context, userId, groupId int64) (int64, error)
+	GetCountForCreator(ctx context.Context, opts TaskServiceGetListForCreatorOpts) (int64, error)
+	GetListForUser(ctx context.Context, opts TaskServiceGetListForUserOpts) ([]models.UserTask, error)
+	GetCountForUser(ctx context.Context, opts TaskServiceGetListForUserOpts) (int64, error)
+	GetListForScope(ctx context.Context, opts TaskServiceGetListForScopeOpts) ([]models.Task, error)
+	GetCountForScope(ctx context.Context, opts TaskServiceGetListForScopeOpts) (int64, error)
 	Create(ctx context.Context, opts TaskServiceCreateOpts) (models.Task, error)
+	Clone(ctx context.Context, opts TaskServiceCloneOpts) (models.Task, error)
 	Update(ctx context.Context, opts TaskServiceUpdateOpts) (models.Task, error)
+	Publish(ctx context.Context, opts TaskServicePublishOpts) (models.Task, error)
+	Extend(ctx context.Context, opts TaskServiceExtendOpts) error
+	PublishDue(ctx context.Context) error
 	Delete(ctx context.Context, id int64) error
 }
 
@@ -26,6 +50,9 @@ type TaskServiceImpl struct {
 	repo             repo.TasksRepo
 	filesService     FileService
 	taskLinksService TaskLinksService
+	groupService     GroupService
+	rubricService    RubricService
+	notifier         Notifier
 	log              *zerolog.Logger
 }
 
@@ -33,6 +60,9 @@ func NewTaskServiceImpl(
 	repo repo.TasksRepo,
 	filesService FileService,
 	taskLinksService TaskLinksService,
+	groupService GroupService,
+	rubricService RubricService,
+	notifier Notifier,
 	log *zerolog.Logger,
 ) *TaskServiceImpl {
 	return &TaskServiceImpl{
@@ -40,6 +70,9 @@ func NewTaskServiceImpl(
 		log:              log,
 		filesService:     filesService,
 		taskLinksService: taskLinksService,
+		groupService:     groupService,
+		rubricService:    rubricService,
+		notifier:         notifier,
 	}
 }
 
@@ -54,14 +87,83 @@ func (s *TaskServiceImpl) GetById(
 	return task, nil
 }
 
+// GetByIdForUser returns the task with the dates overridden for the user or
+// the user's group.
+func (s *TaskServiceImpl) GetByIdForUser(
+	ctx context.Context,
+	id, userId int64,
+) (models.Task, error) {
+	task, err := s.repo.GetByIdForUser(ctx, id, userId)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("s.repo.GetByIdForUser: %w", err)
+	}
+	return task, nil
+}
+
+func (s *TaskServiceImpl) GetLinks(
+	ctx context.Context,
+	id int64,
+) ([]models.TaskLink, error) {
+	links, err := s.taskLinksService.GetByTaskId(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("s.taskLinksService.GetByTaskId: %w", err)
+	}
+	return links, nil
+}
+
+// CanView tells whether the staff member sees the task: their own tasks and
+// the tasks assigned to the groups and students they see.
+func (s *TaskServiceImpl) CanView(
+	ctx context.Context,
+	id, userId int64,
+) (bool, error) {
+	visible, err := s.repo.IsVisible(ctx, id, userId)
+	if err != nil {
+		return false, fmt.Errorf("s.repo.IsVisible: %w", err)
+	}
+	return visible, nil
+}
+
+// IsAssigned tells whether the task is assigned to the student, directly or
+// through the student's group.
+func (s *TaskServiceImpl) IsAssigned(
+	ctx context.Context,
+	id, userId int64,
+) (bool, error) {
+	assigned, err := s.repo.IsAssigned(ctx, id, userId)
+	if err != nil {
+		return false, fmt.Errorf("s.repo.IsAssigned: %w", err)
+	}
+	return assigned, nil
+}
+
+// CanManage tells whether the staff member may change the task: its creator
+// or an administrator who sees it.
+func (s *TaskServiceImpl) CanManage(
+	ctx context.Context,
+	task models.Task,
+	userId int64,
+	role int,
+) (bool, error) {
+	if task.CreatedBy == userId {
+		return true, nil
+	}
+	if role != models.UserRoleAdministrator {
+		return false, nil
+	}
+	return s.CanView(ctx, task.Id, userId)
+}
+
 func (s *TaskServiceImpl) GetListForCreator(
 	ctx context.Context,
 	opts TaskServiceGetListForCreatorOpts,
 ) ([]models.Task, error) {
 	tasks, err := s.repo.GetListForCreator(ctx, repo.TasksRepoGetListForCreatorOpts{
-		CreatedBy: opts.CreatedBy,
-		Limit:     opts.Limit,
-		Offset:    opts.Offset,
+		CreatedBy:  opts.CreatedBy,
+		IsTemplate: opts.IsTemplate,
+		Filter:     opts.Filter,
+		Limit:      opts.Limit,
+		Offset:     opts.Offset,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("s.repo.GetListForCreator: %w", err)
@@ -71,9 +173,13 @@ func (s *TaskServiceImpl) GetListForCreator(
 
 func (s *TaskServiceImpl) GetCountForCreator(
 	ctx context.Context,
-	createdBy int64,
+	opts TaskServiceGetListForCreatorOpts,
 ) (int64, error) {
-	count, err := s.repo.GetCountForCreator(ctx, createdBy)
+	count, err := s.repo.GetCountForCreator(ctx, repo.TasksRepoGetListForCreatorOpts{
+		CreatedBy:  opts.CreatedBy,
+		IsTemplate: opts.IsTemplate,
+		Filter:     opts.Filter,
+	})
 	if err != nil {
 		return 0, fmt.Errorf("s.repo.GetCountForCreator: %w", err)
 	}
@@ -83,10 +189,11 @@ func (s *TaskServiceImpl) GetCountForCreator(
 func (s *TaskServiceImpl) GetListForUser(
 	ctx context.Context,
 	opts TaskServiceGetListForUserOpts,
-) ([]models.Task, error) {
+) ([]models.UserTask, error) {
 	tasks, err := s.repo.GetListForUser(ctx, repo.TasksRepoGetListForUserOpts{
 		UserId:  opts.UserId,
 		GroupId: opts.GroupId,
+		Filter:  opts.Filter,
 		Limit:   opts.Limit,
 		Offset:  opts.Offset,
 	})
@@ -98,60 +205,172 @@ func (s *TaskServiceImpl) GetListForUser(
 
 func (s *TaskServiceImpl) GetCountForUser(
 	ctx context.Context,
-	userId, groupId int64,
+	opts TaskServiceGetListForUserOpts,
 ) (int64, error) {
-	count, err := s.repo.GetCountForUser(ctx, userId, groupId)
+	count, err := s.repo.GetCountForUser(ctx, repo.TasksRepoGetListForUserOpts{
+		UserId:  opts.UserId,
+		GroupId: opts.GroupId,
+		Filter:  opts.Filter,
+	})
 	if err != nil {
 		return 0, fmt.Errorf("s.repo.GetCountForUser: %w", err)
 	}
 	return count, nil
 }
 
+func (s *TaskServiceImpl) GetListForScope(
+	ctx context.Context,
+	opts TaskServiceGetListForScopeOpts,
+) ([]models.Task, error) {
+	tasks, err := s.repo.GetListForScope(ctx, repo.TasksRepoGetListForScopeOpts{
+		ScopeUserId: opts.ScopeUserId,
+		Filter:      opts.Filter,
+		Limit:       opts.Limit,
+		Offset:      opts.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetListForScope: %w", err)
+	}
+	return tasks, nil
+}
+
+func (s *TaskServiceImpl) GetCountForScope(
+	ctx context.Context,
+	opts TaskServiceGetListForScopeOpts,
+) (int64, error) {
+	count, err := s.repo.GetCountForScope(ctx, repo.TasksRepoGetListForScopeOpts{
+		ScopeUserId: opts.ScopeUserId,
+		Filter:      opts.Filter,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("s.repo.GetCountForScope: %w", err)
+	}
+	return count, nil
+}
+
 func (s *TaskServiceImpl) Create(
 	ctx context.Context,
 	opts TaskServiceCreateOpts,
 ) (models.Task, error) {
+	if opts.LatePolicy == "" {
+		opts.LatePolicy = models.LatePolicyNone
+	}
+	if err := validateLatePolicy(opts.LatePolicy, opts.LatePenalty, opts.LateGraceHours); err != nil {
+		return models.Task{}, err
+	}
+
+	if opts.IsTemplate && (len(opts.UserIds) > 0 || len(opts.GroupIds) > 0) {
+		return models.Task{}, ErrTemplateAssigned
+	}
+	if opts.Cost == 0 {
+		opts.Cost = models.DefaultTaskCost
+	}
+	if opts.Cost < 0 {
+		return models.Task{}, ErrInvalidTaskCost
+	}
+	if err := s.checkRubric(ctx, opts.RubricId, opts.Cost); err != nil {
+		return models.Task{}, fmt.Errorf("s.checkRubric: %w", err)
+	}
+
 	if opts.EffectiveFrom == nil {
 		opts.EffectiveFrom = lo.ToPtr(time.Now())
 	}
 
+	if opts.ScopeUserId != nil {
+		if err := s.groupService.CheckAccess(ctx, GroupServiceCheckAccessOpts{
+			ScopeUserId: *opts.ScopeUserId,
+			GroupIds:    opts.GroupIds,
+			UserIds:     opts.UserIds,
+		}); err != nil {
+			return models.Task{}, fmt.Errorf("s.groupService.CheckAccess: %w", err)
+		}
+	}
+
+	state, publishAt, publishedAt := publication(opts.Draft, opts.PublishAt)
+
 	task, err := s.repo.Create(ctx, repo.TasksRepoCreateOpts{
-		CreatedBy:     opts.CreatedBy,
-		Title:         opts.Title,
-		Text:          opts.Text,
-		EffectiveFrom: *opts.EffectiveFrom,
-		EffectiveTill: opts.EffectiveTill,
+		CreatedBy:      opts.CreatedBy,
+		Title:          opts.Title,
+		Text:           opts.Text,
+		EffectiveFrom:  *opts.EffectiveFrom,
+		EffectiveTill:  opts.EffectiveTill,
+		LatePolicy:     opts.LatePolicy,
+		LatePenalty:    opts.LatePenalty,
+		LateGraceHours: opts.LateGraceHours,
+		Cost:           opts.Cost,
+		RubricId:       opts.RubricId,
+		IsTemplate:     opts.IsTemplate,
+		SeriesId:       opts.SeriesId,
+		SeriesIndex:    opts.SeriesIndex,
+		State:          state,
+		PublishAt:      publishAt,
+		PublishedAt:    publishedAt,
+		UserIds:        opts.UserIds,
+		GroupIds:       opts.GroupIds,
+		FileIds:        opts.FileIds,
+		CopiedFiles:    opts.CopiedFiles,
 	})
 	if err != nil {
 		return models.Task{}, fmt.Errorf("s.repo.Create: %w", err)
 	}
+	return task, nil
+}
 
-	for _, userId := range opts.UserIds {
-		if err = s.taskLinksService.Create(ctx, TaskLinksServiceCreateOpts{
-			TaskId:  task.Id,
-			UserId:  lo.ToPtr(userId),
-			GroupId: nil,
-		}); err != nil {
-			return models.Task{}, fmt.Errorf("s.taskLinksService.Create: %w", err)
-		}
+// Clone copies the task with its attachments into a new task, shifting its
+// dates so that it starts at opts.EffectiveFrom. The new task and its files
+// are saved in one transaction.
+func (s *TaskServiceImpl) Clone(
+	ctx context.Context,
+	opts TaskServiceCloneOpts,
+) (models.Task, error) {
+	source, err := s.repo.GetById(ctx, opts.Id)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("s.repo.GetById: %w", err)
 	}
 
-	for _, groupId := range opts.GroupIds {
-		if err = s.taskLinksService.Create(ctx, TaskLinksServiceCreateOpts{
-			TaskId:  task.Id,
-			UserId:  nil,
-			GroupId: lo.ToPtr(groupId),
-		}); err != nil {
-			return models.Task{}, fmt.Errorf("s.taskLinksService.Create: %w", err)
-		}
+	var offset time.Duration
+	if opts.EffectiveFrom != nil {
+		offset = opts.EffectiveFrom.Sub(source.EffectiveFrom)
 	}
 
-	for _, fileId := range opts.FileIds {
-		if err = s.filesService.UpdateTaskId(ctx, task.Id, fileId); err != nil {
-			return models.Task{}, fmt.Errorf("s.filesService.UpdateTaskId: %d:%w", fileId, err)
+	files, err := s.filesService.GetByTaskId(ctx, source.Id)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("s.filesService.GetByTaskId: %w", err)
+	}
+	copied := make([]models.File, 0, len(files))
+	for _, file := range files {
+		stored, err := copyStored(file)
+		if err != nil {
+			removeStored(copied)
+			return models.Task{}, fmt.Errorf("copyStored: %d:%w", file.Id, err)
 		}
+		copied = append(copied, stored)
 	}
 
+	task, err := s.Create(ctx, TaskServiceCreateOpts{
+		ScopeUserId:    opts.ScopeUserId,
+		GroupIds:       opts.GroupIds,
+		UserIds:        opts.UserIds,
+		Title:          source.Title,
+		Text:           source.Text,
+		CreatedBy:      opts.CreatedBy,
+		EffectiveFrom:  lo.ToPtr(source.EffectiveFrom.Add(offset)),
+		EffectiveTill:  source.EffectiveTill.Add(offset),
+		LatePolicy:     source.LatePolicy,
+		LatePenalty:    source.LatePenalty,
+		LateGraceHours: source.LateGraceHours,
+		Cost:           source.Cost,
+		RubricId:       source.RubricId,
+		IsTemplate:     opts.AsTemplate,
+		Draft:          opts.Draft,
+		SeriesId:       opts.SeriesId,
+		SeriesIndex:    opts.SeriesIndex,
+		CopiedFiles:    copied,
+	})
+	if err != nil {
+		removeStored(copied)
+		return models.Task{}, fmt.Errorf("s.Create: %w", err)
+	}
 	return task, nil
 }
 
@@ -159,14 +378,65 @@ func (s *TaskServiceImpl) Update(
 	ctx context.Context,
 	opts TaskServiceUpdateOpts,
 ) (models.Task, error) {
+	current, err := s.repo.GetById(ctx, opts.Id)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	if current.IsTemplate && (len(opts.UserIds) > 0 || len(opts.GroupIds) > 0) {
+		return models.Task{}, ErrTemplateAssigned
+	}
+
+	if opts.LatePolicy == "" {
+		opts.LatePolicy = models.LatePolicyNone
+	}
+	if err := validateLatePolicy(opts.LatePolicy, opts.LatePenalty, opts.LateGraceHours); err != nil {
+		return models.Task{}, err
+	}
+	if opts.Cost == 0 {
+		opts.Cost = models.DefaultTaskCost
+	}
+	if opts.Cost < 0 {
+		return models.Task{}, ErrInvalidTaskCost
+	}
+	if opts.Cost < current.Cost {
+		maxMark, err := s.repo.GetMaxMark(ctx, opts.Id)
+		if err != nil {
+			return models.Task{}, fmt.Errorf("s.repo.GetMaxMark: %w", err)
+		}
+		if opts.Cost < maxMark {
+			return models.Task{}, fmt.Errorf("%w: the highest mark is %d", ErrCostBelowMarks, maxMark)
+		}
+	}
+	if err := s.checkRubric(ctx, opts.RubricId, opts.Cost); err != nil {
+		return models.Task{}, fmt.Errorf("s.checkRubric: %w", err)
+	}
+
+	if opts.ScopeUserId != nil {
+		if err := s.groupService.CheckAccess(ctx, GroupServiceCheckAccessOpts{
+			ScopeUserId: *opts.ScopeUserId,
+			GroupIds:    opts.GroupIds,
+			UserIds:     opts.UserIds,
+		}); err != nil {
+			return models.Task{}, fmt.Errorf("s.groupService.CheckAccess: %w", err)
+		}
+	}
+
 	task, err := s.repo.Update(ctx, repo.TasksRepoUpdateOpts{
-		Id:            opts.Id,
-		GroupId:       opts.GroupId,
-		Title:         opts.Title,
-		Text:          opts.Text,
-		EffectiveFrom: opts.EffectiveFrom,
-		EffectiveTill: opts.EffectiveTill,
-		Cost:          opts.Cost,
+		Id:             opts.Id,
+		UpdatedBy:      opts.UpdatedBy,
+		Title:          opts.Title,
+		Text:           opts.Text,
+		EffectiveFrom:  opts.EffectiveFrom,
+		EffectiveTill:  opts.EffectiveTill,
+		LatePolicy:     opts.LatePolicy,
+		LatePenalty:    opts.LatePenalty,
+		LateGraceHours: opts.LateGraceHours,
+		Cost:           opts.Cost,
+		RubricId:       opts.RubricId,
+		UserIds:        lo.Ternary(opts.UserIds == nil, []int64{}, opts.UserIds),
+		GroupIds:       lo.Ternary(opts.GroupIds == nil, []int64{}, opts.GroupIds),
+		FileIds:        lo.Ternary(opts.FileIds == nil, []int64{}, opts.FileIds),
+		ApplyToSeries:  opts.ApplyToSeries,
 	})
 	if err != nil {
 		return models.Task{}, fmt.Errorf("s.repo.Update: %w", err)
@@ -174,6 +444,164 @@ func (s *TaskServiceImpl) Update(
 	return task, nil
 }
 
+// Publish makes the task visible to its assignees at opts.PublishAt, or right
+// away when it is not set or has already passed.
+func (s *TaskServiceImpl) Publish(
+	ctx context.Context,
+	opts TaskServicePublishOpts,
+) (models.Task, error) {
+	current, err := s.repo.GetById(ctx, opts.Id)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	if current.IsTemplate {
+		return models.Task{}, ErrTemplatePublished
+	}
+	if current.State == models.TaskStatePublished {
+		return models.Task{}, ErrTaskPublished
+	}
+
+	state, publishAt, publishedAt := publication(false, opts.PublishAt)
+	task, err := s.repo.SetState(ctx, repo.TasksRepoSetStateOpts{
+		Id:          opts.Id,
+		State:       state,
+		PublishAt:   publishAt,
+		PublishedAt: publishedAt,
+	})
+	if err != nil {
+		return models.Task{}, fmt.Errorf("s.repo.SetState: %w", err)
+	}
+	if task.State == models.TaskStatePublished {
+		s.notifyPublished(ctx, task)
+	}
+	return task, nil
+}
+
+// Extend overrides the task dates for a single user or group already assigned
+// to the task. Nil dates fall back to the ones of the task, so passing none
+// removes the override.
+func (s *TaskServiceImpl) Extend(
+	ctx context.Context,
+	opts TaskServiceExtendOpts,
+) error {
+	if (opts.UserId == nil) == (opts.GroupId == nil) {
+		return ErrInvalidExtension
+	}
+
+	task, err := s.repo.GetById(ctx, opts.Id)
+	if err != nil {
+		return fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	if task.IsTemplate {
+		return ErrTemplateAssigned
+	}
+
+	from := lo.FromPtrOr(opts.EffectiveFrom, task.EffectiveFrom)
+	till := lo.FromPtrOr(opts.EffectiveTill, task.EffectiveTill)
+	if !till.After(from) {
+		return ErrInvalidExtension
+	}
+
+	if opts.ScopeUserId != nil {
+		if err = s.groupService.CheckAccess(ctx, GroupServiceCheckAccessOpts{
+			ScopeUserId: *opts.ScopeUserId,
+			GroupIds:    lo.Compact([]int64{lo.FromPtr(opts.GroupId)}),
+			UserIds:     lo.Compact([]int64{lo.FromPtr(opts.UserId)}),
+		}); err != nil {
+			return fmt.Errorf("s.groupService.CheckAccess: %w", err)
+		}
+	}
+
+	if err = s.taskLinksService.SetWindow(ctx, TaskLinksServiceSetWindowOpts{
+		TaskId:        opts.Id,
+		UserId:        opts.UserId,
+		GroupId:       opts.GroupId,
+		EffectiveFrom: opts.EffectiveFrom,
+		EffectiveTill: opts.EffectiveTill,
+	}); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrNotTaskAssignee
+		}
+		return fmt.Errorf("s.taskLinksService.SetWindow: %w", err)
+	}
+	return nil
+}
+
+func (s *TaskServiceImpl) PublishDue(ctx context.Context) error {
+	tasks, err := s.repo.PublishDue(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("s.repo.PublishDue: %w", err)
+	}
+	for _, task := range tasks {
+		s.notifyPublished(ctx, task)
+	}
+	return nil
+}
+
+// notifyPublished tells the assignees that the task has been published. The
+// task stays published when this fails, so errors are only logged.
+func (s *TaskServiceImpl) notifyPublished(ctx context.Context, task models.Task) {
+	userIds, err := s.taskLinksService.GetAssigneeIds(ctx, task.Id)
+	if err != nil {
+		s.log.Error().Err(err).Int64("taskId", task.Id).Msg("Failed to get task assignees")
+		return
+	}
+	if err = s.notifier.TaskPublished(ctx, task, userIds); err != nil {
+		s.log.Error().Err(err).Int64("taskId", task.Id).Msg("Failed to notify task assignees")
+	}
+}
+
+// publication returns the state a task is saved in together with its
+// scheduled and actual publication times.
+func publication(draft bool, publishAt *time.Time) (string, *time.Time, *time.Time) {
+	now := time.Now()
+	switch {
+	case draft:
+		return models.TaskStateDraft, nil, nil
+	case publishAt != nil && publishAt.After(now):
+		return models.TaskStateScheduled, publishAt, nil
+	}
+	return models.TaskStatePublished, nil, &now
+}
+
+func (s *TaskServiceImpl) checkRubric(
+	ctx context.Context,
+	rubricId *int64,
+	cost int64,
+) error {
+	if rubricId == nil {
+		return nil
+	}
+
+	rubric, err := s.rubricService.GetById(ctx, *rubricId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrInvalidRubric
+		}
+		return fmt.Errorf("s.rubricService.GetById: %w", err)
+	}
+	if rubricMaxPoints(rubric) > cost {
+		return ErrRubricExceedsCost
+	}
+	return nil
+}
+
+func validateLatePolicy(policy string, penalty, graceHours int64) error {
+	if penalty < 0 || graceHours < 0 {
+		return ErrInvalidLatePolicy
+	}
+	switch policy {
+	case models.LatePolicyNone, models.LatePolicyFixed, models.LatePolicyGrace:
+		return nil
+	case models.LatePolicyPercentPerDay:
+		if penalty > 100 {
+			return ErrInvalidLatePolicy
+		}
+		return nil
+	}
+	return ErrInvalidLatePolicy
+}
+
 func (s *TaskServiceImpl) Delete(
 	ctx context.Context,
 	id int64,
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"backend/internal/models"
 	"backend/internal/repo"
 	"context"
 	"fmt"
@@ -8,7 +9,10 @@ import (
 )
 
 type TaskLinksService interface {
+	GetByTaskId(ctx context.Context, taskId int64) ([]models.TaskLink, error)
 	Create(ctx context.Context, opts TaskLinksServiceCreateOpts) error
+	SetWindow(ctx context.Context, opts TaskLinksServiceSetWindowOpts) error
+	GetAssigneeIds(ctx context.Context, taskId int64) ([]int64, error)
 }
 
 type TaskLinksServiceImpl struct {
@@ -26,6 +30,17 @@ func NewTaskLinksServiceImpl(
 	}
 }
 
+func (s *TaskLinksServiceImpl) GetByTaskId(
+	ctx context.Context,
+	taskId int64,
+) ([]models.TaskLink, error) {
+	links, err := s.repo.GetByTaskId(ctx, taskId)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetByTaskId: %w", err)
+	}
+	return links, nil
+}
+
 func (s *TaskLinksServiceImpl) Create(
 	ctx context.Context,
 	opts TaskLinksServiceCreateOpts,
@@ -39,3 +54,32 @@ func (s *TaskLinksServiceImpl) Create(
 	}
 	return nil
 }
+
+func (s *TaskLinksServiceImpl) SetWindow(
+	ctx context.Context,
+	opts TaskLinksServiceSetWindowOpts,
+) error {
+	if err := s.repo.SetWindow(ctx, repo.TaskLinksRepoSetWindowOpts{
+		TaskId:        opts.TaskId,
+		UserId:        opts.UserId,
+		GroupId:       opts.GroupId,
+		EffectiveFrom: opts.EffectiveFrom,
+		EffectiveTill: opts.EffectiveTill,
+	}); err != nil {
+		return fmt.Errorf("s.repo.SetWindow: %w", err)
+	}
+	return nil
+}
+
+// GetAssigneeIds returns the users the task is assigned to, either directly or
+// through one of their groups.
+func (s *TaskLinksServiceImpl) GetAssigneeIds(
+	ctx context.Context,
+	taskId int64,
+) ([]int64, error) {
+	ids, err := s.repo.GetAssigneeIds(ctx, taskId)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetAssigneeIds: %w", err)
+	}
+	return ids, nil
+}
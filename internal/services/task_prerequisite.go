@@ -0,0 +1,140 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+)
+
+var (
+	ErrInvalidPrerequisite = errors.New("invalid prerequisite")
+	ErrPrerequisiteCycle   = repo.ErrCycle
+	ErrTaskLocked          = errors.New("task prerequisites are not met")
+)
+
+type TaskPrerequisiteService interface {
+	GetByTaskId(ctx context.Context, taskId int64) ([]models.TaskPrerequisite, error)
+	GetUnmet(ctx context.Context, userId int64, taskIds []int64) (map[int64][]models.TaskPrerequisite, error)
+	CheckUnlocked(ctx context.Context, taskId, userId int64) error
+	Create(ctx context.Context, opts TaskPrerequisiteServiceCreateOpts) (models.TaskPrerequisite, error)
+	Delete(ctx context.Context, taskId, id int64) error
+}
+
+type TaskPrerequisiteServiceImpl struct {
+	repo        repo.TaskPrerequisitesRepo
+	taskService TaskService
+	log         *zerolog.Logger
+}
+
+func NewTaskPrerequisiteServiceImpl(
+	repo repo.TaskPrerequisitesRepo,
+	taskService TaskService,
+	log *zerolog.Logger,
+) *TaskPrerequisiteServiceImpl {
+	return &TaskPrerequisiteServiceImpl{
+		repo:        repo,
+		taskService: taskService,
+		log:         log,
+	}
+}
+
+func (s *TaskPrerequisiteServiceImpl) GetByTaskId(
+	ctx context.Context,
+	taskId int64,
+) ([]models.TaskPrerequisite, error) {
+	prerequisites, err := s.repo.GetByTaskId(ctx, taskId)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetByTaskId: %w", err)
+	}
+	return prerequisites, nil
+}
+
+// GetUnmet returns the prerequisites the user has not met yet, grouped by the
+// tasks they lock.
+func (s *TaskPrerequisiteServiceImpl) GetUnmet(
+	ctx context.Context,
+	userId int64,
+	taskIds []int64,
+) (map[int64][]models.TaskPrerequisite, error) {
+	if len(taskIds) == 0 {
+		return nil, nil
+	}
+
+	prerequisites, err := s.repo.GetUnmet(ctx, userId, taskIds)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetUnmet: %w", err)
+	}
+	return lo.GroupBy(prerequisites, func(item models.TaskPrerequisite) int64 {
+		return item.TaskId
+	}), nil
+}
+
+func (s *TaskPrerequisiteServiceImpl) CheckUnlocked(
+	ctx context.Context,
+	taskId, userId int64,
+) error {
+	unmet, err := s.repo.GetUnmet(ctx, userId, []int64{taskId})
+	if err != nil {
+		return fmt.Errorf("s.repo.GetUnmet: %w", err)
+	}
+	if len(unmet) > 0 {
+		return ErrTaskLocked
+	}
+	return nil
+}
+
+func (s *TaskPrerequisiteServiceImpl) Create(
+	ctx context.Context,
+	opts TaskPrerequisiteServiceCreateOpts,
+) (models.TaskPrerequisite, error) {
+	if _, err := s.taskService.GetById(ctx, opts.TaskId); err != nil {
+		return models.TaskPrerequisite{}, fmt.Errorf("s.taskService.GetById: %w", err)
+	}
+	prerequisite, err := s.taskService.GetById(ctx, opts.PrerequisiteTaskId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return models.TaskPrerequisite{}, ErrInvalidPrerequisite
+		}
+		return models.TaskPrerequisite{}, fmt.Errorf("s.taskService.GetById: %w", err)
+	}
+	if prerequisite.IsTemplate {
+		return models.TaskPrerequisite{}, ErrInvalidPrerequisite
+	}
+
+	switch opts.Condition {
+	case models.PrerequisiteSubmitted, models.PrerequisiteGraded:
+		opts.MinMark = nil
+	case models.PrerequisiteMarkAtLeast:
+		if opts.MinMark == nil || *opts.MinMark < 0 || *opts.MinMark > prerequisite.Cost {
+			return models.TaskPrerequisite{}, ErrInvalidPrerequisite
+		}
+	default:
+		return models.TaskPrerequisite{}, ErrInvalidPrerequisite
+	}
+
+	created, err := s.repo.Create(ctx, repo.TaskPrerequisitesRepoCreateOpts{
+		TaskId:             opts.TaskId,
+		PrerequisiteTaskId: opts.PrerequisiteTaskId,
+		Condition:          opts.Condition,
+		MinMark:            opts.MinMark,
+	})
+	if err != nil {
+		return models.TaskPrerequisite{}, fmt.Errorf("s.repo.Create: %w", err)
+	}
+	return created, nil
+}
+
+func (s *TaskPrerequisiteServiceImpl) Delete(
+	ctx context.Context,
+	taskId, id int64,
+) error {
+	if err := s.repo.Delete(ctx, taskId, id); err != nil {
+		return fmt.Errorf("s.repo.Delete: %w", err)
+	}
+	return nil
+}
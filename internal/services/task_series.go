@@ -0,0 +1,252 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"backend/pkg/rrule"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+)
+
+var (
+	ErrInvalidRecurrence = rrule.ErrInvalidRule
+	ErrRecurringTemplate = errors.New("templates cannot recur")
+	ErrSeriesCancelled   = errors.New("task series is cancelled")
+)
+
+type TaskSeriesService interface {
+	GetByTaskId(ctx context.Context, taskId int64) (models.TaskSeries, error)
+	SetRule(ctx context.Context, opts TaskSeriesServiceSetRuleOpts) (models.TaskSeries, error)
+	Cancel(ctx context.Context, taskId int64) error
+	GenerateDue(ctx context.Context) error
+}
+
+type TaskSeriesServiceImpl struct {
+	repo        repo.TaskSeriesRepo
+	taskService TaskService
+	lead        time.Duration
+	log         *zerolog.Logger
+}
+
+// NewTaskSeriesServiceImpl creates the service. Each instance of a series is
+// generated lead before it becomes effective.
+func NewTaskSeriesServiceImpl(
+	repo repo.TaskSeriesRepo,
+	taskService TaskService,
+	lead time.Duration,
+	log *zerolog.Logger,
+) *TaskSeriesServiceImpl {
+	return &TaskSeriesServiceImpl{
+		repo:        repo,
+		taskService: taskService,
+		lead:        lead,
+		log:         log,
+	}
+}
+
+func (s *TaskSeriesServiceImpl) GetByTaskId(
+	ctx context.Context,
+	taskId int64,
+) (models.TaskSeries, error) {
+	task, err := s.taskService.GetById(ctx, taskId)
+	if err != nil {
+		return models.TaskSeries{}, fmt.Errorf("s.taskService.GetById: %w", err)
+	}
+	if task.SeriesId == nil {
+		return models.TaskSeries{}, repo.ErrNotFound
+	}
+
+	series, err := s.repo.GetById(ctx, *task.SeriesId)
+	if err != nil {
+		return models.TaskSeries{}, fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	return series, nil
+}
+
+// SetRule makes the task recurring, or changes the rule of the series the task
+// already belongs to. Instances generated before are kept.
+func (s *TaskSeriesServiceImpl) SetRule(
+	ctx context.Context,
+	opts TaskSeriesServiceSetRuleOpts,
+) (models.TaskSeries, error) {
+	rule, err := rrule.Parse(opts.RRule)
+	if err != nil {
+		return models.TaskSeries{}, err
+	}
+
+	task, err := s.taskService.GetById(ctx, opts.TaskId)
+	if err != nil {
+		return models.TaskSeries{}, fmt.Errorf("s.taskService.GetById: %w", err)
+	}
+	if task.IsTemplate {
+		return models.TaskSeries{}, ErrRecurringTemplate
+	}
+
+	if task.SeriesId == nil {
+		series, err := s.repo.Create(ctx, repo.TaskSeriesRepoCreateOpts{
+			SourceTaskId: task.Id,
+			RRule:        opts.RRule,
+			NextRunAt:    s.runAt(rule, task.EffectiveFrom, 1),
+		})
+		if err != nil {
+			return models.TaskSeries{}, fmt.Errorf("s.repo.Create: %w", err)
+		}
+		return series, nil
+	}
+
+	series, err := s.repo.GetById(ctx, *task.SeriesId)
+	if err != nil {
+		return models.TaskSeries{}, fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	if series.CancelledAt != nil {
+		return models.TaskSeries{}, ErrSeriesCancelled
+	}
+
+	source, err := s.taskService.GetById(ctx, series.SourceTaskId)
+	if err != nil {
+		return models.TaskSeries{}, fmt.Errorf("s.taskService.GetById: %w", err)
+	}
+
+	series, err = s.repo.Update(ctx, repo.TaskSeriesRepoUpdateOpts{
+		Id:        series.Id,
+		RRule:     opts.RRule,
+		Generated: series.Generated,
+		NextRunAt: s.runAt(rule, source.EffectiveFrom, int(series.Generated)),
+	})
+	if err != nil {
+		return models.TaskSeries{}, fmt.Errorf("s.repo.Update: %w", err)
+	}
+	return series, nil
+}
+
+func (s *TaskSeriesServiceImpl) Cancel(
+	ctx context.Context,
+	taskId int64,
+) error {
+	series, err := s.GetByTaskId(ctx, taskId)
+	if err != nil {
+		return fmt.Errorf("s.GetByTaskId: %w", err)
+	}
+	if series.CancelledAt != nil {
+		return ErrSeriesCancelled
+	}
+
+	if err = s.repo.Cancel(ctx, series.Id); err != nil {
+		return fmt.Errorf("s.repo.Cancel: %w", err)
+	}
+	return nil
+}
+
+// GenerateDue creates the instances of all series whose next run time has
+// passed. A failing series does not stop the others.
+func (s *TaskSeriesServiceImpl) GenerateDue(ctx context.Context) error {
+	due, err := s.repo.GetDue(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("s.repo.GetDue: %w", err)
+	}
+
+	var errs []error
+	for _, series := range due {
+		if err = s.generate(ctx, series); err != nil {
+			errs = append(errs, fmt.Errorf("s.generate: %d:%w", series.Id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *TaskSeriesServiceImpl) generate(
+	ctx context.Context,
+	series models.TaskSeries,
+) error {
+	rule, err := rrule.Parse(series.RRule)
+	if err != nil {
+		return fmt.Errorf("rrule.Parse: %w", err)
+	}
+
+	source, err := s.taskService.GetById(ctx, series.SourceTaskId)
+	if err != nil {
+		return fmt.Errorf("s.taskService.GetById: %w", err)
+	}
+	links, err := s.taskService.GetLinks(ctx, source.Id)
+	if err != nil {
+		return fmt.Errorf("s.taskService.GetLinks: %w", err)
+	}
+
+	now := time.Now()
+	for series.NextRunAt != nil && !series.NextRunAt.After(now) {
+		index := int(series.Generated)
+		effectiveFrom, ok := rule.Occurrence(source.EffectiveFrom, index)
+		if ok {
+			if err = s.createInstance(ctx, series.Id, source, links, index, effectiveFrom); err != nil {
+				return fmt.Errorf("s.createInstance: %w", err)
+			}
+			index++
+		}
+
+		series, err = s.repo.Update(ctx, repo.TaskSeriesRepoUpdateOpts{
+			Id:        series.Id,
+			RRule:     series.RRule,
+			Generated: int64(index),
+			NextRunAt: s.runAt(rule, source.EffectiveFrom, index),
+		})
+		if err != nil {
+			return fmt.Errorf("s.repo.Update: %w", err)
+		}
+	}
+	return nil
+}
+
+// createInstance clones the source task as the instance with the given index
+// of the series. The series is advanced only after the instance is created,
+// so an instance left behind by a failed advance is not created again.
+func (s *TaskSeriesServiceImpl) createInstance(
+	ctx context.Context,
+	seriesId int64,
+	source models.Task,
+	links []models.TaskLink,
+	index int,
+	effectiveFrom time.Time,
+) error {
+	exists, err := s.repo.HasInstance(ctx, seriesId, int64(index))
+	if err != nil {
+		return fmt.Errorf("s.repo.HasInstance: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	task, err := s.taskService.Clone(ctx, TaskServiceCloneOpts{
+		Id:            source.Id,
+		CreatedBy:     source.CreatedBy,
+		EffectiveFrom: lo.ToPtr(effectiveFrom),
+		Draft:         source.State == models.TaskStateDraft,
+		UserIds: lo.FilterMap(links, func(item models.TaskLink, _ int) (int64, bool) {
+			return lo.FromPtr(item.UserId), item.UserId != nil
+		}),
+		GroupIds: lo.FilterMap(links, func(item models.TaskLink, _ int) (int64, bool) {
+			return lo.FromPtr(item.GroupId), item.GroupId != nil
+		}),
+		SeriesId:    lo.ToPtr(seriesId),
+		SeriesIndex: int64(index),
+	})
+	if err != nil {
+		return fmt.Errorf("s.taskService.Clone: %w", err)
+	}
+	s.log.Info().Int64("seriesId", seriesId).Int64("taskId", task.Id).Msg("Recurring task created")
+	return nil
+}
+
+// runAt returns when the n-th instance of the series has to be generated, or
+// nil when the rule has no such occurrence.
+func (s *TaskSeriesServiceImpl) runAt(rule rrule.Rule, start time.Time, n int) *time.Time {
+	occurrence, ok := rule.Occurrence(start, n)
+	if !ok {
+		return nil
+	}
+	return lo.ToPtr(occurrence.Add(-s.lead))
+}
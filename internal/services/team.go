@@ -0,0 +1,351 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrInvalidTeam        = errors.New("invalid team")
+	ErrAlreadyInTeam      = repo.ErrConflict
+	ErrTeamFull           = repo.ErrLimitExceeded
+	ErrNotInTeam          = errors.New("user is not in a team for this task")
+	ErrTeamTooSmall       = errors.New("team has fewer members than required")
+	ErrTeamLocked         = errors.New("team has already submitted an answer")
+	ErrSelfSignupDisabled = errors.New("team self sign-up is disabled for this task")
+)
+
+type TeamService interface {
+	GetSettings(ctx context.Context, taskId int64) (models.TeamSettings, error)
+	SetSettings(ctx context.Context, opts TeamServiceSetSettingsOpts) (models.TeamSettings, error)
+	DeleteSettings(ctx context.Context, taskId int64) error
+	GetByTaskId(ctx context.Context, taskId int64) ([]models.Team, error)
+	GetById(ctx context.Context, id int64) (models.Team, error)
+	GetByUser(ctx context.Context, taskId, userId int64) (models.Team, error)
+	GetSubmittingTeam(ctx context.Context, taskId, userId int64) (*int64, error)
+	Create(ctx context.Context, opts TeamServiceCreateOpts) (models.Team, error)
+	Delete(ctx context.Context, id int64) error
+	AddMember(ctx context.Context, opts TeamServiceMemberOpts) error
+	RemoveMember(ctx context.Context, opts TeamServiceMemberOpts) error
+	SetAdjustment(ctx context.Context, opts TeamServiceSetAdjustmentOpts) error
+}
+
+type TeamServiceImpl struct {
+	repo        repo.TeamsRepo
+	taskService TaskService
+	userService UserService
+	log         *zerolog.Logger
+}
+
+func NewTeamServiceImpl(
+	repo repo.TeamsRepo,
+	taskService TaskService,
+	userService UserService,
+	log *zerolog.Logger,
+) *TeamServiceImpl {
+	return &TeamServiceImpl{
+		repo:        repo,
+		taskService: taskService,
+		userService: userService,
+		log:         log,
+	}
+}
+
+func (s *TeamServiceImpl) GetSettings(
+	ctx context.Context,
+	taskId int64,
+) (models.TeamSettings, error) {
+	settings, err := s.repo.GetSettings(ctx, taskId)
+	if err != nil {
+		return models.TeamSettings{}, fmt.Errorf("s.repo.GetSettings: %w", err)
+	}
+	return settings, nil
+}
+
+func (s *TeamServiceImpl) SetSettings(
+	ctx context.Context,
+	opts TeamServiceSetSettingsOpts,
+) (models.TeamSettings, error) {
+	task, err := s.taskService.GetById(ctx, opts.TaskId)
+	if err != nil {
+		return models.TeamSettings{}, fmt.Errorf("s.taskService.GetById: %w", err)
+	}
+	if task.IsTemplate {
+		return models.TeamSettings{}, fmt.Errorf("%w: templates cannot be team tasks", ErrInvalidTeam)
+	}
+	if opts.MinSize < 1 || opts.MaxSize < opts.MinSize {
+		return models.TeamSettings{}, fmt.Errorf("%w: sizes must satisfy 1 <= minSize <= maxSize", ErrInvalidTeam)
+	}
+
+	settings, err := s.repo.SetSettings(ctx, repo.TeamsRepoSetSettingsOpts{
+		TaskId:     opts.TaskId,
+		MinSize:    opts.MinSize,
+		MaxSize:    opts.MaxSize,
+		SelfSignup: opts.SelfSignup,
+	})
+	if err != nil {
+		return models.TeamSettings{}, fmt.Errorf("s.repo.SetSettings: %w", err)
+	}
+	return settings, nil
+}
+
+// DeleteSettings turns the task back into an individual one. This is refused
+// once a team has answered, as the answer would lose its other members.
+func (s *TeamServiceImpl) DeleteSettings(
+	ctx context.Context,
+	taskId int64,
+) error {
+	answered, err := s.repo.HasAnswers(ctx, taskId, nil)
+	if err != nil {
+		return fmt.Errorf("s.repo.HasAnswers: %w", err)
+	}
+	if answered {
+		return ErrTeamLocked
+	}
+
+	if err = s.repo.DeleteSettings(ctx, taskId); err != nil {
+		return fmt.Errorf("s.repo.DeleteSettings: %w", err)
+	}
+	return nil
+}
+
+func (s *TeamServiceImpl) GetByTaskId(
+	ctx context.Context,
+	taskId int64,
+) ([]models.Team, error) {
+	teams, err := s.repo.GetByTaskId(ctx, taskId)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetByTaskId: %w", err)
+	}
+	return teams, nil
+}
+
+func (s *TeamServiceImpl) GetById(
+	ctx context.Context,
+	id int64,
+) (models.Team, error) {
+	team, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return models.Team{}, fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	return team, nil
+}
+
+func (s *TeamServiceImpl) GetByUser(
+	ctx context.Context,
+	taskId, userId int64,
+) (models.Team, error) {
+	team, err := s.repo.GetByUser(ctx, taskId, userId)
+	if err != nil {
+		return models.Team{}, fmt.Errorf("s.repo.GetByUser: %w", err)
+	}
+	return team, nil
+}
+
+// GetSubmittingTeam returns the team the user submits for on the task, or nil
+// when the task is an individual one. On a team task the user must be in a
+// team that has reached the minimum size.
+func (s *TeamServiceImpl) GetSubmittingTeam(
+	ctx context.Context,
+	taskId, userId int64,
+) (*int64, error) {
+	settings, err := s.repo.GetSettings(ctx, taskId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("s.repo.GetSettings: %w", err)
+	}
+
+	team, err := s.repo.GetByUser(ctx, taskId, userId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrNotInTeam
+		}
+		return nil, fmt.Errorf("s.repo.GetByUser: %w", err)
+	}
+	if int64(len(team.Members)) < settings.MinSize {
+		return nil, ErrTeamTooSmall
+	}
+	return &team.Id, nil
+}
+
+// Create adds a team to the task. A team created through self sign-up gets
+// the creating student as its only member.
+func (s *TeamServiceImpl) Create(
+	ctx context.Context,
+	opts TeamServiceCreateOpts,
+) (models.Team, error) {
+	settings, err := s.repo.GetSettings(ctx, opts.TaskId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return models.Team{}, fmt.Errorf("%w: task %d is not a team task", ErrInvalidTeam, opts.TaskId)
+		}
+		return models.Team{}, fmt.Errorf("s.repo.GetSettings: %w", err)
+	}
+	if opts.SelfSignup && !settings.SelfSignup {
+		return models.Team{}, ErrSelfSignupDisabled
+	}
+
+	opts.Name = strings.TrimSpace(opts.Name)
+	if opts.Name == "" {
+		return models.Team{}, fmt.Errorf("%w: name is required", ErrInvalidTeam)
+	}
+	if err = s.checkStudents(ctx, opts.TaskId, opts.UserIds); err != nil {
+		return models.Team{}, err
+	}
+
+	team, err := s.repo.Create(ctx, repo.TeamsRepoCreateOpts{
+		TaskId:  opts.TaskId,
+		Name:    opts.Name,
+		UserIds: opts.UserIds,
+		MaxSize: settings.MaxSize,
+	})
+	if err != nil {
+		return models.Team{}, fmt.Errorf("s.repo.Create: %w", err)
+	}
+	return team, nil
+}
+
+func (s *TeamServiceImpl) Delete(
+	ctx context.Context,
+	id int64,
+) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("s.repo.Delete: %w", err)
+	}
+	return nil
+}
+
+// AddMember adds the user to the team. Students signing themselves up may do
+// so only when the task allows it and until the team has submitted.
+func (s *TeamServiceImpl) AddMember(
+	ctx context.Context,
+	opts TeamServiceMemberOpts,
+) error {
+	team, settings, err := s.getForChange(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if err = s.checkStudents(ctx, team.TaskId, []int64{opts.UserId}); err != nil {
+		return err
+	}
+
+	if err = s.repo.AddMember(ctx, repo.TeamsRepoAddMemberOpts{
+		TeamId:  team.Id,
+		UserId:  opts.UserId,
+		MaxSize: settings.MaxSize,
+	}); err != nil {
+		return fmt.Errorf("s.repo.AddMember: %w", err)
+	}
+	return nil
+}
+
+func (s *TeamServiceImpl) RemoveMember(
+	ctx context.Context,
+	opts TeamServiceMemberOpts,
+) error {
+	if _, _, err := s.getForChange(ctx, opts); err != nil {
+		return err
+	}
+
+	if err := s.repo.RemoveMember(ctx, opts.TeamId, opts.UserId); err != nil {
+		return fmt.Errorf("s.repo.RemoveMember: %w", err)
+	}
+	return nil
+}
+
+func (s *TeamServiceImpl) getForChange(
+	ctx context.Context,
+	opts TeamServiceMemberOpts,
+) (models.Team, models.TeamSettings, error) {
+	team, err := s.repo.GetById(ctx, opts.TeamId)
+	if err != nil {
+		return models.Team{}, models.TeamSettings{}, fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	settings, err := s.repo.GetSettings(ctx, team.TaskId)
+	if err != nil {
+		return models.Team{}, models.TeamSettings{}, fmt.Errorf("s.repo.GetSettings: %w", err)
+	}
+	if !opts.SelfSignup {
+		return team, settings, nil
+	}
+
+	if !settings.SelfSignup {
+		return models.Team{}, models.TeamSettings{}, ErrSelfSignupDisabled
+	}
+	answered, err := s.repo.HasAnswers(ctx, team.TaskId, &team.Id)
+	if err != nil {
+		return models.Team{}, models.TeamSettings{}, fmt.Errorf("s.repo.HasAnswers: %w", err)
+	}
+	if answered {
+		return models.Team{}, models.TeamSettings{}, ErrTeamLocked
+	}
+	return team, settings, nil
+}
+
+// checkStudents makes sure that every user is a student the task is assigned
+// to, directly or through their group.
+func (s *TeamServiceImpl) checkStudents(
+	ctx context.Context,
+	taskId int64,
+	userIds []int64,
+) error {
+	for _, userId := range userIds {
+		user, err := s.userService.GetById(ctx, userId)
+		if err != nil {
+			if errors.Is(err, repo.ErrNotFound) {
+				return fmt.Errorf("%w: user %d not found", ErrInvalidTeam, userId)
+			}
+			return fmt.Errorf("s.userService.GetById: %w", err)
+		}
+		if user.RoleId != models.UserRoleStudent {
+			return fmt.Errorf("%w: user %d is not a student", ErrInvalidTeam, userId)
+		}
+
+		assigned, err := s.taskService.IsAssigned(ctx, taskId, userId)
+		if err != nil {
+			return fmt.Errorf("s.taskService.IsAssigned: %w", err)
+		}
+		if !assigned {
+			return fmt.Errorf("%w: task is not assigned to user %d", ErrInvalidTeam, userId)
+		}
+	}
+	return nil
+}
+
+// SetAdjustment changes the mark of one member of the team by at most the
+// cost of the task. The adjusted mark itself never leaves the range from zero
+// to the cost.
+func (s *TeamServiceImpl) SetAdjustment(
+	ctx context.Context,
+	opts TeamServiceSetAdjustmentOpts,
+) error {
+	team, err := s.repo.GetById(ctx, opts.TeamId)
+	if err != nil {
+		return fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	task, err := s.taskService.GetById(ctx, team.TaskId)
+	if err != nil {
+		return fmt.Errorf("s.taskService.GetById: %w", err)
+	}
+	if opts.Adjustment < -task.Cost || opts.Adjustment > task.Cost {
+		return fmt.Errorf("%w: adjustment must be between %d and %d", ErrInvalidTeam, -task.Cost, task.Cost)
+	}
+
+	if err = s.repo.SetAdjustment(ctx, repo.TeamsRepoSetAdjustmentOpts{
+		TeamId:     opts.TeamId,
+		UserId:     opts.UserId,
+		Adjustment: opts.Adjustment,
+		Comment:    opts.Comment,
+	}); err != nil {
+		return fmt.Errorf("s.repo.SetAdjustment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+var ErrInvalidTrashKind = errors.New("invalid trash item kind")
+
+type TrashService interface {
+	GetList(ctx context.Context, opts TrashServiceGetListOpts) ([]models.TrashItem, error)
+	GetCount(ctx context.Context, opts TrashServiceGetListOpts) (int64, error)
+	Restore(ctx context.Context, opts TrashServiceRestoreOpts) error
+	Purge(ctx context.Context) error
+}
+
+type TrashServiceImpl struct {
+	repo      repo.TrashRepo
+	retention time.Duration
+	log       *zerolog.Logger
+}
+
+func NewTrashServiceImpl(
+	repo repo.TrashRepo,
+	retention time.Duration,
+	log *zerolog.Logger,
+) *TrashServiceImpl {
+	return &TrashServiceImpl{
+		repo:      repo,
+		retention: retention,
+		log:       log,
+	}
+}
+
+func (s *TrashServiceImpl) GetList(
+	ctx context.Context,
+	opts TrashServiceGetListOpts,
+) ([]models.TrashItem, error) {
+	items, err := s.repo.GetList(ctx, repo.TrashRepoGetListOpts{
+		UserId:      opts.UserId,
+		ScopeUserId: opts.ScopeUserId,
+		Limit:       opts.Limit,
+		Offset:      opts.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetList: %w", err)
+	}
+	return items, nil
+}
+
+func (s *TrashServiceImpl) GetCount(
+	ctx context.Context,
+	opts TrashServiceGetListOpts,
+) (int64, error) {
+	count, err := s.repo.GetCount(ctx, repo.TrashRepoGetListOpts{
+		UserId:      opts.UserId,
+		ScopeUserId: opts.ScopeUserId,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("s.repo.GetCount: %w", err)
+	}
+	return count, nil
+}
+
+func (s *TrashServiceImpl) Restore(
+	ctx context.Context,
+	opts TrashServiceRestoreOpts,
+) error {
+	switch opts.Kind {
+	case models.TrashKindTask, models.TrashKindAnswer, models.TrashKindFile, models.TrashKindGroup:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidTrashKind, opts.Kind)
+	}
+
+	if err := s.repo.Restore(ctx, repo.TrashRepoRestoreOpts{
+		Kind:        opts.Kind,
+		Id:          opts.Id,
+		UserId:      opts.UserId,
+		ScopeUserId: opts.ScopeUserId,
+	}); err != nil {
+		return fmt.Errorf("s.repo.Restore: %w", err)
+	}
+	return nil
+}
+
+// Purge removes the items that have stayed in the trash longer than the
+// retention period. Stored contents of the purged files are removed after the
+// rows are gone; a failure there only leaves an orphan on disk and is logged.
+func (s *TrashServiceImpl) Purge(ctx context.Context) error {
+	files, err := s.repo.Purge(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		return fmt.Errorf("s.repo.Purge: %w", err)
+	}
+
+	for _, file := range files {
+		if err = os.Remove(filepath.Join(file.Filepath, file.Filename)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.log.Error().Err(err).Int64("fileId", file.Id).Msg("Failed to remove purged file")
+		}
+	}
+	if len(files) > 0 {
+		s.log.Info().Int("files", len(files)).Msg("Trash purged")
+	}
+	return nil
+}
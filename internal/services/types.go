@@ -1,45 +1,104 @@
 package services
 
-import "time"
+import (
+	"backend/internal/models"
+	"time"
+)
 
 type (
 	TaskServiceGetListForCreatorOpts struct {
-		CreatedBy int64
-		Limit     int64
-		Offset    int64
+		CreatedBy  int64
+		IsTemplate bool
+		Filter     models.Filter
+		Limit      int64
+		Offset     int64
 	}
 	TaskServiceGetListForUserOpts struct {
 		UserId  int64
 		GroupId int64
+		Filter  models.Filter
 		Limit   int64
 		Offset  int64
 	}
+	TaskServiceGetListForScopeOpts struct {
+		ScopeUserId int64
+		Filter      models.Filter
+		Limit       int64
+		Offset      int64
+	}
 	TaskServiceCreateOpts struct {
-		GroupIds      []int64
-		UserIds       []int64
-		Title         string
-		Text          *string
+		ScopeUserId    *int64
+		GroupIds       []int64
+		UserIds        []int64
+		Title          string
+		Text           *string
+		CreatedBy      int64
+		EffectiveFrom  *time.Time
+		EffectiveTill  time.Time
+		LatePolicy     string
+		LatePenalty    int64
+		LateGraceHours int64
+		Cost           int64
+		RubricId       *int64
+		IsTemplate     bool
+		FileIds        []int64
+		CopiedFiles    []models.File
+		SeriesId       *int64
+		SeriesIndex    int64
+		Draft          bool
+		PublishAt      *time.Time
+	}
+	TaskServiceCloneOpts struct {
+		Id            int64
+		ScopeUserId   *int64
 		CreatedBy     int64
+		AsTemplate    bool
 		EffectiveFrom *time.Time
-		EffectiveTill time.Time
-		FileIds       []int64
+		UserIds       []int64
+		GroupIds      []int64
+		SeriesId      *int64
+		SeriesIndex   int64
+		Draft         bool
 	}
 	TaskServiceUpdateOpts struct {
+		Id             int64
+		UpdatedBy      int64
+		ScopeUserId    *int64
+		Title          string
+		Text           string
+		EffectiveFrom  time.Time
+		EffectiveTill  time.Time
+		LatePolicy     string
+		LatePenalty    int64
+		LateGraceHours int64
+		Cost           int64
+		RubricId       *int64
+		UserIds        []int64
+		GroupIds       []int64
+		FileIds        []int64
+		ApplyToSeries  bool
+	}
+	TaskServicePublishOpts struct {
+		Id        int64
+		PublishAt *time.Time
+	}
+	TaskServiceExtendOpts struct {
 		Id            int64
-		GroupId       int64
-		Title         string
-		Text          string
-		EffectiveFrom time.Time
-		EffectiveTill time.Time
-		Cost          int64
+		ScopeUserId   *int64
+		UserId        *int64
+		GroupId       *int64
+		EffectiveFrom *time.Time
+		EffectiveTill *time.Time
 	}
 )
 
 type (
 	AnswerServiceGetListOpts struct {
-		TaskId int64
-		Limit  int64
-		Offset int64
+		TaskId      int64
+		ScopeUserId *int64
+		Filter      models.Filter
+		Limit       int64
+		Offset      int64
 	}
 	AnswerServiceCreateOpts struct {
 		TaskId  int64
@@ -56,52 +115,98 @@ type (
 
 type (
 	GroupServiceGetListOpts struct {
-		Limit  int64
-		Offset int64
+		ScopeUserId *int64
+		Filter      models.Filter
+		Limit       int64
+		Offset      int64
 	}
 	GroupServiceCreateOpts struct {
-		Name string
+		ScopeUserId int64
+		Name        string
+		OrgUnitId   *int64
 	}
 	GroupServiceUpdateOpts struct {
-		Id   int64
-		Name string
+		Id          int64
+		ScopeUserId int64
+		Name        string
+		OrgUnitId   *int64
+	}
+	GroupServiceDeleteOpts struct {
+		Id          int64
+		ScopeUserId int64
+	}
+	GroupServiceAssignmentOpts struct {
+		ScopeUserId int64
+		UserId      int64
+		GroupId     int64
+	}
+	GroupServiceCheckAccessOpts struct {
+		ScopeUserId int64
+		GroupIds    []int64
+		UserIds     []int64
 	}
 )
 
 type (
 	FileServiceCreateOpts struct {
-		Name     string
-		Filename string
-		Filepath string
-		TaskId   *int64
-		AnswerId *int64
+		Name      string
+		Filename  string
+		Filepath  string
+		TaskId    *int64
+		AnswerId  *int64
+		CreatedBy *int64
 	}
 )
 
 type (
 	MarkServiceGetListByUserIdOpts struct {
-		UserId int64
-		Limit  int64
-		Offset int64
+		UserId      int64
+		ScopeUserId *int64
+		Filter      models.Filter
+		Limit       int64
+		Offset      int64
 	}
 	MarkServiceCreateOpts struct {
 		AnswerId int64
 		Mark     int64
 		Comment  *string
+		Criteria []models.MarkCriterion
 	}
 	MarkServiceUpdateOpts struct {
-		Id      int64
-		Mark    int64
-		Comment *string
+		Id       int64
+		Mark     int64
+		Comment  *string
+		Criteria []models.MarkCriterion
 	}
 )
 
 type (
-	UserServiceGetListByRoleIdOpts struct {
-		RoleId int64
+	UserServiceGetListOpts struct {
+		Filter models.Filter
 		Limit  int64
 		Offset int64
 	}
+	UserServiceTransferOpts struct {
+		UserId        int64
+		GroupId       int64
+		EffectiveFrom *time.Time
+	}
+)
+
+type (
+	TaskSeriesServiceSetRuleOpts struct {
+		TaskId int64
+		RRule  string
+	}
+)
+
+type (
+	TaskPrerequisiteServiceCreateOpts struct {
+		TaskId             int64
+		PrerequisiteTaskId int64
+		Condition          string
+		MinMark            *int64
+	}
 )
 
 type (
@@ -110,13 +215,223 @@ type (
 		UserId  *int64
 		GroupId *int64
 	}
+	TaskLinksServiceSetWindowOpts struct {
+		TaskId        int64
+		UserId        *int64
+		GroupId       *int64
+		EffectiveFrom *time.Time
+		EffectiveTill *time.Time
+	}
+)
+
+type (
+	OrgUnitServiceCreateOpts struct {
+		ScopeUserId int64
+		ParentId    *int64
+		Kind        string
+		Name        string
+	}
+	OrgUnitServiceUpdateOpts struct {
+		Id          int64
+		ScopeUserId int64
+		ParentId    *int64
+		Kind        string
+		Name        string
+	}
+	OrgUnitServiceDeleteOpts struct {
+		Id          int64
+		ScopeUserId int64
+	}
+	OrgUnitServiceAdminOpts struct {
+		ScopeUserId int64
+		UserId      int64
+		OrgUnitId   int64
+	}
 )
 
 type (
 	GetStatisticsOpts struct {
+		ScopeUserId *int64
+		Level       string
+		Normalize   bool
+		Limit       int64
+		Offset      int64
+		From        *time.Time
+		To          *time.Time
+	}
+)
+
+type (
+	RubricServiceGetListOpts struct {
+		Limit  int64
+		Offset int64
+	}
+	RubricServiceCreateOpts struct {
+		Name        string
+		Description *string
+		CreatedBy   int64
+		Criteria    []models.RubricCriterion
+	}
+	RubricServiceUpdateOpts struct {
+		Id          int64
+		UserId      int64
+		Name        string
+		Description *string
+		Criteria    []models.RubricCriterion
+	}
+	RubricServiceDeleteOpts struct {
+		Id     int64
+		UserId int64
+	}
+)
+
+type (
+	QuestionBankServiceGetListOpts struct {
 		Limit  int64
 		Offset int64
-		From   *time.Time
-		To     *time.Time
+	}
+	QuestionBankServiceCreateOpts struct {
+		Name        string
+		Description *string
+		CreatedBy   int64
+		Questions   []models.Question
+	}
+	QuestionBankServiceUpdateOpts struct {
+		Id          int64
+		UserId      int64
+		Name        string
+		Description *string
+		Questions   []models.Question
+	}
+	QuestionBankServiceDeleteOpts struct {
+		Id     int64
+		UserId int64
+	}
+)
+
+type (
+	QuizServiceSetOpts struct {
+		TaskId        int64
+		BankId        int64
+		QuestionCount *int64
+		Shuffle       bool
+	}
+	QuizServiceSubmitOpts struct {
+		TaskId    int64
+		UserId    int64
+		Responses []models.QuizResponse
+	}
+)
+
+type (
+	CheckerServiceSetOpts struct {
+		TaskId      int64
+		EditorId    int64
+		Kind        string
+		Command     string
+		TestsFileId int64
+		TimeoutMs   int64
+		MemoryMb    int64
+	}
+)
+
+type (
+	TrashServiceGetListOpts struct {
+		UserId      int64
+		ScopeUserId *int64
+		Limit       int64
+		Offset      int64
+	}
+	TrashServiceRestoreOpts struct {
+		Kind        string
+		Id          int64
+		UserId      int64
+		ScopeUserId *int64
+	}
+)
+
+type (
+	TeamServiceSetSettingsOpts struct {
+		TaskId     int64
+		MinSize    int64
+		MaxSize    int64
+		SelfSignup bool
+	}
+	TeamServiceCreateOpts struct {
+		TaskId  int64
+		Name    string
+		UserIds []int64
+		// SelfSignup marks a team created by a student for themselves.
+		SelfSignup bool
+	}
+	TeamServiceMemberOpts struct {
+		TeamId int64
+		UserId int64
+		// SelfSignup marks a student joining or leaving a team on their own.
+		SelfSignup bool
+	}
+	TeamServiceSetAdjustmentOpts struct {
+		TeamId     int64
+		UserId     int64
+		Adjustment int64
+		Comment    *string
+	}
+)
+
+type (
+	CommentServiceGetListOpts struct {
+		TaskId   int64
+		AnswerId *int64
+		Limit    int64
+		Offset   int64
+	}
+	CommentServiceCreateOpts struct {
+		TaskId   int64
+		AnswerId *int64
+		AuthorId int64
+		Text     string
+		FileIds  []int64
+	}
+	CommentServiceUpdateOpts struct {
+		Id      int64
+		Text    string
+		FileIds []int64
+	}
+	CommentServiceThreadOpts struct {
+		UserId   int64
+		TaskId   int64
+		AnswerId *int64
+	}
+	CommentServiceGetUnreadOpts struct {
+		UserId     int64
+		TaskId     int64
+		AllAnswers bool
+	}
+)
+
+type (
+	PeerReviewServiceSetSettingsOpts struct {
+		TaskId           int64
+		ReviewsPerAnswer int64
+		RubricId         *int64
+		MarkWeight       int64
+	}
+	PeerReviewServiceGetListOpts struct {
+		TaskId        int64
+		ReviewerId    *int64
+		AnswerId      *int64
+		SubmittedOnly bool
+	}
+	PeerReviewServiceSubmitOpts struct {
+		Id         int64
+		ReviewerId int64
+		Score      *int64
+		Text       *string
+		Criteria   []models.MarkCriterion
+	}
+	PeerReviewServiceModerateOpts struct {
+		Id       int64
+		Rejected bool
+		Comment  *string
 	}
 )
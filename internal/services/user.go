@@ -6,20 +6,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/rs/zerolog"
 )
 
 var (
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrInvalidTransfer = errors.New("invalid transfer")
 )
 
 type UserService interface {
 	Create(ctx context.Context, opts UserServiceCreateOpts) (models.User, error)
 	GetById(ctx context.Context, id int64) (models.User, error)
 	GetByCredentials(ctx context.Context, credentials models.Credentials) (models.User, error)
-	GetListByRoleId(ctx context.Context, opts UserServiceGetListByRoleIdOpts) ([]models.User, error)
-	GetListByRoleIdCount(ctx context.Context, opts UserServiceGetListByRoleIdOpts) (int, error)
+	GetList(ctx context.Context, opts UserServiceGetListOpts) ([]models.User, error)
+	GetCount(ctx context.Context, filter models.Filter) (int64, error)
+	GetMemberships(ctx context.Context, userId int64) ([]models.GroupMembership, error)
+	Transfer(ctx context.Context, opts UserServiceTransferOpts) error
 }
 
 type UserServiceImpl struct {
@@ -65,36 +69,93 @@ func (s *UserServiceImpl) GetByCredentials(
 	return user, nil
 }
 
-func (s *UserServiceImpl) GetListByRoleId(
+func (s *UserServiceImpl) GetList(
 	ctx context.Context,
-	opts UserServiceGetListByRoleIdOpts,
+	opts UserServiceGetListOpts,
 ) ([]models.User, error) {
-	users, err := s.repo.GetListByRoleId(ctx, repo.UsersRepoGetListByRoleIdOpts{
-		RoleId: opts.RoleId,
+	users, err := s.repo.GetList(ctx, repo.UsersRepoGetListOpts{
+		Filter: opts.Filter,
 		Limit:  opts.Limit,
 		Offset: opts.Offset,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("s.repo.GetListByRoleId: %w", err)
+		return nil, fmt.Errorf("s.repo.GetList: %w", err)
 	}
 	return users, nil
 }
 
-func (s *UserServiceImpl) GetListByRoleIdCount(
+func (s *UserServiceImpl) GetCount(
 	ctx context.Context,
-	opts UserServiceGetListByRoleIdOpts,
-) (int, error) {
-	count, err := s.repo.GetListByRoleIdCount(ctx, repo.UsersRepoGetListByRoleIdOpts{
-		RoleId: opts.RoleId,
-		Limit:  opts.Limit,
-		Offset: opts.Offset,
-	})
+	filter models.Filter,
+) (int64, error) {
+	count, err := s.repo.GetCount(ctx, filter)
 	if err != nil {
-		return 0, fmt.Errorf("s.repo.GetListByRoleIdCount: %w", err)
+		return 0, fmt.Errorf("s.repo.GetCount: %w", err)
 	}
 	return count, nil
 }
 
+func (s *UserServiceImpl) GetMemberships(
+	ctx context.Context,
+	userId int64,
+) ([]models.GroupMembership, error) {
+	memberships, err := s.repo.GetMemberships(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("s.repo.GetMemberships: %w", err)
+	}
+	return memberships, nil
+}
+
+func (s *UserServiceImpl) Transfer(
+	ctx context.Context,
+	opts UserServiceTransferOpts,
+) error {
+	now := time.Now()
+	if opts.EffectiveFrom == nil {
+		opts.EffectiveFrom = &now
+	}
+	if opts.EffectiveFrom.After(now) {
+		return fmt.Errorf("%w: effective date is in the future", ErrInvalidTransfer)
+	}
+
+	user, err := s.repo.GetById(ctx, opts.UserId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("s.repo.GetById: %w", err)
+	}
+	if user.RoleId != models.UserRoleStudent {
+		return fmt.Errorf("%w: only students belong to groups", ErrInvalidTransfer)
+	}
+
+	memberships, err := s.repo.GetMemberships(ctx, opts.UserId)
+	if err != nil {
+		return fmt.Errorf("s.repo.GetMemberships: %w", err)
+	}
+	if len(memberships) > 0 && memberships[0].EffectiveTill == nil {
+		current := memberships[0]
+		if current.GroupId == opts.GroupId {
+			return fmt.Errorf("%w: user is already in group %d", ErrInvalidTransfer, opts.GroupId)
+		}
+		if !opts.EffectiveFrom.After(current.EffectiveFrom) {
+			return fmt.Errorf("%w: effective date precedes current membership", ErrInvalidTransfer)
+		}
+	}
+
+	if err = s.repo.Transfer(ctx, repo.UsersRepoTransferOpts{
+		UserId:        opts.UserId,
+		GroupId:       opts.GroupId,
+		EffectiveFrom: *opts.EffectiveFrom,
+	}); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fmt.Errorf("%w: group %d not found", ErrInvalidTransfer, opts.GroupId)
+		}
+		return fmt.Errorf("s.repo.Transfer: %w", err)
+	}
+	return nil
+}
+
 type UserServiceCreateOpts struct {
 	GroupId    *int64
 	RoleId     int64
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"backend/internal/models"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/samber/lo"
@@ -46,3 +48,18 @@ func GetClaimsFromCtx(ctx *fiber.Ctx) (Claims, error) {
 
 	return result, nil
 }
+
+// ScopeUserId returns the user whose group assignments or administered org
+// units limit the students visible to the caller, or nil when the caller is
+// not limited.
+func (c Claims) ScopeUserId() *int64 {
+	if c.Role == models.UserRoleStudent {
+		return nil
+	}
+	return lo.ToPtr(c.UserId)
+}
+
+// ReadOnly reports whether the caller may only use safe http methods.
+func (c Claims) ReadOnly() bool {
+	return c.Role == models.UserRoleObserver
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"backend/internal/models"
+	"strings"
+
+	jwtware "github.com/gofiber/contrib/jwt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+)
+
+func New(cfg models.JWTConfig, log *zerolog.Logger) fiber.Handler {
+	return jwtware.New(jwtware.Config{
+		SigningKey: jwtware.SigningKey{
+			Key: []byte(cfg.JWTAccessSecretKey),
+		},
+		SuccessHandler: func(ctx *fiber.Ctx) error {
+			authorizationHeaderValue := ctx.Get(fiber.HeaderAuthorization)
+			token := strings.Split(authorizationHeaderValue, "Bearer ")[1]
+			if len(token) == 0 {
+				return fiber.NewError(fiber.StatusUnauthorized, "missed jwt token")
+			}
+
+			claims := jwt.MapClaims{}
+			_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+				return []byte(cfg.JWTAccessSecretKey), nil
+			})
+			if err != nil {
+				log.Error().Err(err).Send()
+				return fiber.NewError(fiber.StatusUnauthorized, "err token parse")
+			}
+
+			ctx.Locals("claims", claims)
+			return authorize(ctx)
+		},
+	})
+}
+
+func authorize(ctx *fiber.Ctx) error {
+	claims, err := GetClaimsFromCtx(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch ctx.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+	default:
+		if claims.ReadOnly() {
+			return fiber.NewError(fiber.StatusForbidden, "Read-only access")
+		}
+	}
+
+	return ctx.Next()
+}
+
+func RequireRoles(roles ...int) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		claims, err := GetClaimsFromCtx(ctx)
+		if err != nil {
+			return err
+		}
+		for _, role := range roles {
+			if claims.Role == role {
+				return ctx.Next()
+			}
+		}
+		return fiber.NewError(fiber.StatusForbidden, "Access denied")
+	}
+}
@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"backend/internal/models"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FromQuery reads a filter from the query string. Every field may be given as
+// "field=value" for equality or as "field[op]=value" with one of
+// models.FilterOps, e.g. "createdAt[gte]=2024-09-01T00:00:00Z" or
+// "status[in]=late,graded". Sorting is a comma separated list of fields, a
+// leading "-" sorts descending: "sort=-deadline,id".
+func FromQuery(ctx *fiber.Ctx, fields []string) models.Filter {
+	filter := models.Filter{
+		Search: strings.TrimSpace(ctx.Query("search")),
+	}
+
+	for _, field := range fields {
+		if value := ctx.Query(field); value != "" {
+			filter.Items = append(filter.Items, models.FilterItem{Field: field, Op: models.FilterOpEq, Value: value})
+		}
+		for _, op := range models.FilterOps {
+			if value := ctx.Query(fmt.Sprintf("%s[%s]", field, op)); value != "" {
+				filter.Items = append(filter.Items, models.FilterItem{Field: field, Op: op, Value: value})
+			}
+		}
+	}
+
+	for _, field := range strings.Split(ctx.Query("sort"), ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		filter.Sort = append(filter.Sort, models.SortItem{
+			Field: strings.TrimPrefix(field, "-"),
+			Desc:  strings.HasPrefix(field, "-"),
+		})
+	}
+
+	return filter
+}
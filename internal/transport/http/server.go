@@ -5,11 +5,21 @@ import (
 	"backend/internal/services"
 	"backend/internal/transport/http/v1/answershandlers"
 	"backend/internal/transport/http/v1/authhandlers"
+	"backend/internal/transport/http/v1/calendarhandlers"
+	"backend/internal/transport/http/v1/checkershandlers"
+	"backend/internal/transport/http/v1/commentshandlers"
 	"backend/internal/transport/http/v1/fileshandlers"
 	"backend/internal/transport/http/v1/groupshandlers"
 	"backend/internal/transport/http/v1/markshandlers"
+	"backend/internal/transport/http/v1/orgunitshandlers"
+	"backend/internal/transport/http/v1/peerreviewshandlers"
+	"backend/internal/transport/http/v1/questionbankshandlers"
+	"backend/internal/transport/http/v1/quizzeshandlers"
+	"backend/internal/transport/http/v1/rubricshandlers"
 	"backend/internal/transport/http/v1/statisticshandlers"
 	"backend/internal/transport/http/v1/taskshandlers"
+	"backend/internal/transport/http/v1/teamshandlers"
+	"backend/internal/transport/http/v1/trashhandlers"
 	"backend/internal/transport/http/v1/usershandlers"
 	"context"
 	"errors"
@@ -21,31 +31,55 @@ import (
 )
 
 type Config struct {
-	Addr              string
-	TaskService       services.TaskService
-	AnswerService     services.AnswerService
-	FileService       services.FileService
-	GroupService      services.GroupService
-	UserService       services.UserService
-	AuthService       services.AuthService
-	MarkService       services.MarkService
-	StatisticsService services.StatisticsService
-	JWTConfig         models.JWTConfig
-	Log               *zerolog.Logger
+	Addr                    string
+	TaskService             services.TaskService
+	TaskSeriesService       services.TaskSeriesService
+	TaskPrerequisiteService services.TaskPrerequisiteService
+	AnswerService           services.AnswerService
+	FileService             services.FileService
+	GroupService            services.GroupService
+	UserService             services.UserService
+	AuthService             services.AuthService
+	MarkService             services.MarkService
+	StatisticsService       services.StatisticsService
+	OrgUnitService          services.OrgUnitService
+	RubricService           services.RubricService
+	QuestionBankService     services.QuestionBankService
+	QuizService             services.QuizService
+	CheckerService          services.CheckerService
+	TrashService            services.TrashService
+	TeamService             services.TeamService
+	CommentService          services.CommentService
+	CalendarService         services.CalendarService
+	PeerReviewService       services.PeerReviewService
+	JWTConfig               models.JWTConfig
+	Log                     *zerolog.Logger
 }
 
 type Server struct {
 	app  *fiber.App
 	addr string
 
-	taskService       services.TaskService
-	answerService     services.AnswerService
-	fileService       services.FileService
-	groupService      services.GroupService
-	userService       services.UserService
-	authService       services.AuthService
-	markService       services.MarkService
-	statisticsService services.StatisticsService
+	taskService             services.TaskService
+	taskSeriesService       services.TaskSeriesService
+	taskPrerequisiteService services.TaskPrerequisiteService
+	answerService           services.AnswerService
+	fileService             services.FileService
+	groupService            services.GroupService
+	userService             services.UserService
+	authService             services.AuthService
+	markService             services.MarkService
+	statisticsService       services.StatisticsService
+	orgUnitService          services.OrgUnitService
+	rubricService           services.RubricService
+	questionBankService     services.QuestionBankService
+	quizService             services.QuizService
+	checkerService          services.CheckerService
+	trashService            services.TrashService
+	teamService             services.TeamService
+	commentService          services.CommentService
+	calendarService         services.CalendarService
+	peerReviewService       services.PeerReviewService
 
 	jwtConfig models.JWTConfig
 
@@ -54,18 +88,30 @@ type Server struct {
 
 func NewServer(cfg *Config) *Server {
 	s := &Server{
-		app:               nil,
-		addr:              cfg.Addr,
-		taskService:       cfg.TaskService,
-		answerService:     cfg.AnswerService,
-		fileService:       cfg.FileService,
-		groupService:      cfg.GroupService,
-		userService:       cfg.UserService,
-		authService:       cfg.AuthService,
-		markService:       cfg.MarkService,
-		statisticsService: cfg.StatisticsService,
-		jwtConfig:         cfg.JWTConfig,
-		log:               cfg.Log,
+		app:                     nil,
+		addr:                    cfg.Addr,
+		taskService:             cfg.TaskService,
+		taskSeriesService:       cfg.TaskSeriesService,
+		taskPrerequisiteService: cfg.TaskPrerequisiteService,
+		answerService:           cfg.AnswerService,
+		fileService:             cfg.FileService,
+		groupService:            cfg.GroupService,
+		userService:             cfg.UserService,
+		authService:             cfg.AuthService,
+		markService:             cfg.MarkService,
+		statisticsService:       cfg.StatisticsService,
+		orgUnitService:          cfg.OrgUnitService,
+		rubricService:           cfg.RubricService,
+		questionBankService:     cfg.QuestionBankService,
+		quizService:             cfg.QuizService,
+		checkerService:          cfg.CheckerService,
+		trashService:            cfg.TrashService,
+		teamService:             cfg.TeamService,
+		commentService:          cfg.CommentService,
+		calendarService:         cfg.CalendarService,
+		peerReviewService:       cfg.PeerReviewService,
+		jwtConfig:               cfg.JWTConfig,
+		log:                     cfg.Log,
 	}
 
 	s.app = fiber.New(fiber.Config{
@@ -96,9 +142,11 @@ func (s *Server) init() {
 
 	authhandlers.New(v1Group, authhandlers.Config{UserService: s.userService, AuthService: s.authService}, s.log)
 	taskshandlers.New(v1Group, taskshandlers.Config{
-		TaskService: s.taskService,
-		JWTConfig:   s.jwtConfig,
-		FileService: s.fileService,
+		TaskService:             s.taskService,
+		TaskSeriesService:       s.taskSeriesService,
+		TaskPrerequisiteService: s.taskPrerequisiteService,
+		JWTConfig:               s.jwtConfig,
+		FileService:             s.fileService,
 	}, s.log)
 	answershandlers.New(v1Group, answershandlers.Config{
 		AnswerService: s.answerService,
@@ -106,15 +154,75 @@ func (s *Server) init() {
 		FileService:   s.fileService,
 		UserService:   s.userService,
 		MarkService:   s.markService,
+		TaskService:   s.taskService,
 	}, s.log)
 	fileshandlers.New(v1Group, fileshandlers.Config{FileService: s.fileService, JWTConfig: s.jwtConfig}, s.log)
-	markshandlers.New(v1Group, markshandlers.Config{MarkService: s.markService, JWTConfig: s.jwtConfig}, s.log)
-	usershandlers.New(v1Group, usershandlers.Config{UserService: s.userService, JWTConfig: s.jwtConfig}, s.log)
-	groupshandlers.New(v1Group, groupshandlers.Config{GroupService: s.groupService}, s.log)
+	markshandlers.New(v1Group, markshandlers.Config{
+		MarkService:   s.markService,
+		AnswerService: s.answerService,
+		JWTConfig:     s.jwtConfig,
+	}, s.log)
+	usershandlers.New(v1Group, usershandlers.Config{
+		UserService:  s.userService,
+		GroupService: s.groupService,
+		JWTConfig:    s.jwtConfig,
+	}, s.log)
+	groupshandlers.New(v1Group, groupshandlers.Config{GroupService: s.groupService, JWTConfig: s.jwtConfig}, s.log)
 	statisticshandlers.New(v1Group, statisticshandlers.Config{
 		StatisticsService: s.statisticsService,
 		JWTConfig:         s.jwtConfig,
 	}, s.log)
+	orgunitshandlers.New(v1Group, orgunitshandlers.Config{
+		OrgUnitService: s.orgUnitService,
+		JWTConfig:      s.jwtConfig,
+	}, s.log)
+	rubricshandlers.New(v1Group, rubricshandlers.Config{
+		RubricService: s.rubricService,
+		JWTConfig:     s.jwtConfig,
+	}, s.log)
+	questionbankshandlers.New(v1Group, questionbankshandlers.Config{
+		QuestionBankService: s.questionBankService,
+		JWTConfig:           s.jwtConfig,
+	}, s.log)
+	quizzeshandlers.New(v1Group, quizzeshandlers.Config{
+		QuizService:   s.quizService,
+		AnswerService: s.answerService,
+		TaskService:   s.taskService,
+		JWTConfig:     s.jwtConfig,
+	}, s.log)
+	checkershandlers.New(v1Group, checkershandlers.Config{
+		CheckerService: s.checkerService,
+		AnswerService:  s.answerService,
+		TaskService:    s.taskService,
+		JWTConfig:      s.jwtConfig,
+	}, s.log)
+	trashhandlers.New(v1Group, trashhandlers.Config{
+		TrashService: s.trashService,
+		JWTConfig:    s.jwtConfig,
+	}, s.log)
+	teamshandlers.New(v1Group, teamshandlers.Config{
+		TeamService: s.teamService,
+		TaskService: s.taskService,
+		JWTConfig:   s.jwtConfig,
+	}, s.log)
+	commentshandlers.New(v1Group, commentshandlers.Config{
+		CommentService: s.commentService,
+		TaskService:    s.taskService,
+		AnswerService:  s.answerService,
+		JWTConfig:      s.jwtConfig,
+	}, s.log)
+	calendarhandlers.New(v1Group, calendarhandlers.Config{
+		CalendarService: s.calendarService,
+		JWTConfig:       s.jwtConfig,
+	}, s.log)
+	peerreviewshandlers.New(v1Group, peerreviewshandlers.Config{
+		PeerReviewService: s.peerReviewService,
+		AnswerService:     s.answerService,
+		MarkService:       s.markService,
+		TaskService:       s.taskService,
+		FileService:       s.fileService,
+		JWTConfig:         s.jwtConfig,
+	}, s.log)
 }
 
 func (s *Server) errorHandler(ctx *fiber.Ctx, err error) error {
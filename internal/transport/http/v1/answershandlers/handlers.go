@@ -5,6 +5,7 @@ import (
 	"backend/internal/repo"
 	"backend/internal/services"
 	"backend/internal/transport/http/auth"
+	"backend/internal/transport/http/filter"
 	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
@@ -17,10 +18,52 @@ type handler struct {
 	fileService services.FileService
 	userService services.UserService
 	markService services.MarkService
+	taskService services.TaskService
 	log         *zerolog.Logger
 }
 
+// checkEditable makes sure the caller may change the answer: a student who
+// wrote it or is in the team that did, or staff managing its task.
+func (h *handler) checkEditable(ctx *fiber.Ctx, claims auth.Claims, id int64) error {
+	answer, err := h.service.GetById(ctx.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Answer not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetById: %v", err))
+	}
+
+	if claims.Role == models.UserRoleStudent {
+		belongs, err := h.service.BelongsTo(ctx.UserContext(), answer, claims.UserId)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.BelongsTo: %v", err))
+		}
+		if !belongs {
+			return fiber.NewError(fiber.StatusNotFound, "Answer not found")
+		}
+		return nil
+	}
+
+	task, err := h.taskService.GetById(ctx.UserContext(), answer.TaskId)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.GetById: %v", err))
+	}
+	canManage, err := h.taskService.CanManage(ctx.UserContext(), task, claims.UserId, claims.Role)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.CanManage: %v", err))
+	}
+	if !canManage {
+		return fiber.NewError(fiber.StatusForbidden, "Only the creator of the task can change its answers")
+	}
+	return nil
+}
+
 func (h *handler) getById(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
 	id, err := ctx.ParamsInt("id")
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
@@ -28,16 +71,27 @@ func (h *handler) getById(ctx *fiber.Ctx) error {
 
 	answer, err := h.service.GetById(ctx.UserContext(), int64(id))
 	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Answer not found")
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetById: %v", err))
 	}
 
+	canView, err := h.service.CanView(ctx.UserContext(), answer, claims.UserId, claims.Role)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.CanView: %v", err))
+	}
+	if !canView {
+		return fiber.NewError(fiber.StatusNotFound, "Answer not found")
+	}
+
 	files, err := h.fileService.GetByAnswerId(ctx.UserContext(), answer.Id)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetById: %v", err))
 	}
 
 	responseBytes, err := jsoniter.Marshal(getByIdResponse{
-		Answer:        answer,
+		Answer:        renderAnswer(answer),
 		AttachedFiles: files,
 	})
 	if err != nil {
@@ -74,7 +128,7 @@ func (h *handler) getList(ctx *fiber.Ctx) error {
 		}
 
 		responseBytes, err := jsoniter.Marshal(getByIdResponse{
-			Answer:        answer,
+			Answer:        renderAnswer(answer),
 			AttachedFiles: files,
 		})
 		if err != nil {
@@ -98,16 +152,23 @@ func (h *handler) getList(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, `Query parameter <offset> missed`)
 	}
 
-	answers, err := h.service.GetList(ctx.UserContext(), services.AnswerServiceGetListOpts{
-		TaskId: int64(taskId),
-		Limit:  int64(limit),
-		Offset: int64(offset),
-	})
+	opts := services.AnswerServiceGetListOpts{
+		TaskId:      int64(taskId),
+		ScopeUserId: claims.ScopeUserId(),
+		Filter:      filter.FromQuery(ctx, filterFields),
+		Limit:       int64(limit),
+		Offset:      int64(offset),
+	}
+
+	answers, err := h.service.GetList(ctx.UserContext(), opts)
 	if err != nil {
+		if errors.Is(err, repo.ErrInvalidFilter) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetList: %v", err))
 	}
 
-	count, err := h.service.GetCount(ctx.UserContext(), int64(taskId))
+	count, err := h.service.GetCount(ctx.UserContext(), opts)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetCount: %v", err))
 	}
@@ -123,7 +184,7 @@ func (h *handler) getList(ctx *fiber.Ctx) error {
 		if err != nil {
 			if errors.Is(err, repo.ErrNotFound) {
 				data = append(data, extendedAnswer{
-					Answer:   answer,
+					Answer:   renderAnswer(answer),
 					UserName: user.FullName(),
 					Mark:     nil,
 				})
@@ -133,9 +194,10 @@ func (h *handler) getList(ctx *fiber.Ctx) error {
 		}
 
 		data = append(data, extendedAnswer{
-			Answer:   answer,
+			Answer:   renderAnswer(answer),
 			UserName: user.FullName(),
 			Mark:     &mark.Mark,
+			Criteria: mark.Criteria,
 		})
 	}
 
@@ -172,6 +234,14 @@ func (h *handler) create(ctx *fiber.Ctx) error {
 		Files:   req.Files,
 	})
 	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTaskNotStarted), errors.Is(err, services.ErrDeadlinePassed),
+			errors.Is(err, services.ErrTaskLocked), errors.Is(err, services.ErrNotInTeam),
+			errors.Is(err, services.ErrTeamTooSmall):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		case errors.Is(err, repo.ErrNotFound), errors.Is(err, services.ErrNotTaskAssignee):
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Create: %v", err))
 	}
 
@@ -183,11 +253,20 @@ func (h *handler) create(ctx *fiber.Ctx) error {
 }
 
 func (h *handler) update(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
 	id, err := ctx.ParamsInt("id")
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
 	}
 
+	if err = h.checkEditable(ctx, claims, int64(id)); err != nil {
+		return err
+	}
+
 	var req updateRequest
 	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
@@ -199,7 +278,13 @@ func (h *handler) update(ctx *fiber.Ctx) error {
 		Files:   req.Files,
 	})
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Create: %v", err))
+		switch {
+		case errors.Is(err, services.ErrTaskNotStarted), errors.Is(err, services.ErrDeadlinePassed):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Answer not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Update: %v", err))
 	}
 
 	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
@@ -210,11 +295,20 @@ func (h *handler) update(ctx *fiber.Ctx) error {
 }
 
 func (h *handler) delete(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
 	id, err := ctx.ParamsInt("id")
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
 	}
 
+	if err = h.checkEditable(ctx, claims, int64(id)); err != nil {
+		return err
+	}
+
 	if err = h.service.Delete(ctx.UserContext(), int64(id)); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Delete: %v", err))
 	}
@@ -2,8 +2,11 @@ package answershandlers
 
 import (
 	"backend/internal/models"
+	"backend/pkg/markdown"
 )
 
+var filterFields = []string{"userId", "groupId", "isLate", "createdAt"}
+
 type getByIdResponse struct {
 	Answer        models.Answer `json:"answer"`
 	AttachedFiles []models.File `json:"attachedFiles"`
@@ -15,9 +18,10 @@ type getListResponse struct {
 }
 
 type extendedAnswer struct {
-	Answer   models.Answer `json:"answer"`
-	UserName string        `json:"userName"`
-	Mark     *int64        `json:"mark"`
+	Answer   models.Answer          `json:"answer"`
+	UserName string                 `json:"userName"`
+	Mark     *int64                 `json:"mark"`
+	Criteria []models.MarkCriterion `json:"criteria,omitempty"`
 }
 
 type createRequest struct {
@@ -30,3 +34,9 @@ type updateRequest struct {
 	Comment string  `json:"comment"`
 	Files   []int64 `json:"files"`
 }
+
+// renderAnswer fills in the html of the answer comment for the response.
+func renderAnswer(answer models.Answer) models.Answer {
+	answer.CommentHtml = markdown.RenderPtr(answer.Comment)
+	return answer
+}
@@ -3,9 +3,7 @@ package answershandlers
 import (
 	"backend/internal/models"
 	"backend/internal/services"
-	jwtware "github.com/gofiber/contrib/jwt"
-	"github.com/golang-jwt/jwt/v5"
-	"strings"
+	"backend/internal/transport/http/auth"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
@@ -16,6 +14,7 @@ type Config struct {
 	FileService   services.FileService
 	UserService   services.UserService
 	MarkService   services.MarkService
+	TaskService   services.TaskService
 	JWTConfig     models.JWTConfig
 }
 
@@ -25,33 +24,11 @@ func New(router fiber.Router, cfg Config, log *zerolog.Logger) {
 		fileService: cfg.FileService,
 		userService: cfg.UserService,
 		markService: cfg.MarkService,
+		taskService: cfg.TaskService,
 		log:         log,
 	}
 
-	answerGroup := router.Group("/answer", jwtware.New(jwtware.Config{
-		SigningKey: jwtware.SigningKey{
-			Key: []byte(cfg.JWTConfig.JWTAccessSecretKey),
-		},
-		SuccessHandler: func(ctx *fiber.Ctx) error {
-			authorizationHeaderValue := ctx.Get(fiber.HeaderAuthorization)
-			token := strings.Split(authorizationHeaderValue, "Bearer ")[1]
-			if len(token) == 0 {
-				return fiber.NewError(fiber.StatusUnauthorized, "missed jwt token")
-			}
-
-			claims := jwt.MapClaims{}
-			_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-				return []byte(cfg.JWTConfig.JWTAccessSecretKey), nil
-			})
-			if err != nil {
-				log.Error().Err(err).Send()
-				return fiber.NewError(fiber.StatusUnauthorized, "err token parse")
-			}
-
-			ctx.Locals("claims", claims)
-			return ctx.Next()
-		},
-	}))
+	answerGroup := router.Group("/answer", auth.New(cfg.JWTConfig, log))
 	answerGroup.Get("/:id", h.getById)
 	answerGroup.Get("/", h.getList)
 	answerGroup.Post("/", h.create)
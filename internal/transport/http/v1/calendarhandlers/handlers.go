@@ -0,0 +1,86 @@
+package calendarhandlers
+
+import (
+	"backend/internal/repo"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog"
+)
+
+type handler struct {
+	service services.CalendarService
+	log     *zerolog.Logger
+}
+
+func (h *handler) getFeed(ctx *fiber.Ctx) error {
+	token := ctx.Params("token")
+	if token == "" {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <token> empty`)
+	}
+
+	feed, err := h.service.GetFeed(ctx.UserContext(), token)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Calendar not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetFeed: %v", err))
+	}
+
+	ctx.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	if err = ctx.Status(fiber.StatusOK).Send(feed); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) getToken(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	token, err := h.service.GetToken(ctx.UserContext(), claims.UserId)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetToken: %v", err))
+	}
+
+	return sendToken(ctx, fiber.StatusOK, token)
+}
+
+// resetToken replaces the caller's token, revoking the feed URL shared so far.
+func (h *handler) resetToken(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	token, err := h.service.ResetToken(ctx.UserContext(), claims.UserId)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.ResetToken: %v", err))
+	}
+
+	return sendToken(ctx, fiber.StatusCreated, token)
+}
+
+func sendToken(ctx *fiber.Ctx, status int, token string) error {
+	responseBytes, err := jsoniter.Marshal(tokenResponse{
+		Token: token,
+		Path:  "/api/v1/calendar/feed/" + token + ".ics",
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(status).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
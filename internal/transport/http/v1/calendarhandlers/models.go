@@ -0,0 +1,8 @@
+package calendarhandlers
+
+// tokenResponse carries the feed token and the path of the feed relative to
+// the API root.
+type tokenResponse struct {
+	Token string `json:"token"`
+	Path  string `json:"path"`
+}
@@ -0,0 +1,29 @@
+package calendarhandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type Config struct {
+	CalendarService services.CalendarService
+	JWTConfig       models.JWTConfig
+}
+
+func New(router fiber.Router, cfg Config, log *zerolog.Logger) {
+	h := handler{
+		service: cfg.CalendarService,
+		log:     log,
+	}
+
+	calendarGroup := router.Group("/calendar")
+	// The feed is fetched by calendar apps, which cannot log in: the token in
+	// the path is the credential.
+	calendarGroup.Get("/feed/:token.ics", h.getFeed)
+	calendarGroup.Get("/token", auth.New(cfg.JWTConfig, log), h.getToken)
+	calendarGroup.Post("/token", auth.New(cfg.JWTConfig, log), h.resetToken)
+}
@@ -0,0 +1,243 @@
+package checkershandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog"
+)
+
+type handler struct {
+	service       services.CheckerService
+	answerService services.AnswerService
+	taskService   services.TaskService
+	log           *zerolog.Logger
+}
+
+// checkManaged makes sure the caller may change the task, see
+// TaskService.CanManage.
+func (h *handler) checkManaged(ctx *fiber.Ctx, claims auth.Claims, taskId int64) error {
+	task, err := h.taskService.GetById(ctx.UserContext(), taskId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.GetById: %v", err))
+	}
+
+	canManage, err := h.taskService.CanManage(ctx.UserContext(), task, claims.UserId, claims.Role)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.CanManage: %v", err))
+	}
+	if !canManage {
+		return fiber.NewError(fiber.StatusForbidden, "Only the creator of the task can change its checker")
+	}
+	return nil
+}
+
+func (h *handler) getByTaskId(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	if err = h.checkManaged(ctx, claims, int64(taskId)); err != nil {
+		return err
+	}
+
+	taskChecker, err := h.service.GetByTaskId(ctx.UserContext(), int64(taskId))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Checker not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetByTaskId: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(taskChecker)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) set(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	if err = h.checkManaged(ctx, claims, int64(taskId)); err != nil {
+		return err
+	}
+
+	var req setRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	taskChecker, err := h.service.Set(ctx.UserContext(), services.CheckerServiceSetOpts{
+		TaskId:      int64(taskId),
+		EditorId:    claims.UserId,
+		Kind:        req.Kind,
+		Command:     req.Command,
+		TestsFileId: req.TestsFileId,
+		TimeoutMs:   req.TimeoutMs,
+		MemoryMb:    req.MemoryMb,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidChecker):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Set: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(taskChecker)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) delete(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	if err = h.checkManaged(ctx, claims, int64(taskId)); err != nil {
+		return err
+	}
+
+	if err = h.service.Delete(ctx.UserContext(), int64(taskId)); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Checker not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Delete: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) getCheck(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	answer, err := h.getAnswer(ctx)
+	if err != nil {
+		return err
+	}
+	canView, err := h.answerService.CanView(ctx.UserContext(), answer, claims.UserId, claims.Role)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.answerService.CanView: %v", err))
+	}
+	if !canView {
+		return fiber.NewError(fiber.StatusNotFound, "Answer not found")
+	}
+
+	check, err := h.service.GetCheck(ctx.UserContext(), answer.Id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Check not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetCheck: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(check)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) recheck(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	answer, err := h.getAnswer(ctx)
+	if err != nil {
+		return err
+	}
+	if err = h.checkManaged(ctx, claims, answer.TaskId); err != nil {
+		return err
+	}
+
+	if _, err = h.service.GetByTaskId(ctx.UserContext(), answer.TaskId); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Checker not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetByTaskId: %v", err))
+	}
+
+	if err = h.service.Enqueue(ctx.UserContext(), answer.Id, answer.TaskId); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Enqueue: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) getAnswer(ctx *fiber.Ctx) (models.Answer, error) {
+	answerId, err := ctx.ParamsInt("answerId")
+	if err != nil {
+		return models.Answer{}, fiber.NewError(fiber.StatusBadRequest, `Path parameter <answerId> empty or not a number`)
+	}
+
+	answer, err := h.answerService.GetById(ctx.UserContext(), int64(answerId))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return models.Answer{}, fiber.NewError(fiber.StatusNotFound, "Answer not found")
+		}
+		return models.Answer{}, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.answerService.GetById: %v", err))
+	}
+	return answer, nil
+}
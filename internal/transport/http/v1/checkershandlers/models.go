@@ -0,0 +1,9 @@
+package checkershandlers
+
+type setRequest struct {
+	Kind        string `json:"kind"`
+	Command     string `json:"command"`
+	TestsFileId int64  `json:"testsFileId"`
+	TimeoutMs   int64  `json:"timeoutMs"`
+	MemoryMb    int64  `json:"memoryMb"`
+}
@@ -0,0 +1,35 @@
+package checkershandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type Config struct {
+	CheckerService services.CheckerService
+	AnswerService  services.AnswerService
+	TaskService    services.TaskService
+	JWTConfig      models.JWTConfig
+}
+
+func New(router fiber.Router, cfg Config, log *zerolog.Logger) {
+	h := handler{
+		service:       cfg.CheckerService,
+		answerService: cfg.AnswerService,
+		taskService:   cfg.TaskService,
+		log:           log,
+	}
+
+	staffOnly := auth.RequireRoles(models.UserRoleAdministrator, models.UserRoleTeacher)
+
+	checkerGroup := router.Group("/checker", auth.New(cfg.JWTConfig, log))
+	checkerGroup.Get("/answer/:answerId", h.getCheck)
+	checkerGroup.Post("/answer/:answerId", staffOnly, h.recheck)
+	checkerGroup.Get("/:taskId", staffOnly, h.getByTaskId)
+	checkerGroup.Put("/:taskId", staffOnly, h.set)
+	checkerGroup.Delete("/:taskId", staffOnly, h.delete)
+}
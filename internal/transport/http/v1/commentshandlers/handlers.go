@@ -0,0 +1,371 @@
+package commentshandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog"
+)
+
+type handler struct {
+	service       services.CommentService
+	taskService   services.TaskService
+	answerService services.AnswerService
+	log           *zerolog.Logger
+}
+
+// checkThread makes sure the caller can see the thread and returns the task
+// it belongs to. Students see the threads of the published tasks assigned to
+// them and the threads of their own or their team's answers. Staff see the
+// threads of the tasks and answers they can view.
+func (h *handler) checkThread(ctx *fiber.Ctx, claims auth.Claims, taskId int64, answerId *int64) (int64, error) {
+	if answerId != nil {
+		answer, err := h.answerService.GetById(ctx.UserContext(), *answerId)
+		if err != nil {
+			if errors.Is(err, repo.ErrNotFound) {
+				return 0, fiber.NewError(fiber.StatusNotFound, "Answer not found")
+			}
+			return 0, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.answerService.GetById: %v", err))
+		}
+
+		visible, err := h.answerService.CanView(ctx.UserContext(), answer, claims.UserId, claims.Role)
+		if err != nil {
+			return 0, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.answerService.CanView: %v", err))
+		}
+		if !visible {
+			return 0, fiber.NewError(fiber.StatusNotFound, "Answer not found")
+		}
+		return answer.TaskId, nil
+	}
+
+	if claims.Role != models.UserRoleStudent {
+		visible, err := h.taskService.CanView(ctx.UserContext(), taskId, claims.UserId)
+		if err != nil {
+			if errors.Is(err, repo.ErrNotFound) {
+				return 0, fiber.NewError(fiber.StatusNotFound, "Task not found")
+			}
+			return 0, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.CanView: %v", err))
+		}
+		if !visible {
+			return 0, fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return taskId, nil
+	}
+
+	task, err := h.taskService.GetByIdForUser(ctx.UserContext(), taskId, claims.UserId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return 0, fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return 0, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.GetByIdForUser: %v", err))
+	}
+	if task.State != models.TaskStatePublished || time.Now().Before(task.EffectiveFrom) {
+		return 0, fiber.NewError(fiber.StatusNotFound, "Task not found")
+	}
+
+	assigned, err := h.taskService.IsAssigned(ctx.UserContext(), task.Id, claims.UserId)
+	if err != nil {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.IsAssigned: %v", err))
+	}
+	if !assigned {
+		return 0, fiber.NewError(fiber.StatusNotFound, "Task not found")
+	}
+	return task.Id, nil
+}
+
+// queryAnswerId reads the optional <answerId> query parameter.
+func queryAnswerId(ctx *fiber.Ctx) *int64 {
+	answerId := ctx.QueryInt("answerId")
+	if answerId == 0 {
+		return nil
+	}
+	id := int64(answerId)
+	return &id
+}
+
+func (h *handler) getList(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	answerId := queryAnswerId(ctx)
+	taskId := ctx.QueryInt("taskId")
+	if taskId == 0 && answerId == nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Query parameter <taskId> or <answerId> missed`)
+	}
+
+	limit := ctx.QueryInt("limit")
+	if limit == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, `Query parameter <limit> missed or equal to zero`)
+	}
+
+	offset := ctx.QueryInt("offset", -1)
+	if offset == -1 {
+		return fiber.NewError(fiber.StatusBadRequest, `Query parameter <offset> missed`)
+	}
+
+	threadTaskId, err := h.checkThread(ctx, claims, int64(taskId), answerId)
+	if err != nil {
+		return err
+	}
+
+	opts := services.CommentServiceGetListOpts{
+		TaskId:   threadTaskId,
+		AnswerId: answerId,
+		Limit:    int64(limit),
+		Offset:   int64(offset),
+	}
+
+	comments, err := h.service.GetList(ctx.UserContext(), opts)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetList: %v", err))
+	}
+
+	count, err := h.service.GetCount(ctx.UserContext(), opts)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetCount: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(getListResponse{
+		Comments: renderComments(comments),
+		Count:    count,
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+// getUnread counts the unread comments in every thread of the task the caller
+// can see. Staff get the threads of all answers.
+func (h *handler) getUnread(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId := ctx.QueryInt("taskId")
+	if taskId == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, `Query parameter <taskId> missed`)
+	}
+
+	if _, err = h.checkThread(ctx, claims, int64(taskId), nil); err != nil {
+		return err
+	}
+
+	unread, err := h.service.GetUnread(ctx.UserContext(), services.CommentServiceGetUnreadOpts{
+		UserId:     claims.UserId,
+		TaskId:     int64(taskId),
+		AllAnswers: claims.Role != models.UserRoleStudent,
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetUnread: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(getUnreadResponse{Data: unread})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) markRead(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	var req threadRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	taskId, err := h.checkThread(ctx, claims, req.TaskId, req.AnswerId)
+	if err != nil {
+		return err
+	}
+
+	if err = h.service.MarkRead(ctx.UserContext(), services.CommentServiceThreadOpts{
+		UserId:   claims.UserId,
+		TaskId:   taskId,
+		AnswerId: req.AnswerId,
+	}); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.MarkRead: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) create(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	var req createRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	taskId, err := h.checkThread(ctx, claims, req.TaskId, req.AnswerId)
+	if err != nil {
+		return err
+	}
+
+	comment, err := h.service.Create(ctx.UserContext(), services.CommentServiceCreateOpts{
+		TaskId:   taskId,
+		AnswerId: req.AnswerId,
+		AuthorId: claims.UserId,
+		Text:     req.Text,
+		FileIds:  req.Files,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidComment) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Create: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(renderComment(comment))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusCreated).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+// update edits a comment. Only its author can do so.
+func (h *handler) update(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	var req updateRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	comment, err := h.service.GetById(ctx.UserContext(), int64(id))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Comment not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetById: %v", err))
+	}
+	if comment.AuthorId != claims.UserId {
+		return fiber.NewError(fiber.StatusForbidden, "Only the author can edit a comment")
+	}
+
+	comment, err = h.service.Update(ctx.UserContext(), services.CommentServiceUpdateOpts{
+		Id:      comment.Id,
+		Text:    req.Text,
+		FileIds: req.Files,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidComment):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Comment not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Update: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(renderComment(comment))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+// delete removes a comment. Authors can remove their own comments, staff also
+// the comments on the tasks they manage.
+func (h *handler) delete(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	comment, err := h.service.GetById(ctx.UserContext(), int64(id))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Comment not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetById: %v", err))
+	}
+	if comment.AuthorId != claims.UserId {
+		if claims.Role == models.UserRoleStudent {
+			return fiber.NewError(fiber.StatusForbidden, "Only the author can delete a comment")
+		}
+
+		task, err := h.taskService.GetById(ctx.UserContext(), comment.TaskId)
+		if err != nil {
+			if errors.Is(err, repo.ErrNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "Task not found")
+			}
+			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.GetById: %v", err))
+		}
+		canManage, err := h.taskService.CanManage(ctx.UserContext(), task, claims.UserId, claims.Role)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.CanManage: %v", err))
+		}
+		if !canManage {
+			return fiber.NewError(fiber.StatusForbidden, "Only the author or the creator of the task can delete a comment")
+		}
+	}
+
+	if err = h.service.Delete(ctx.UserContext(), comment.Id); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Comment not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Delete: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
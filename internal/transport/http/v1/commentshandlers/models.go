@@ -0,0 +1,48 @@
+package commentshandlers
+
+import (
+	"backend/internal/models"
+	"backend/pkg/markdown"
+
+	"github.com/samber/lo"
+)
+
+type getListResponse struct {
+	Comments []models.Comment `json:"data"`
+	Count    int64            `json:"count"`
+}
+
+type getUnreadResponse struct {
+	Data []models.CommentUnread `json:"data"`
+}
+
+// threadRequest names a thread: the task thread when AnswerId is nil and the
+// private thread of the answer otherwise.
+type threadRequest struct {
+	TaskId   int64  `json:"taskId"`
+	AnswerId *int64 `json:"answerId"`
+}
+
+type createRequest struct {
+	TaskId   int64   `json:"taskId"`
+	AnswerId *int64  `json:"answerId"`
+	Text     string  `json:"text"`
+	Files    []int64 `json:"files"`
+}
+
+type updateRequest struct {
+	Text  string  `json:"text"`
+	Files []int64 `json:"files"`
+}
+
+// renderComment fills in the html of the comment text for the response.
+func renderComment(comment models.Comment) models.Comment {
+	comment.TextHtml = markdown.Render(comment.Text)
+	return comment
+}
+
+func renderComments(comments []models.Comment) []models.Comment {
+	return lo.Map(comments, func(item models.Comment, _ int) models.Comment {
+		return renderComment(item)
+	})
+}
@@ -0,0 +1,34 @@
+package commentshandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type Config struct {
+	CommentService services.CommentService
+	TaskService    services.TaskService
+	AnswerService  services.AnswerService
+	JWTConfig      models.JWTConfig
+}
+
+func New(router fiber.Router, cfg Config, log *zerolog.Logger) {
+	h := handler{
+		service:       cfg.CommentService,
+		taskService:   cfg.TaskService,
+		answerService: cfg.AnswerService,
+		log:           log,
+	}
+
+	commentGroup := router.Group("/comment", auth.New(cfg.JWTConfig, log))
+	commentGroup.Get("/", h.getList)
+	commentGroup.Get("/unread", h.getUnread)
+	commentGroup.Post("/read", h.markRead)
+	commentGroup.Post("/", h.create)
+	commentGroup.Put("/:id", h.update)
+	commentGroup.Delete("/:id", h.delete)
+}
@@ -2,6 +2,7 @@ package fileshandlers
 
 import (
 	"backend/internal/services"
+	"backend/internal/transport/http/auth"
 	"fmt"
 	"path/filepath"
 
@@ -40,6 +41,11 @@ func (h *handler) getById(ctx *fiber.Ctx) error {
 }
 
 func (h *handler) create(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
 	form, err := ctx.MultipartForm()
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("ctx.MultipartForm: %v", err))
@@ -61,11 +67,12 @@ func (h *handler) create(ctx *fiber.Ctx) error {
 		}
 
 		createdFile, err := h.service.Create(ctx.UserContext(), services.FileServiceCreateOpts{
-			Name:     originalName,
-			Filename: saveName,
-			Filepath: savePath,
-			TaskId:   req.TaskId,
-			AnswerId: req.AnswerId,
+			Name:      originalName,
+			Filename:  saveName,
+			Filepath:  savePath,
+			TaskId:    req.TaskId,
+			AnswerId:  req.AnswerId,
+			CreatedBy: &claims.UserId,
 		})
 		if err != nil {
 			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Create: %v", err))
@@ -1,7 +1,11 @@
 package groupshandlers
 
 import (
+	"backend/internal/repo"
 	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+	"backend/internal/transport/http/filter"
+	"errors"
 	"fmt"
 
 	"github.com/gofiber/fiber/v2"
@@ -38,6 +42,11 @@ func (h *handler) getById(ctx *fiber.Ctx) error {
 }
 
 func (h *handler) getList(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
 	limit := ctx.QueryInt("limit")
 	if limit == 0 {
 		return fiber.NewError(fiber.StatusBadRequest, `Query parameter <limit> missed or equal to zero`)
@@ -48,15 +57,22 @@ func (h *handler) getList(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, `Query parameter <offset> missed`)
 	}
 
-	groups, err := h.service.GetList(ctx.UserContext(), services.GroupServiceGetListOpts{
-		Limit:  int64(limit),
-		Offset: int64(offset),
-	})
+	opts := services.GroupServiceGetListOpts{
+		ScopeUserId: claims.ScopeUserId(),
+		Filter:      filter.FromQuery(ctx, filterFields),
+		Limit:       int64(limit),
+		Offset:      int64(offset),
+	}
+
+	groups, err := h.service.GetList(ctx.UserContext(), opts)
 	if err != nil {
+		if errors.Is(err, repo.ErrInvalidFilter) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetList: %v", err))
 	}
 
-	count, err := h.service.GetCount(ctx.UserContext())
+	count, err := h.service.GetCount(ctx.UserContext(), opts)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetCount: %v", err))
 	}
@@ -77,15 +93,28 @@ func (h *handler) getList(ctx *fiber.Ctx) error {
 }
 
 func (h *handler) create(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
 	var req createRequest
-	if err := jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
 	}
 
-	_, err := h.service.Create(ctx.UserContext(), services.GroupServiceCreateOpts{
-		Name: req.Name,
+	_, err = h.service.Create(ctx.UserContext(), services.GroupServiceCreateOpts{
+		ScopeUserId: claims.UserId,
+		Name:        req.Name,
+		OrgUnitId:   req.OrgUnitId,
 	})
 	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidOrgUnit):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrOrgUnitNotManaged):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Create: %v", err))
 	}
 
@@ -97,6 +126,11 @@ func (h *handler) create(ctx *fiber.Ctx) error {
 }
 
 func (h *handler) update(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
 	id, err := ctx.ParamsInt("id")
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
@@ -108,11 +142,19 @@ func (h *handler) update(ctx *fiber.Ctx) error {
 	}
 
 	_, err = h.service.Update(ctx.UserContext(), services.GroupServiceUpdateOpts{
-		Id:   int64(id),
-		Name: req.Name,
+		Id:          int64(id),
+		ScopeUserId: claims.UserId,
+		Name:        req.Name,
+		OrgUnitId:   req.OrgUnitId,
 	})
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Create: %v", err))
+		switch {
+		case errors.Is(err, services.ErrInvalidOrgUnit):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrOrgUnitNotManaged), errors.Is(err, services.ErrGroupNotAssigned):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Update: %v", err))
 	}
 
 	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
@@ -123,12 +165,23 @@ func (h *handler) update(ctx *fiber.Ctx) error {
 }
 
 func (h *handler) delete(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
 	id, err := ctx.ParamsInt("id")
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
 	}
 
-	if err = h.service.Delete(ctx.UserContext(), int64(id)); err != nil {
+	if err = h.service.Delete(ctx.UserContext(), services.GroupServiceDeleteOpts{
+		Id:          int64(id),
+		ScopeUserId: claims.UserId,
+	}); err != nil {
+		if errors.Is(err, services.ErrGroupNotAssigned) {
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Delete: %v", err))
 	}
 
@@ -138,3 +191,74 @@ func (h *handler) delete(ctx *fiber.Ctx) error {
 
 	return nil
 }
+
+func (h *handler) assign(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	var req assignRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	if err = h.service.Assign(ctx.UserContext(), services.GroupServiceAssignmentOpts{
+		ScopeUserId: claims.UserId,
+		UserId:      req.UserId,
+		GroupId:     int64(id),
+	}); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidAssignee):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrGroupNotAssigned):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Assign: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusCreated).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) unassign(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	userId, err := ctx.ParamsInt("userId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <userId> empty or not a number`)
+	}
+
+	if err = h.service.Unassign(ctx.UserContext(), services.GroupServiceAssignmentOpts{
+		ScopeUserId: claims.UserId,
+		UserId:      int64(userId),
+		GroupId:     int64(id),
+	}); err != nil {
+		if errors.Is(err, services.ErrGroupNotAssigned) {
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Unassign: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
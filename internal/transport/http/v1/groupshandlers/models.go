@@ -4,15 +4,23 @@ import (
 	"backend/internal/models"
 )
 
+var filterFields = []string{"name", "orgUnitId"}
+
 type getListResponse struct {
 	Groups []models.Group `json:"data"`
 	Count  int64          `json:"count"`
 }
 
 type createRequest struct {
-	Name string `json:"name"`
+	Name      string `json:"name"`
+	OrgUnitId *int64 `json:"orgUnitId"`
 }
 
 type updateRequest struct {
-	Name string `json:"name"`
+	Name      string `json:"name"`
+	OrgUnitId *int64 `json:"orgUnitId"`
+}
+
+type assignRequest struct {
+	UserId int64 `json:"userId"`
 }
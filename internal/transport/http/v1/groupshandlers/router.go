@@ -1,7 +1,9 @@
 package groupshandlers
 
 import (
+	"backend/internal/models"
 	"backend/internal/services"
+	"backend/internal/transport/http/auth"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
@@ -9,6 +11,7 @@ import (
 
 type Config struct {
 	GroupService services.GroupService
+	JWTConfig    models.JWTConfig
 }
 
 func New(router fiber.Router, cfg Config, log *zerolog.Logger) {
@@ -17,10 +20,14 @@ func New(router fiber.Router, cfg Config, log *zerolog.Logger) {
 		log:     log,
 	}
 
-	answerGroup := router.Group("/group")
+	adminOnly := auth.RequireRoles(models.UserRoleAdministrator)
+
+	answerGroup := router.Group("/group", auth.New(cfg.JWTConfig, log))
 	answerGroup.Get("/:id", h.getById)
 	answerGroup.Get("/", h.getList)
-	answerGroup.Post("/", h.create)
-	answerGroup.Put("/:id", h.update)
-	answerGroup.Delete("/:id", h.delete)
+	answerGroup.Post("/", adminOnly, h.create)
+	answerGroup.Put("/:id", adminOnly, h.update)
+	answerGroup.Delete("/:id", adminOnly, h.delete)
+	answerGroup.Post("/:id/assignee", adminOnly, h.assign)
+	answerGroup.Delete("/:id/assignee/:userId", adminOnly, h.unassign)
 }
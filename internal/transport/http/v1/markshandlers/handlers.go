@@ -1,8 +1,12 @@
 package markshandlers
 
 import (
+	"backend/internal/models"
+	"backend/internal/repo"
 	"backend/internal/services"
 	"backend/internal/transport/http/auth"
+	"backend/internal/transport/http/filter"
+	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	jsoniter "github.com/json-iterator/go"
@@ -10,11 +14,17 @@ import (
 )
 
 type handler struct {
-	service services.MarkService
-	log     *zerolog.Logger
+	service       services.MarkService
+	answerService services.AnswerService
+	log           *zerolog.Logger
 }
 
 func (h *handler) getById(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
 	id, err := ctx.ParamsInt("id")
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
@@ -22,9 +32,29 @@ func (h *handler) getById(ctx *fiber.Ctx) error {
 
 	mark, err := h.service.GetById(ctx.UserContext(), int64(id))
 	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Mark not found")
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetById: %v", err))
 	}
 
+	// Marks of deleted answers are gone together with them.
+	answer, err := h.answerService.GetById(ctx.UserContext(), mark.AnswerId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Mark not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.answerService.GetById: %v", err))
+	}
+
+	canView, err := h.answerService.CanView(ctx.UserContext(), answer, claims.UserId, claims.Role)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.answerService.CanView: %v", err))
+	}
+	if !canView {
+		return fiber.NewError(fiber.StatusNotFound, "Mark not found")
+	}
+
 	responseBytes, err := jsoniter.Marshal(mark)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
@@ -58,16 +88,30 @@ func (h *handler) getList(ctx *fiber.Ctx) error {
 		return fmt.Errorf("auth.GetClaimsFromCtx: %v", err)
 	}
 
-	marks, err := h.service.GetListByUserId(ctx.UserContext(), services.MarkServiceGetListByUserIdOpts{
-		UserId: claims.UserId,
-		Limit:  int64(limit),
-		Offset: int64(offset),
-	})
+	opts := services.MarkServiceGetListByUserIdOpts{
+		UserId:      claims.UserId,
+		ScopeUserId: claims.ScopeUserId(),
+		Filter:      filter.FromQuery(ctx, filterFields),
+		Limit:       int64(limit),
+		Offset:      int64(offset),
+	}
+	if claims.Role != models.UserRoleStudent {
+		userId := ctx.QueryInt("userId", -1)
+		if userId == -1 {
+			return fiber.NewError(fiber.StatusBadRequest, `Query parameter <userId> missed`)
+		}
+		opts.UserId = int64(userId)
+	}
+
+	marks, err := h.service.GetListByUserId(ctx.UserContext(), opts)
 	if err != nil {
+		if errors.Is(err, repo.ErrInvalidFilter) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetListByUserId: %v", err))
 	}
 
-	count, err := h.service.GetCountByUserId(ctx.UserContext(), claims.UserId)
+	count, err := h.service.GetCountByUserId(ctx.UserContext(), opts)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetCountByUserId: %v", err))
 	}
@@ -97,8 +141,15 @@ func (h *handler) create(ctx *fiber.Ctx) error {
 		AnswerId: req.AnswerId,
 		Mark:     req.Mark,
 		Comment:  req.Comment,
+		Criteria: req.Criteria,
 	})
 	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMarkOutOfRange), errors.Is(err, services.ErrInvalidCriteria):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Answer not found")
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Create: %v", err))
 	}
 
@@ -126,11 +177,18 @@ func (h *handler) update(ctx *fiber.Ctx) error {
 	}
 
 	mark, err := h.service.Update(ctx.UserContext(), services.MarkServiceUpdateOpts{
-		Id:      int64(id),
-		Mark:    req.Mark,
-		Comment: req.Comment,
+		Id:       int64(id),
+		Mark:     req.Mark,
+		Comment:  req.Comment,
+		Criteria: req.Criteria,
 	})
 	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMarkOutOfRange), errors.Is(err, services.ErrInvalidCriteria):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Mark not found")
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Update: %v", err))
 	}
 
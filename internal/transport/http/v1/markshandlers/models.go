@@ -4,18 +4,22 @@ import (
 	"backend/internal/models"
 )
 
+var filterFields = []string{"mark", "rawMark", "createdAt"}
+
 type getListResponse struct {
 	Marks []models.Mark `json:"data"`
 	Count int64         `json:"count"`
 }
 
 type createMarkRequest struct {
-	AnswerId int64   `json:"answerId"`
-	Mark     int64   `json:"mark"`
-	Comment  *string `json:"comment"`
+	AnswerId int64                  `json:"answerId"`
+	Mark     int64                  `json:"mark"`
+	Comment  *string                `json:"comment"`
+	Criteria []models.MarkCriterion `json:"criteria"`
 }
 
 type updateMarkRequest struct {
-	Mark    int64   `json:"mark"`
-	Comment *string `json:"comment"`
+	Mark     int64                  `json:"mark"`
+	Comment  *string                `json:"comment"`
+	Criteria []models.MarkCriterion `json:"criteria"`
 }
@@ -0,0 +1,252 @@
+package orgunitshandlers
+
+import (
+	"backend/internal/repo"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog"
+)
+
+type handler struct {
+	service services.OrgUnitService
+	log     *zerolog.Logger
+}
+
+func (h *handler) getById(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	unit, err := h.service.GetById(ctx.UserContext(), int64(id))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Org unit not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetById: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(unit)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) getList(ctx *fiber.Ctx) error {
+	units, err := h.service.GetList(ctx.UserContext())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetList: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(getListResponse{
+		Data: units,
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) create(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	var req createRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	unit, err := h.service.Create(ctx.UserContext(), services.OrgUnitServiceCreateOpts{
+		ScopeUserId: claims.UserId,
+		ParentId:    req.ParentId,
+		Kind:        req.Kind,
+		Name:        req.Name,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidOrgUnit):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrOrgUnitNotManaged):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Create: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(unit)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusCreated).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) update(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	var req updateRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	unit, err := h.service.Update(ctx.UserContext(), services.OrgUnitServiceUpdateOpts{
+		Id:          int64(id),
+		ScopeUserId: claims.UserId,
+		ParentId:    req.ParentId,
+		Kind:        req.Kind,
+		Name:        req.Name,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidOrgUnit):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrOrgUnitNotManaged):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Org unit not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Update: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(unit)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) delete(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	if err = h.service.Delete(ctx.UserContext(), services.OrgUnitServiceDeleteOpts{
+		Id:          int64(id),
+		ScopeUserId: claims.UserId,
+	}); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidOrgUnit):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, services.ErrOrgUnitNotManaged):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Delete: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) assignAdmin(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	var req assignAdminRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	if err = h.service.AssignAdmin(ctx.UserContext(), services.OrgUnitServiceAdminOpts{
+		ScopeUserId: claims.UserId,
+		UserId:      req.UserId,
+		OrgUnitId:   int64(id),
+	}); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidAssignee):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrOrgUnitNotManaged):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.AssignAdmin: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusCreated).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) unassignAdmin(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	userId, err := ctx.ParamsInt("userId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <userId> empty or not a number`)
+	}
+
+	if err = h.service.UnassignAdmin(ctx.UserContext(), services.OrgUnitServiceAdminOpts{
+		ScopeUserId: claims.UserId,
+		UserId:      int64(userId),
+		OrgUnitId:   int64(id),
+	}); err != nil {
+		if errors.Is(err, services.ErrOrgUnitNotManaged) {
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.UnassignAdmin: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
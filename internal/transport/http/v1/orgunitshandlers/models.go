@@ -0,0 +1,25 @@
+package orgunitshandlers
+
+import (
+	"backend/internal/models"
+)
+
+type getListResponse struct {
+	Data []models.OrgUnit `json:"data"`
+}
+
+type createRequest struct {
+	ParentId *int64 `json:"parentId"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+}
+
+type updateRequest struct {
+	ParentId *int64 `json:"parentId"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+}
+
+type assignAdminRequest struct {
+	UserId int64 `json:"userId"`
+}
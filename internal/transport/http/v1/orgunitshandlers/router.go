@@ -0,0 +1,33 @@
+package orgunitshandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type Config struct {
+	OrgUnitService services.OrgUnitService
+	JWTConfig      models.JWTConfig
+}
+
+func New(router fiber.Router, cfg Config, log *zerolog.Logger) {
+	h := handler{
+		service: cfg.OrgUnitService,
+		log:     log,
+	}
+
+	adminOnly := auth.RequireRoles(models.UserRoleAdministrator)
+
+	orgUnitGroup := router.Group("/org-unit", auth.New(cfg.JWTConfig, log))
+	orgUnitGroup.Get("/:id", h.getById)
+	orgUnitGroup.Get("/", h.getList)
+	orgUnitGroup.Post("/", adminOnly, h.create)
+	orgUnitGroup.Put("/:id", adminOnly, h.update)
+	orgUnitGroup.Delete("/:id", adminOnly, h.delete)
+	orgUnitGroup.Post("/:id/admin", adminOnly, h.assignAdmin)
+	orgUnitGroup.Delete("/:id/admin/:userId", adminOnly, h.unassignAdmin)
+}
@@ -0,0 +1,359 @@
+package peerreviewshandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+	"backend/pkg/markdown"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+)
+
+type handler struct {
+	service       services.PeerReviewService
+	answerService services.AnswerService
+	markService   services.MarkService
+	taskService   services.TaskService
+	fileService   services.FileService
+	log           *zerolog.Logger
+}
+
+// anonymous hides the reviewer from the author of the reviewed answer.
+func anonymous(review models.PeerReview, _ int) models.PeerReview {
+	review.ReviewerId = 0
+	review.ReviewerName = ""
+	return review
+}
+
+// checkTask makes sure the staff member sees the task, or may change it when
+// manage is set.
+func (h *handler) checkTask(ctx *fiber.Ctx, claims auth.Claims, taskId int64, manage bool) error {
+	task, err := h.taskService.GetById(ctx.UserContext(), taskId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.GetById: %v", err))
+	}
+
+	if !manage {
+		visible, err := h.taskService.CanView(ctx.UserContext(), task.Id, claims.UserId)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.CanView: %v", err))
+		}
+		if !visible {
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return nil
+	}
+
+	canManage, err := h.taskService.CanManage(ctx.UserContext(), task, claims.UserId, claims.Role)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.CanManage: %v", err))
+	}
+	if !canManage {
+		return fiber.NewError(fiber.StatusForbidden, "Only the creator of the task can change its peer review")
+	}
+	return nil
+}
+
+func send(ctx *fiber.Ctx, status int, response any) error {
+	responseBytes, err := jsoniter.Marshal(response)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(status).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) getSettings(ctx *fiber.Ctx) error {
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	settings, err := h.service.GetSettings(ctx.UserContext(), int64(taskId))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Task is not peer reviewed")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetSettings: %v", err))
+	}
+
+	return send(ctx, fiber.StatusOK, settings)
+}
+
+func (h *handler) setSettings(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	if err = h.checkTask(ctx, claims, int64(taskId), true); err != nil {
+		return err
+	}
+
+	var req setSettingsRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	settings, err := h.service.SetSettings(ctx.UserContext(), services.PeerReviewServiceSetSettingsOpts{
+		TaskId:           int64(taskId),
+		ReviewsPerAnswer: req.ReviewsPerAnswer,
+		RubricId:         req.RubricId,
+		MarkWeight:       req.MarkWeight,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPeerReview),
+			errors.Is(err, services.ErrInvalidRubric),
+			errors.Is(err, services.ErrRubricExceedsCost):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.SetSettings: %v", err))
+	}
+
+	return send(ctx, fiber.StatusOK, settings)
+}
+
+func (h *handler) deleteSettings(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	if err = h.checkTask(ctx, claims, int64(taskId), true); err != nil {
+		return err
+	}
+
+	if err = h.service.DeleteSettings(ctx.UserContext(), int64(taskId)); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Task is not peer reviewed")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.DeleteSettings: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+// getByTaskId lists every review of the task with its reviewer, for staff to
+// moderate.
+func (h *handler) getByTaskId(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	if err = h.checkTask(ctx, claims, int64(taskId), false); err != nil {
+		return err
+	}
+
+	reviews, err := h.service.GetList(ctx.UserContext(), services.PeerReviewServiceGetListOpts{
+		TaskId: int64(taskId),
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetList: %v", err))
+	}
+
+	return send(ctx, fiber.StatusOK, getListResponse{Data: renderReviews(reviews)})
+}
+
+// getAssigned lists the reviews the student has to write, each with the
+// answer to review.
+func (h *handler) getAssigned(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	reviews, err := h.service.GetList(ctx.UserContext(), services.PeerReviewServiceGetListOpts{
+		TaskId:     int64(taskId),
+		ReviewerId: &claims.UserId,
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetList: %v", err))
+	}
+
+	assigned := make([]assignedReview, 0, len(reviews))
+	for _, review := range renderReviews(reviews) {
+		answer, err := h.answerService.GetById(ctx.UserContext(), review.AnswerId)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.answerService.GetById: %v", err))
+		}
+		files, err := h.fileService.GetByAnswerId(ctx.UserContext(), answer.Id)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.fileService.GetByAnswerId: %v", err))
+		}
+
+		assigned = append(assigned, assignedReview{
+			PeerReview: review,
+			Answer: reviewedAnswer{
+				Comment:       answer.Comment,
+				CommentHtml:   markdown.RenderPtr(answer.Comment),
+				AttachedFiles: files,
+			},
+		})
+	}
+
+	return send(ctx, fiber.StatusOK, getAssignedResponse{Data: assigned})
+}
+
+// getReceived lists the submitted reviews of the student's answer without
+// their reviewers.
+func (h *handler) getReceived(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	answer, err := h.answerService.GetByTaskIdAndUserId(ctx.UserContext(), claims.UserId, int64(taskId))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Answer not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.answerService.GetByTaskIdAndUserId: %v", err))
+	}
+
+	reviews, err := h.service.GetList(ctx.UserContext(), services.PeerReviewServiceGetListOpts{
+		TaskId:        int64(taskId),
+		AnswerId:      &answer.Id,
+		SubmittedOnly: true,
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetList: %v", err))
+	}
+
+	return send(ctx, fiber.StatusOK, getListResponse{Data: renderReviews(lo.Map(reviews, anonymous))})
+}
+
+func (h *handler) submit(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	var req submitRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	review, err := h.service.Submit(ctx.UserContext(), services.PeerReviewServiceSubmitOpts{
+		Id:         int64(id),
+		ReviewerId: claims.UserId,
+		Score:      req.Score,
+		Text:       req.Text,
+		Criteria:   req.Criteria,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPeerReview),
+			errors.Is(err, services.ErrInvalidCriteria),
+			errors.Is(err, services.ErrMarkOutOfRange):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrPeerReviewRejected):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Peer review not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Submit: %v", err))
+	}
+
+	if err = h.markService.Regrade(ctx.UserContext(), review.AnswerId); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.markService.Regrade: %v", err))
+	}
+
+	return send(ctx, fiber.StatusAccepted, renderReview(review))
+}
+
+func (h *handler) moderate(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	current, err := h.service.GetById(ctx.UserContext(), int64(id))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Peer review not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetById: %v", err))
+	}
+	if err = h.checkTask(ctx, claims, current.TaskId, true); err != nil {
+		return err
+	}
+
+	var req moderateRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	review, err := h.service.Moderate(ctx.UserContext(), services.PeerReviewServiceModerateOpts{
+		Id:       int64(id),
+		Rejected: req.Rejected,
+		Comment:  req.Comment,
+	})
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Peer review not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Moderate: %v", err))
+	}
+
+	if err = h.markService.Regrade(ctx.UserContext(), review.AnswerId); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.markService.Regrade: %v", err))
+	}
+
+	return send(ctx, fiber.StatusAccepted, renderReview(review))
+}
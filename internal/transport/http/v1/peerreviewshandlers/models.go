@@ -0,0 +1,58 @@
+package peerreviewshandlers
+
+import (
+	"backend/internal/models"
+	"backend/pkg/markdown"
+
+	"github.com/samber/lo"
+)
+
+type setSettingsRequest struct {
+	ReviewsPerAnswer int64  `json:"reviewsPerAnswer"`
+	RubricId         *int64 `json:"rubricId"`
+	MarkWeight       int64  `json:"markWeight"`
+}
+
+type getListResponse struct {
+	Data []models.PeerReview `json:"data"`
+}
+
+// reviewedAnswer is the answer under review without anything telling who
+// wrote it.
+type reviewedAnswer struct {
+	Comment       *string       `json:"comment"`
+	CommentHtml   *string       `json:"commentHtml"`
+	AttachedFiles []models.File `json:"attachedFiles"`
+}
+
+type assignedReview struct {
+	models.PeerReview
+	Answer reviewedAnswer `json:"answer"`
+}
+
+type getAssignedResponse struct {
+	Data []assignedReview `json:"data"`
+}
+
+type submitRequest struct {
+	Score    *int64                 `json:"score"`
+	Text     *string                `json:"text"`
+	Criteria []models.MarkCriterion `json:"criteria"`
+}
+
+type moderateRequest struct {
+	Rejected bool    `json:"rejected"`
+	Comment  *string `json:"comment"`
+}
+
+// renderReview fills in the html of the review text for the response.
+func renderReview(review models.PeerReview) models.PeerReview {
+	review.TextHtml = markdown.RenderPtr(review.Text)
+	return review
+}
+
+func renderReviews(reviews []models.PeerReview) []models.PeerReview {
+	return lo.Map(reviews, func(item models.PeerReview, _ int) models.PeerReview {
+		return renderReview(item)
+	})
+}
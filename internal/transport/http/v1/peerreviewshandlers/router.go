@@ -0,0 +1,43 @@
+package peerreviewshandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type Config struct {
+	PeerReviewService services.PeerReviewService
+	AnswerService     services.AnswerService
+	MarkService       services.MarkService
+	TaskService       services.TaskService
+	FileService       services.FileService
+	JWTConfig         models.JWTConfig
+}
+
+func New(router fiber.Router, cfg Config, log *zerolog.Logger) {
+	h := handler{
+		service:       cfg.PeerReviewService,
+		answerService: cfg.AnswerService,
+		markService:   cfg.MarkService,
+		taskService:   cfg.TaskService,
+		fileService:   cfg.FileService,
+		log:           log,
+	}
+
+	staffOnly := auth.RequireRoles(models.UserRoleAdministrator, models.UserRoleTeacher)
+	studentOnly := auth.RequireRoles(models.UserRoleStudent)
+
+	peerReviewGroup := router.Group("/peer-review", auth.New(cfg.JWTConfig, log))
+	peerReviewGroup.Get("/task/:taskId/settings", h.getSettings)
+	peerReviewGroup.Put("/task/:taskId/settings", staffOnly, h.setSettings)
+	peerReviewGroup.Delete("/task/:taskId/settings", staffOnly, h.deleteSettings)
+	peerReviewGroup.Get("/task/:taskId", staffOnly, h.getByTaskId)
+	peerReviewGroup.Get("/task/:taskId/assigned", studentOnly, h.getAssigned)
+	peerReviewGroup.Get("/task/:taskId/received", studentOnly, h.getReceived)
+	peerReviewGroup.Put("/:id", studentOnly, h.submit)
+	peerReviewGroup.Put("/:id/moderation", staffOnly, h.moderate)
+}
@@ -0,0 +1,201 @@
+package questionbankshandlers
+
+import (
+	"backend/internal/repo"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog"
+)
+
+type handler struct {
+	service services.QuestionBankService
+	log     *zerolog.Logger
+}
+
+func (h *handler) getById(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	bank, err := h.service.GetById(ctx.UserContext(), int64(id))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Question bank not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetById: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(bank)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) getList(ctx *fiber.Ctx) error {
+	limit := ctx.QueryInt("limit")
+	if limit == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, `Query parameter <limit> missed or equal to zero`)
+	}
+
+	offset := ctx.QueryInt("offset", -1)
+	if offset == -1 {
+		return fiber.NewError(fiber.StatusBadRequest, `Query parameter <offset> missed`)
+	}
+
+	banks, err := h.service.GetList(ctx.UserContext(), services.QuestionBankServiceGetListOpts{
+		Limit:  int64(limit),
+		Offset: int64(offset),
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetList: %v", err))
+	}
+
+	count, err := h.service.GetCount(ctx.UserContext())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetCount: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(getListResponse{
+		Data:  banks,
+		Count: count,
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) create(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	var req createRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	bank, err := h.service.Create(ctx.UserContext(), services.QuestionBankServiceCreateOpts{
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   claims.UserId,
+		Questions:   req.Questions,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuestionBank) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Create: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(bank)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusCreated).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) update(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	var req updateRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	bank, err := h.service.Update(ctx.UserContext(), services.QuestionBankServiceUpdateOpts{
+		Id:          int64(id),
+		UserId:      claims.UserId,
+		Name:        req.Name,
+		Description: req.Description,
+		Questions:   req.Questions,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidQuestionBank):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrQuestionBankNotOwned):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		case errors.Is(err, services.ErrQuestionBankInUse):
+			return fiber.NewError(fiber.StatusConflict, "Question bank has already been answered")
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Question bank not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Update: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(bank)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) delete(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	if err = h.service.Delete(ctx.UserContext(), services.QuestionBankServiceDeleteOpts{
+		Id:     int64(id),
+		UserId: claims.UserId,
+	}); err != nil {
+		switch {
+		case errors.Is(err, services.ErrQuestionBankNotOwned):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		case errors.Is(err, services.ErrQuestionBankInUse):
+			return fiber.NewError(fiber.StatusConflict, "Question bank is attached to tasks")
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Question bank not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Delete: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
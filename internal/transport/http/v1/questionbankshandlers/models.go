@@ -0,0 +1,22 @@
+package questionbankshandlers
+
+import (
+	"backend/internal/models"
+)
+
+type getListResponse struct {
+	Data  []models.QuestionBank `json:"data"`
+	Count int64                 `json:"count"`
+}
+
+type createRequest struct {
+	Name        string            `json:"name"`
+	Description *string           `json:"description"`
+	Questions   []models.Question `json:"questions"`
+}
+
+type updateRequest struct {
+	Name        string            `json:"name"`
+	Description *string           `json:"description"`
+	Questions   []models.Question `json:"questions"`
+}
@@ -0,0 +1,32 @@
+package questionbankshandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type Config struct {
+	QuestionBankService services.QuestionBankService
+	JWTConfig           models.JWTConfig
+}
+
+func New(router fiber.Router, cfg Config, log *zerolog.Logger) {
+	h := handler{
+		service: cfg.QuestionBankService,
+		log:     log,
+	}
+
+	staffOnly := auth.RequireRoles(models.UserRoleAdministrator, models.UserRoleTeacher)
+
+	// Banks carry answer keys, so students must not read them.
+	bankGroup := router.Group("/question-bank", auth.New(cfg.JWTConfig, log), staffOnly)
+	bankGroup.Get("/:id", h.getById)
+	bankGroup.Get("/", h.getList)
+	bankGroup.Post("/", h.create)
+	bankGroup.Put("/:id", h.update)
+	bankGroup.Delete("/:id", h.delete)
+}
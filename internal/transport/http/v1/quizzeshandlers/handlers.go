@@ -0,0 +1,309 @@
+package quizzeshandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog"
+	"github.com/samber/lo"
+)
+
+type handler struct {
+	service       services.QuizService
+	answerService services.AnswerService
+	taskService   services.TaskService
+	log           *zerolog.Logger
+}
+
+// checkTask makes sure the staff member may read the quiz of the task, which
+// includes the correct answers, or change it when manage is set.
+func (h *handler) checkTask(ctx *fiber.Ctx, claims auth.Claims, taskId int64, manage bool) error {
+	task, err := h.taskService.GetById(ctx.UserContext(), taskId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.GetById: %v", err))
+	}
+
+	if !manage {
+		visible, err := h.taskService.CanView(ctx.UserContext(), task.Id, claims.UserId)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.CanView: %v", err))
+		}
+		if !visible {
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return nil
+	}
+
+	canManage, err := h.taskService.CanManage(ctx.UserContext(), task, claims.UserId, claims.Role)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.CanManage: %v", err))
+	}
+	if !canManage {
+		return fiber.NewError(fiber.StatusForbidden, "Only the creator of the task can change its quiz")
+	}
+	return nil
+}
+
+func (h *handler) getByTaskId(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	if err = h.checkTask(ctx, claims, int64(taskId), false); err != nil {
+		return err
+	}
+
+	quiz, err := h.service.GetByTaskId(ctx.UserContext(), int64(taskId))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Quiz not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetByTaskId: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(quiz)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) set(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	if err = h.checkTask(ctx, claims, int64(taskId), true); err != nil {
+		return err
+	}
+
+	var req setRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	quiz, err := h.service.Set(ctx.UserContext(), services.QuizServiceSetOpts{
+		TaskId:        int64(taskId),
+		BankId:        req.BankId,
+		QuestionCount: req.QuestionCount,
+		Shuffle:       req.Shuffle,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidQuiz):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrQuizAnswered):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Set: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(quiz)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) delete(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	if err = h.checkTask(ctx, claims, int64(taskId), true); err != nil {
+		return err
+	}
+
+	if err = h.service.Delete(ctx.UserContext(), int64(taskId)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrQuizAnswered):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Quiz not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Delete: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) getQuestions(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	questions, err := h.service.GetQuestions(ctx.UserContext(), int64(taskId), claims.UserId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTaskNotStarted):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		case errors.Is(err, repo.ErrNotFound), errors.Is(err, services.ErrNotTaskAssignee):
+			return fiber.NewError(fiber.StatusNotFound, "Quiz not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetQuestions: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(getQuestionsResponse{
+		Data: lo.Map(questions, func(item models.Question, _ int) question {
+			return question{
+				Id:      item.Id,
+				Kind:    item.Kind,
+				Text:    item.Text,
+				Points:  item.Points,
+				Options: item.Options,
+			}
+		}),
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) submit(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	var req submitRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	mark, err := h.service.Submit(ctx.UserContext(), services.QuizServiceSubmitOpts{
+		TaskId:    int64(taskId),
+		UserId:    claims.UserId,
+		Responses: req.Responses,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidQuizResponse):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrTaskNotStarted),
+			errors.Is(err, services.ErrDeadlinePassed),
+			errors.Is(err, services.ErrTaskLocked):
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		case errors.Is(err, services.ErrQuizSubmitted):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, repo.ErrNotFound), errors.Is(err, services.ErrNotTaskAssignee):
+			return fiber.NewError(fiber.StatusNotFound, "Quiz not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Submit: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(mark)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusCreated).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) getResponses(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	answerId, err := ctx.ParamsInt("answerId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <answerId> empty or not a number`)
+	}
+
+	answer, err := h.answerService.GetById(ctx.UserContext(), int64(answerId))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Answer not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.answerService.GetById: %v", err))
+	}
+	canView, err := h.answerService.CanView(ctx.UserContext(), answer, claims.UserId, claims.Role)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.answerService.CanView: %v", err))
+	}
+	if !canView {
+		return fiber.NewError(fiber.StatusNotFound, "Answer not found")
+	}
+
+	responses, err := h.service.GetResponses(ctx.UserContext(), answer.Id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetResponses: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(getResponsesResponse{
+		Data: responses,
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
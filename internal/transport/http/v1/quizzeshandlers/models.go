@@ -0,0 +1,32 @@
+package quizzeshandlers
+
+import (
+	"backend/internal/models"
+)
+
+type setRequest struct {
+	BankId        int64  `json:"bankId"`
+	QuestionCount *int64 `json:"questionCount"`
+	Shuffle       bool   `json:"shuffle"`
+}
+
+// question is a quiz question as shown to students, without its answer key.
+type question struct {
+	Id      int64    `json:"id"`
+	Kind    string   `json:"kind"`
+	Text    string   `json:"text"`
+	Points  int64    `json:"points"`
+	Options []string `json:"options,omitempty"`
+}
+
+type getQuestionsResponse struct {
+	Data []question `json:"data"`
+}
+
+type submitRequest struct {
+	Responses []models.QuizResponse `json:"responses"`
+}
+
+type getResponsesResponse struct {
+	Data []models.QuizResponse `json:"data"`
+}
@@ -0,0 +1,37 @@
+package quizzeshandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type Config struct {
+	QuizService   services.QuizService
+	AnswerService services.AnswerService
+	TaskService   services.TaskService
+	JWTConfig     models.JWTConfig
+}
+
+func New(router fiber.Router, cfg Config, log *zerolog.Logger) {
+	h := handler{
+		service:       cfg.QuizService,
+		answerService: cfg.AnswerService,
+		taskService:   cfg.TaskService,
+		log:           log,
+	}
+
+	staffOnly := auth.RequireRoles(models.UserRoleAdministrator, models.UserRoleTeacher)
+	studentOnly := auth.RequireRoles(models.UserRoleStudent)
+
+	quizGroup := router.Group("/quiz", auth.New(cfg.JWTConfig, log))
+	quizGroup.Get("/answer/:answerId", h.getResponses)
+	quizGroup.Get("/:taskId", staffOnly, h.getByTaskId)
+	quizGroup.Put("/:taskId", staffOnly, h.set)
+	quizGroup.Delete("/:taskId", staffOnly, h.delete)
+	quizGroup.Get("/:taskId/questions", studentOnly, h.getQuestions)
+	quizGroup.Post("/:taskId/submit", studentOnly, h.submit)
+}
@@ -0,0 +1,22 @@
+package rubricshandlers
+
+import (
+	"backend/internal/models"
+)
+
+type getListResponse struct {
+	Data  []models.Rubric `json:"data"`
+	Count int64           `json:"count"`
+}
+
+type createRequest struct {
+	Name        string                   `json:"name"`
+	Description *string                  `json:"description"`
+	Criteria    []models.RubricCriterion `json:"criteria"`
+}
+
+type updateRequest struct {
+	Name        string                   `json:"name"`
+	Description *string                  `json:"description"`
+	Criteria    []models.RubricCriterion `json:"criteria"`
+}
@@ -0,0 +1,31 @@
+package rubricshandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type Config struct {
+	RubricService services.RubricService
+	JWTConfig     models.JWTConfig
+}
+
+func New(router fiber.Router, cfg Config, log *zerolog.Logger) {
+	h := handler{
+		service: cfg.RubricService,
+		log:     log,
+	}
+
+	staffOnly := auth.RequireRoles(models.UserRoleAdministrator, models.UserRoleTeacher)
+
+	rubricGroup := router.Group("/rubric", auth.New(cfg.JWTConfig, log))
+	rubricGroup.Get("/:id", h.getById)
+	rubricGroup.Get("/", h.getList)
+	rubricGroup.Post("/", staffOnly, h.create)
+	rubricGroup.Put("/:id", staffOnly, h.update)
+	rubricGroup.Delete("/:id", staffOnly, h.delete)
+}
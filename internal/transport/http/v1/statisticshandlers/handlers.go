@@ -2,6 +2,8 @@ package statisticshandlers
 
 import (
 	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	jsoniter "github.com/json-iterator/go"
@@ -15,6 +17,11 @@ type handler struct {
 }
 
 func (h *handler) get(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
 	limit := ctx.QueryInt("limit")
 	if limit == 0 {
 		return fiber.NewError(fiber.StatusBadRequest, `Query parameter <limit> missed or equal to zero`)
@@ -26,8 +33,11 @@ func (h *handler) get(ctx *fiber.Ctx) error {
 	}
 
 	opts := services.GetStatisticsOpts{
-		Limit:  int64(limit),
-		Offset: int64(offset),
+		ScopeUserId: claims.ScopeUserId(),
+		Level:       ctx.Query("level"),
+		Normalize:   ctx.QueryBool("normalize"),
+		Limit:       int64(limit),
+		Offset:      int64(offset),
 	}
 
 	queries := ctx.Queries()
@@ -52,6 +62,9 @@ func (h *handler) get(ctx *fiber.Ctx) error {
 
 	statistics, err := h.service.GetStatistics(ctx.UserContext(), opts)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidStatisticsLevel) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetStatistics: %v", err))
 	}
 
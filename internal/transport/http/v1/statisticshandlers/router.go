@@ -3,7 +3,7 @@ package statisticshandlers
 import (
 	"backend/internal/models"
 	"backend/internal/services"
-	jwtware "github.com/gofiber/contrib/jwt"
+	"backend/internal/transport/http/auth"
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 )
@@ -19,10 +19,6 @@ func New(router fiber.Router, cfg Config, log *zerolog.Logger) {
 		log:     log,
 	}
 
-	statisticsGroup := router.Group("/statistics", jwtware.New(jwtware.Config{
-		SigningKey: jwtware.SigningKey{
-			Key: []byte(cfg.JWTConfig.JWTAccessSecretKey),
-		},
-	}))
+	statisticsGroup := router.Group("/statistics", auth.New(cfg.JWTConfig, log))
 	statisticsGroup.Get("/", h.get)
 }
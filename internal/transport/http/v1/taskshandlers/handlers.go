@@ -1,40 +1,109 @@
 package taskshandlers
 
 import (
+	"backend/internal/models"
+	"backend/internal/repo"
 	"backend/internal/services"
 	"backend/internal/transport/http/auth"
+	"backend/internal/transport/http/filter"
+	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/rs/zerolog"
 	"github.com/samber/lo"
+	"time"
 )
 
 type handler struct {
-	service     services.TaskService
-	fileService services.FileService
-	log         *zerolog.Logger
+	service             services.TaskService
+	fileService         services.FileService
+	seriesService       services.TaskSeriesService
+	prerequisiteService services.TaskPrerequisiteService
+	log                 *zerolog.Logger
+}
+
+// getManaged loads the task the caller is about to change and makes sure the
+// caller may manage it.
+func (h *handler) getManaged(ctx *fiber.Ctx, claims auth.Claims, id int64) (models.Task, error) {
+	task, err := h.service.GetById(ctx.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return models.Task{}, fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return models.Task{}, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetById: %v", err))
+	}
+
+	canManage, err := h.service.CanManage(ctx.UserContext(), task, claims.UserId, claims.Role)
+	if err != nil {
+		return models.Task{}, fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.CanManage: %v", err))
+	}
+	if !canManage {
+		return models.Task{}, fiber.NewError(fiber.StatusForbidden, "Only the creator of the task can change it")
+	}
+	return task, nil
 }
 
 func (h *handler) getById(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
 	id, err := ctx.ParamsInt("id")
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
 	}
 
-	task, err := h.service.GetById(ctx.UserContext(), int64(id))
+	var task models.Task
+	if claims.Role == models.UserRoleStudent {
+		task, err = h.service.GetByIdForUser(ctx.UserContext(), int64(id), claims.UserId)
+	} else {
+		task, err = h.service.GetById(ctx.UserContext(), int64(id))
+	}
 	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetById: %v", err))
 	}
 
+	if claims.Role == models.UserRoleStudent &&
+		(task.State != models.TaskStatePublished || time.Now().Before(task.EffectiveFrom)) {
+		return fiber.NewError(fiber.StatusNotFound, "Task not found")
+	}
+	if claims.Role != models.UserRoleStudent {
+		visible, err := h.service.CanView(ctx.UserContext(), task.Id, claims.UserId)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.CanView: %v", err))
+		}
+		if !visible {
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+	}
+
 	attachedFiles, err := h.fileService.GetByTaskId(ctx.UserContext(), task.Id)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetById: %v", err))
 	}
 
+	links, err := h.service.GetLinks(ctx.UserContext(), task.Id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetLinks: %v", err))
+	}
+
 	responseBytes, err := jsoniter.Marshal(getByIdResponse{
-		Task:          task,
+		Task:          renderTask(task),
 		AttachedFiles: attachedFiles,
+		UserIds: lo.FilterMap(links, func(item models.TaskLink, _ int) (int64, bool) {
+			return lo.FromPtr(item.UserId), item.UserId != nil
+		}),
+		GroupIds: lo.FilterMap(links, func(item models.TaskLink, _ int) (int64, bool) {
+			return lo.FromPtr(item.GroupId), item.GroupId != nil
+		}),
+		Extensions: lo.Filter(links, func(item models.TaskLink, _ int) bool {
+			return claims.Role != models.UserRoleStudent && (item.EffectiveFrom != nil || item.EffectiveTill != nil)
+		}),
 	})
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
@@ -64,23 +133,31 @@ func (h *handler) getList(ctx *fiber.Ctx) error {
 	}
 
 	creator := ctx.QueryBool("creator")
-	if creator {
-		tasks, err := h.service.GetListForCreator(ctx.UserContext(), services.TaskServiceGetListForCreatorOpts{
-			CreatedBy: claims.UserId,
-			Limit:     int64(limit),
-			Offset:    int64(offset),
-		})
+	template := ctx.QueryBool("template")
+	if creator || template {
+		opts := services.TaskServiceGetListForCreatorOpts{
+			CreatedBy:  claims.UserId,
+			IsTemplate: template,
+			Filter:     filter.FromQuery(ctx, filterFields),
+			Limit:      int64(limit),
+			Offset:     int64(offset),
+		}
+
+		tasks, err := h.service.GetListForCreator(ctx.UserContext(), opts)
 		if err != nil {
+			if errors.Is(err, repo.ErrInvalidFilter) {
+				return fiber.NewError(fiber.StatusBadRequest, err.Error())
+			}
 			return fmt.Errorf("h.service.GetListForCreator: %w", err)
 		}
 
-		count, err := h.service.GetCountForCreator(ctx.UserContext(), claims.UserId)
+		count, err := h.service.GetCountForCreator(ctx.UserContext(), opts)
 		if err != nil {
 			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetCountForCreator: %v", err))
 		}
 
 		responseBytes, err := jsoniter.Marshal(getListResponse{
-			Tasks: tasks,
+			Tasks: renderTasks(tasks),
 			Count: count,
 		})
 		if err != nil {
@@ -94,22 +171,75 @@ func (h *handler) getList(ctx *fiber.Ctx) error {
 		return nil
 	}
 
-	tasks, err := h.service.GetListForUser(ctx.UserContext(), services.TaskServiceGetListForUserOpts{
+	if claims.Role == models.UserRoleObserver {
+		opts := services.TaskServiceGetListForScopeOpts{
+			ScopeUserId: claims.UserId,
+			Filter:      filter.FromQuery(ctx, filterFields),
+			Limit:       int64(limit),
+			Offset:      int64(offset),
+		}
+
+		tasks, err := h.service.GetListForScope(ctx.UserContext(), opts)
+		if err != nil {
+			if errors.Is(err, repo.ErrInvalidFilter) {
+				return fiber.NewError(fiber.StatusBadRequest, err.Error())
+			}
+			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetListForScope: %v", err))
+		}
+
+		count, err := h.service.GetCountForScope(ctx.UserContext(), opts)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetCountForScope: %v", err))
+		}
+
+		responseBytes, err := jsoniter.Marshal(getListResponse{
+			Tasks: renderTasks(tasks),
+			Count: count,
+		})
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+		}
+
+		if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+		}
+
+		return nil
+	}
+
+	opts := services.TaskServiceGetListForUserOpts{
 		UserId:  claims.UserId,
 		GroupId: lo.FromPtr(claims.GroupId),
+		Filter:  filter.FromQuery(ctx, userFilterFields),
 		Limit:   int64(limit),
 		Offset:  int64(offset),
-	})
+	}
+
+	tasks, err := h.service.GetListForUser(ctx.UserContext(), opts)
 	if err != nil {
+		if errors.Is(err, repo.ErrInvalidFilter) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetListForUser: %v", err))
 	}
 
-	count, err := h.service.GetCountForUser(ctx.UserContext(), claims.UserId, lo.FromPtr(claims.GroupId))
+	unmet, err := h.prerequisiteService.GetUnmet(ctx.UserContext(), claims.UserId, lo.Map(tasks, func(item models.UserTask, _ int) int64 {
+		return item.Id
+	}))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.prerequisiteService.GetUnmet: %v", err))
+	}
+	for i := range tasks {
+		tasks[i].Task = renderTask(tasks[i].Task)
+		tasks[i].LockedBy = unmet[tasks[i].Id]
+	}
+
+	count, err := h.service.GetCountForUser(ctx.UserContext(), opts)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetCountForUser: %v", err))
 	}
 
-	responseBytes, err := jsoniter.Marshal(getListResponse{
+	responseBytes, err := jsoniter.Marshal(getListForUserResponse{
 		Tasks: tasks,
 		Count: count,
 	})
@@ -136,20 +266,34 @@ func (h *handler) create(ctx *fiber.Ctx) error {
 	}
 
 	task, err := h.service.Create(ctx.UserContext(), services.TaskServiceCreateOpts{
-		GroupIds:      req.GroupIds,
-		UserIds:       req.UserIds,
-		Title:         req.Title,
-		Text:          req.Text,
-		EffectiveFrom: req.EffectiveFrom,
-		EffectiveTill: req.EffectiveTill,
-		FileIds:       req.FileIds,
-		CreatedBy:     claims.UserId,
+		ScopeUserId:    claims.ScopeUserId(),
+		GroupIds:       req.GroupIds,
+		UserIds:        req.UserIds,
+		Title:          req.Title,
+		Text:           req.Text,
+		EffectiveFrom:  req.EffectiveFrom,
+		EffectiveTill:  req.EffectiveTill,
+		LatePolicy:     req.LatePolicy,
+		LatePenalty:    req.LatePenalty,
+		LateGraceHours: req.LateGraceHours,
+		Cost:           req.Cost,
+		RubricId:       req.RubricId,
+		FileIds:        req.FileIds,
+		CreatedBy:      claims.UserId,
+		Draft:          req.Draft,
+		PublishAt:      req.PublishAt,
 	})
 	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrGroupNotAssigned):
+			return fiber.NewError(fiber.StatusForbidden, "Task can be assigned only to students of your groups")
+		case isValidationError(err):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Create: %v", err))
 	}
 
-	responseBytes, err := jsoniter.Marshal(task)
+	responseBytes, err := jsoniter.Marshal(renderTask(task))
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
 	}
@@ -162,7 +306,10 @@ func (h *handler) create(ctx *fiber.Ctx) error {
 }
 
 func (h *handler) update(ctx *fiber.Ctx) error {
-	groupId := 0
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
 
 	id, err := ctx.ParamsInt("id")
 	if err != nil {
@@ -174,20 +321,253 @@ func (h *handler) update(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
 	}
 
+	if _, err = h.getManaged(ctx, claims, int64(id)); err != nil {
+		return err
+	}
+
 	_, err = h.service.Update(ctx.UserContext(), services.TaskServiceUpdateOpts{
+		Id:             int64(id),
+		UpdatedBy:      claims.UserId,
+		ScopeUserId:    claims.ScopeUserId(),
+		Title:          req.Title,
+		Text:           req.Text,
+		EffectiveFrom:  req.EffectiveFrom,
+		EffectiveTill:  req.EffectiveTill,
+		LatePolicy:     req.LatePolicy,
+		LatePenalty:    req.LatePenalty,
+		LateGraceHours: req.LateGraceHours,
+		Cost:           req.Cost,
+		RubricId:       req.RubricId,
+		UserIds:        req.UserIds,
+		GroupIds:       req.GroupIds,
+		FileIds:        req.FileIds,
+		ApplyToSeries:  ctx.QueryBool("series"),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrGroupNotAssigned):
+			return fiber.NewError(fiber.StatusForbidden, "Task can be assigned only to students of your groups")
+		case isValidationError(err):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Update: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) clone(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	var req cloneRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	return h.sendClone(ctx, services.TaskServiceCloneOpts{
+		Id:            int64(id),
+		ScopeUserId:   claims.ScopeUserId(),
+		CreatedBy:     claims.UserId,
+		EffectiveFrom: req.EffectiveFrom,
+		UserIds:       req.UserIds,
+		GroupIds:      req.GroupIds,
+		Draft:         req.Draft,
+	})
+}
+
+func (h *handler) saveAsTemplate(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	return h.sendClone(ctx, services.TaskServiceCloneOpts{
+		Id:         int64(id),
+		CreatedBy:  claims.UserId,
+		AsTemplate: true,
+	})
+}
+
+func (h *handler) publish(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	if _, err = h.getManaged(ctx, claims, int64(id)); err != nil {
+		return err
+	}
+
+	var req publishRequest
+	if len(ctx.Body()) > 0 {
+		if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+		}
+	}
+
+	task, err := h.service.Publish(ctx.UserContext(), services.TaskServicePublishOpts{
+		Id:        int64(id),
+		PublishAt: req.PublishAt,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTemplatePublished):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrTaskPublished):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Publish: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(renderTask(task))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) extend(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	if _, err = h.getManaged(ctx, claims, int64(id)); err != nil {
+		return err
+	}
+
+	var req extensionRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	return h.sendExtend(ctx, services.TaskServiceExtendOpts{
 		Id:            int64(id),
-		GroupId:       int64(groupId),
-		Title:         req.Title,
-		Text:          req.Text,
+		ScopeUserId:   claims.ScopeUserId(),
+		UserId:        req.UserId,
+		GroupId:       req.GroupId,
 		EffectiveFrom: req.EffectiveFrom,
 		EffectiveTill: req.EffectiveTill,
-		Cost:          req.Cost,
 	})
+}
+
+func (h *handler) removeExtension(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Create: %v", err))
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
 	}
 
-	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	if _, err = h.getManaged(ctx, claims, int64(id)); err != nil {
+		return err
+	}
+
+	opts := services.TaskServiceExtendOpts{
+		Id:          int64(id),
+		ScopeUserId: claims.ScopeUserId(),
+	}
+	if userId := ctx.QueryInt("userId", -1); userId != -1 {
+		opts.UserId = lo.ToPtr(int64(userId))
+	}
+	if groupId := ctx.QueryInt("groupId", -1); groupId != -1 {
+		opts.GroupId = lo.ToPtr(int64(groupId))
+	}
+
+	return h.sendExtend(ctx, opts)
+}
+
+func (h *handler) sendExtend(ctx *fiber.Ctx, opts services.TaskServiceExtendOpts) error {
+	if err := h.service.Extend(ctx.UserContext(), opts); err != nil {
+		switch {
+		case errors.Is(err, services.ErrGroupNotAssigned):
+			return fiber.NewError(fiber.StatusForbidden, "Task can be assigned only to students of your groups")
+		case isValidationError(err):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Extend: %v", err))
+	}
+
+	if err := ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+// sendClone clones a task the caller sees and sends the copy back.
+func (h *handler) sendClone(ctx *fiber.Ctx, opts services.TaskServiceCloneOpts) error {
+	visible, err := h.service.CanView(ctx.UserContext(), opts.Id, opts.CreatedBy)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.CanView: %v", err))
+	}
+	if !visible {
+		return fiber.NewError(fiber.StatusNotFound, "Task not found")
+	}
+
+	task, err := h.service.Clone(ctx.UserContext(), opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrGroupNotAssigned):
+			return fiber.NewError(fiber.StatusForbidden, "Task can be assigned only to students of your groups")
+		case isValidationError(err):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Clone: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(renderTask(task))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusCreated).Send(responseBytes); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
 	}
 
@@ -210,3 +590,247 @@ func (h *handler) delete(ctx *fiber.Ctx) error {
 
 	return nil
 }
+
+func (h *handler) getRecurrence(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	if _, err = h.getManaged(ctx, claims, int64(id)); err != nil {
+		return err
+	}
+
+	series, err := h.seriesService.GetByTaskId(ctx.UserContext(), int64(id))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Task series not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.seriesService.GetByTaskId: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(series)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) setRecurrence(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	if _, err = h.getManaged(ctx, claims, int64(id)); err != nil {
+		return err
+	}
+
+	var req recurrenceRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	series, err := h.seriesService.SetRule(ctx.UserContext(), services.TaskSeriesServiceSetRuleOpts{
+		TaskId: int64(id),
+		RRule:  req.RRule,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRecurrence),
+			errors.Is(err, services.ErrRecurringTemplate):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrSeriesCancelled):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.seriesService.SetRule: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(series)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) cancelRecurrence(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	if _, err = h.getManaged(ctx, claims, int64(id)); err != nil {
+		return err
+	}
+
+	if err = h.seriesService.Cancel(ctx.UserContext(), int64(id)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrSeriesCancelled):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Task series not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.seriesService.Cancel: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) getPrerequisites(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	prerequisites, err := h.prerequisiteService.GetByTaskId(ctx.UserContext(), int64(id))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.prerequisiteService.GetByTaskId: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(getPrerequisitesResponse{
+		Data: prerequisites,
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) createPrerequisite(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	if _, err = h.getManaged(ctx, claims, int64(id)); err != nil {
+		return err
+	}
+
+	var req createPrerequisiteRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	visible, err := h.service.CanView(ctx.UserContext(), req.PrerequisiteTaskId, claims.UserId)
+	if err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.CanView: %v", err))
+	}
+	if !visible {
+		return fiber.NewError(fiber.StatusNotFound, "Prerequisite task not found")
+	}
+
+	prerequisite, err := h.prerequisiteService.Create(ctx.UserContext(), services.TaskPrerequisiteServiceCreateOpts{
+		TaskId:             int64(id),
+		PrerequisiteTaskId: req.PrerequisiteTaskId,
+		Condition:          req.Condition,
+		MinMark:            req.MinMark,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPrerequisite):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrPrerequisiteCycle):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.prerequisiteService.Create: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(prerequisite)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusCreated).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) deletePrerequisite(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	if _, err = h.getManaged(ctx, claims, int64(id)); err != nil {
+		return err
+	}
+
+	prerequisiteId, err := ctx.ParamsInt("prerequisiteId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <prerequisiteId> empty or not a number`)
+	}
+
+	if err = h.prerequisiteService.Delete(ctx.UserContext(), int64(id), int64(prerequisiteId)); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Prerequisite not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.prerequisiteService.Delete: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func isValidationError(err error) bool {
+	return errors.Is(err, services.ErrInvalidLatePolicy) ||
+		errors.Is(err, services.ErrInvalidTaskCost) ||
+		errors.Is(err, services.ErrCostBelowMarks) ||
+		errors.Is(err, services.ErrInvalidRubric) ||
+		errors.Is(err, services.ErrRubricExceedsCost) ||
+		errors.Is(err, services.ErrTemplateAssigned) ||
+		errors.Is(err, services.ErrInvalidExtension) ||
+		errors.Is(err, services.ErrNotTaskAssignee)
+}
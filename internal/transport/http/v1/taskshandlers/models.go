@@ -2,12 +2,22 @@ package taskshandlers
 
 import (
 	"backend/internal/models"
+	"backend/pkg/markdown"
 	"time"
+
+	"github.com/samber/lo"
 )
 
+var filterFields = []string{"title", "createdBy", "effectiveFrom", "deadline", "cost", "rubricId", "seriesId", "latePolicy", "state"}
+
+var userFilterFields = append([]string{"status"}, filterFields...)
+
 type getByIdResponse struct {
-	Task          models.Task   `json:"task"`
-	AttachedFiles []models.File `json:"attachedFiles"`
+	Task          models.Task       `json:"task"`
+	AttachedFiles []models.File     `json:"attachedFiles"`
+	UserIds       []int64           `json:"userIds"`
+	GroupIds      []int64           `json:"groupIds"`
+	Extensions    []models.TaskLink `json:"extensions"`
 }
 
 type getListResponse struct {
@@ -15,20 +25,83 @@ type getListResponse struct {
 	Count int64         `json:"count"`
 }
 
+type getListForUserResponse struct {
+	Tasks []models.UserTask `json:"data"`
+	Count int64             `json:"count"`
+}
+
 type createRequest struct {
-	GroupIds      []int64    `json:"groupIds"`
+	GroupIds       []int64    `json:"groupIds"`
+	UserIds        []int64    `json:"userIds"`
+	Title          string     `json:"title"`
+	Text           *string    `json:"text,omitempty"`
+	EffectiveFrom  *time.Time `json:"effectiveFrom"`
+	EffectiveTill  time.Time  `json:"effectiveTill"`
+	LatePolicy     string     `json:"latePolicy"`
+	LatePenalty    int64      `json:"latePenalty"`
+	LateGraceHours int64      `json:"lateGraceHours"`
+	Cost           int64      `json:"cost"`
+	RubricId       *int64     `json:"rubricId"`
+	FileIds        []int64    `json:"fileIds"`
+	Draft          bool       `json:"draft"`
+	PublishAt      *time.Time `json:"publishAt"`
+}
+
+type updateRequest struct {
+	Title          string    `json:"title"`
+	Text           string    `json:"text"`
+	EffectiveFrom  time.Time `json:"effectiveFrom"`
+	EffectiveTill  time.Time `json:"effectiveTill"`
+	LatePolicy     string    `json:"latePolicy"`
+	LatePenalty    int64     `json:"latePenalty"`
+	LateGraceHours int64     `json:"lateGraceHours"`
+	Cost           int64     `json:"cost"`
+	RubricId       *int64    `json:"rubricId"`
+	UserIds        []int64   `json:"userIds"`
+	GroupIds       []int64   `json:"groupIds"`
+	FileIds        []int64   `json:"fileIds"`
+}
+
+type cloneRequest struct {
+	EffectiveFrom *time.Time `json:"effectiveFrom"`
 	UserIds       []int64    `json:"userIds"`
-	Title         string     `json:"title"`
-	Text          *string    `json:"text,omitempty"`
+	GroupIds      []int64    `json:"groupIds"`
+	Draft         bool       `json:"draft"`
+}
+
+type extensionRequest struct {
+	UserId        *int64     `json:"userId"`
+	GroupId       *int64     `json:"groupId"`
 	EffectiveFrom *time.Time `json:"effectiveFrom"`
-	EffectiveTill time.Time  `json:"effectiveTill"`
-	FileIds       []int64    `json:"fileIds"`
+	EffectiveTill *time.Time `json:"effectiveTill"`
 }
 
-type updateRequest struct {
-	Title         string    `json:"title"`
-	Text          string    `json:"text"`
-	EffectiveFrom time.Time `json:"effectiveFrom"`
-	EffectiveTill time.Time `json:"effectiveTill"`
-	Cost          int64     `json:"cost"`
+type createPrerequisiteRequest struct {
+	PrerequisiteTaskId int64  `json:"prerequisiteTaskId"`
+	Condition          string `json:"condition"`
+	MinMark            *int64 `json:"minMark"`
+}
+
+type getPrerequisitesResponse struct {
+	Data []models.TaskPrerequisite `json:"data"`
+}
+
+type publishRequest struct {
+	PublishAt *time.Time `json:"publishAt"`
+}
+
+type recurrenceRequest struct {
+	RRule string `json:"rrule"`
+}
+
+// renderTask fills in the html of the task text for the response.
+func renderTask(task models.Task) models.Task {
+	task.TextHtml = markdown.RenderPtr(task.Text)
+	return task
+}
+
+func renderTasks(tasks []models.Task) []models.Task {
+	return lo.Map(tasks, func(item models.Task, _ int) models.Task {
+		return renderTask(item)
+	})
 }
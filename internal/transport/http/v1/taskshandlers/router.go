@@ -3,54 +3,46 @@ package taskshandlers
 import (
 	"backend/internal/models"
 	"backend/internal/services"
-	jwtware "github.com/gofiber/contrib/jwt"
-	"github.com/golang-jwt/jwt/v5"
-	"strings"
+	"backend/internal/transport/http/auth"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog"
 )
 
 type Config struct {
-	TaskService services.TaskService
-	FileService services.FileService
-	JWTConfig   models.JWTConfig
+	TaskService             services.TaskService
+	FileService             services.FileService
+	TaskSeriesService       services.TaskSeriesService
+	TaskPrerequisiteService services.TaskPrerequisiteService
+	JWTConfig               models.JWTConfig
 }
 
 func New(router fiber.Router, cfg Config, log *zerolog.Logger) {
 	h := handler{
-		service:     cfg.TaskService,
-		fileService: cfg.FileService,
-		log:         log,
+		service:             cfg.TaskService,
+		fileService:         cfg.FileService,
+		seriesService:       cfg.TaskSeriesService,
+		prerequisiteService: cfg.TaskPrerequisiteService,
+		log:                 log,
 	}
 
-	taskGroup := router.Group("/task", jwtware.New(jwtware.Config{
-		SigningKey: jwtware.SigningKey{
-			Key: []byte(cfg.JWTConfig.JWTAccessSecretKey),
-		},
-		SuccessHandler: func(ctx *fiber.Ctx) error {
-			authorizationHeaderValue := ctx.Get(fiber.HeaderAuthorization)
-			token := strings.Split(authorizationHeaderValue, "Bearer ")[1]
-			if len(token) == 0 {
-				return fiber.NewError(fiber.StatusUnauthorized, "missed jwt token")
-			}
+	staffOnly := auth.RequireRoles(models.UserRoleAdministrator, models.UserRoleTeacher)
 
-			claims := jwt.MapClaims{}
-			_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-				return []byte(cfg.JWTConfig.JWTAccessSecretKey), nil
-			})
-			if err != nil {
-				log.Error().Err(err).Send()
-				return fiber.NewError(fiber.StatusUnauthorized, "err token parse")
-			}
-
-			ctx.Locals("claims", claims)
-			return ctx.Next()
-		},
-	}))
+	taskGroup := router.Group("/task", auth.New(cfg.JWTConfig, log))
 	taskGroup.Get("/:id", h.getById)
 	taskGroup.Get("/", h.getList)
 	taskGroup.Post("/", h.create)
+	taskGroup.Post("/:id/clone", staffOnly, h.clone)
+	taskGroup.Post("/:id/template", staffOnly, h.saveAsTemplate)
+	taskGroup.Post("/:id/publish", staffOnly, h.publish)
+	taskGroup.Get("/:id/prerequisites", h.getPrerequisites)
+	taskGroup.Post("/:id/prerequisites", staffOnly, h.createPrerequisite)
+	taskGroup.Delete("/:id/prerequisites/:prerequisiteId", staffOnly, h.deletePrerequisite)
+	taskGroup.Put("/:id/extension", staffOnly, h.extend)
+	taskGroup.Delete("/:id/extension", staffOnly, h.removeExtension)
+	taskGroup.Get("/:id/recurrence", staffOnly, h.getRecurrence)
+	taskGroup.Put("/:id/recurrence", staffOnly, h.setRecurrence)
+	taskGroup.Delete("/:id/recurrence", staffOnly, h.cancelRecurrence)
 	taskGroup.Put("/:id", h.update)
 	taskGroup.Delete("/:id", h.delete)
 }
@@ -0,0 +1,469 @@
+package teamshandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog"
+)
+
+type handler struct {
+	service     services.TeamService
+	taskService services.TaskService
+	log         *zerolog.Logger
+}
+
+// checkManaged makes sure the caller may change the task, see
+// TaskService.CanManage.
+func (h *handler) checkManaged(ctx *fiber.Ctx, claims auth.Claims, taskId int64) error {
+	task, err := h.taskService.GetById(ctx.UserContext(), taskId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.GetById: %v", err))
+	}
+
+	canManage, err := h.taskService.CanManage(ctx.UserContext(), task, claims.UserId, claims.Role)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.CanManage: %v", err))
+	}
+	if !canManage {
+		return fiber.NewError(fiber.StatusForbidden, "Only the creator of the task can change its teams")
+	}
+	return nil
+}
+
+// checkVisible makes sure the staff member sees the task or the student is
+// assigned to it.
+func (h *handler) checkVisible(ctx *fiber.Ctx, claims auth.Claims, taskId int64) error {
+	if claims.Role == models.UserRoleStudent {
+		assigned, err := h.taskService.IsAssigned(ctx.UserContext(), taskId, claims.UserId)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.IsAssigned: %v", err))
+		}
+		if !assigned {
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return nil
+	}
+
+	visible, err := h.taskService.CanView(ctx.UserContext(), taskId, claims.UserId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.taskService.CanView: %v", err))
+	}
+	if !visible {
+		return fiber.NewError(fiber.StatusNotFound, "Task not found")
+	}
+	return nil
+}
+
+// checkManagedTeam makes sure the caller may change the task of the team.
+func (h *handler) checkManagedTeam(ctx *fiber.Ctx, claims auth.Claims, teamId int64) error {
+	team, err := h.service.GetById(ctx.UserContext(), teamId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Team not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetById: %v", err))
+	}
+	return h.checkManaged(ctx, claims, team.TaskId)
+}
+
+// serviceError maps the errors of team changes to responses.
+func serviceError(err error, method string) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidTeam):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrSelfSignupDisabled), errors.Is(err, services.ErrTeamLocked):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrAlreadyInTeam):
+		return fiber.NewError(fiber.StatusConflict, "User is already in a team for this task")
+	case errors.Is(err, services.ErrTeamFull):
+		return fiber.NewError(fiber.StatusConflict, "Team is full")
+	case errors.Is(err, repo.ErrNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Team not found")
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.%s: %v", method, err))
+}
+
+func (h *handler) getSettings(ctx *fiber.Ctx) error {
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	settings, err := h.service.GetSettings(ctx.UserContext(), int64(taskId))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Task is not a team task")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetSettings: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(settings)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) setSettings(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	if err = h.checkManaged(ctx, claims, int64(taskId)); err != nil {
+		return err
+	}
+
+	var req setSettingsRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	settings, err := h.service.SetSettings(ctx.UserContext(), services.TeamServiceSetSettingsOpts{
+		TaskId:     int64(taskId),
+		MinSize:    req.MinSize,
+		MaxSize:    req.MaxSize,
+		SelfSignup: req.SelfSignup,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTeam):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Task not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.SetSettings: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(settings)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) deleteSettings(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	if err = h.checkManaged(ctx, claims, int64(taskId)); err != nil {
+		return err
+	}
+
+	if err = h.service.DeleteSettings(ctx.UserContext(), int64(taskId)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrTeamLocked):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Task is not a team task")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.DeleteSettings: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+// getByTaskId lists the teams of the task to the staff who see it and to the
+// students it is assigned to, who do not see the adjustments of others.
+func (h *handler) getByTaskId(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	if err = h.checkVisible(ctx, claims, int64(taskId)); err != nil {
+		return err
+	}
+
+	teams, err := h.service.GetByTaskId(ctx.UserContext(), int64(taskId))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetByTaskId: %v", err))
+	}
+	if claims.Role == models.UserRoleStudent {
+		for i := range teams {
+			teams[i] = forStudent(teams[i], claims.UserId)
+		}
+	}
+
+	responseBytes, err := jsoniter.Marshal(getByTaskIdResponse{Data: teams})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) getMy(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	team, err := h.service.GetByUser(ctx.UserContext(), int64(taskId), claims.UserId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Team not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetByUser: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(forStudent(team, claims.UserId))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) create(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	taskId, err := ctx.ParamsInt("taskId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <taskId> empty or not a number`)
+	}
+
+	var req createRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	opts := services.TeamServiceCreateOpts{
+		TaskId:  int64(taskId),
+		Name:    req.Name,
+		UserIds: req.UserIds,
+	}
+	if claims.Role == models.UserRoleStudent {
+		opts.UserIds = []int64{claims.UserId}
+		opts.SelfSignup = true
+	} else if err = h.checkManaged(ctx, claims, opts.TaskId); err != nil {
+		return err
+	}
+
+	team, err := h.service.Create(ctx.UserContext(), opts)
+	if err != nil {
+		return serviceError(err, "Create")
+	}
+
+	responseBytes, err := jsoniter.Marshal(team)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusCreated).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) delete(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	if err = h.checkManagedTeam(ctx, claims, int64(id)); err != nil {
+		return err
+	}
+
+	if err = h.service.Delete(ctx.UserContext(), int64(id)); err != nil {
+		return serviceError(err, "Delete")
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) addMember(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	var req addMemberRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	opts := services.TeamServiceMemberOpts{
+		TeamId: int64(id),
+		UserId: req.UserId,
+	}
+	if claims.Role == models.UserRoleStudent {
+		opts.UserId = claims.UserId
+		opts.SelfSignup = true
+	} else if err = h.checkManagedTeam(ctx, claims, opts.TeamId); err != nil {
+		return err
+	}
+
+	if err = h.service.AddMember(ctx.UserContext(), opts); err != nil {
+		return serviceError(err, "AddMember")
+	}
+
+	if err = ctx.Status(fiber.StatusCreated).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) removeMember(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	userId, err := ctx.ParamsInt("userId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <userId> empty or not a number`)
+	}
+
+	opts := services.TeamServiceMemberOpts{
+		TeamId: int64(id),
+		UserId: int64(userId),
+	}
+	if claims.Role == models.UserRoleStudent {
+		if opts.UserId != claims.UserId {
+			return fiber.NewError(fiber.StatusForbidden, "Students can only leave a team themselves")
+		}
+		opts.SelfSignup = true
+	} else if err = h.checkManagedTeam(ctx, claims, opts.TeamId); err != nil {
+		return err
+	}
+
+	if err = h.service.RemoveMember(ctx.UserContext(), opts); err != nil {
+		return serviceError(err, "RemoveMember")
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) setAdjustment(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	if err = h.checkManagedTeam(ctx, claims, int64(id)); err != nil {
+		return err
+	}
+
+	userId, err := ctx.ParamsInt("userId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <userId> empty or not a number`)
+	}
+
+	var req setAdjustmentRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	if err = h.service.SetAdjustment(ctx.UserContext(), services.TeamServiceSetAdjustmentOpts{
+		TeamId:     int64(id),
+		UserId:     int64(userId),
+		Adjustment: req.Adjustment,
+		Comment:    req.Comment,
+	}); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTeam):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Team member not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.SetAdjustment: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package teamshandlers
+
+import (
+	"backend/internal/models"
+)
+
+type setSettingsRequest struct {
+	MinSize    int64 `json:"minSize"`
+	MaxSize    int64 `json:"maxSize"`
+	SelfSignup bool  `json:"selfSignup"`
+}
+
+type getByTaskIdResponse struct {
+	Data []models.Team `json:"data"`
+}
+
+// forStudent hides the mark adjustments of the other members of the team from
+// the student.
+func forStudent(team models.Team, userId int64) models.Team {
+	members := make([]models.TeamMember, 0, len(team.Members))
+	for _, member := range team.Members {
+		if member.UserId != userId {
+			member.MarkAdjustment = 0
+			member.AdjustmentComment = nil
+		}
+		members = append(members, member)
+	}
+	team.Members = members
+	return team
+}
+
+// createRequest creates a team. UserIds is ignored for students, who always
+// create a team of their own.
+type createRequest struct {
+	Name    string  `json:"name"`
+	UserIds []int64 `json:"userIds"`
+}
+
+// addMemberRequest adds a member. UserId is ignored for students, who can
+// only join themselves.
+type addMemberRequest struct {
+	UserId int64 `json:"userId"`
+}
+
+type setAdjustmentRequest struct {
+	Adjustment int64   `json:"adjustment"`
+	Comment    *string `json:"comment"`
+}
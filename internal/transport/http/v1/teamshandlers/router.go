@@ -0,0 +1,39 @@
+package teamshandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type Config struct {
+	TeamService services.TeamService
+	TaskService services.TaskService
+	JWTConfig   models.JWTConfig
+}
+
+func New(router fiber.Router, cfg Config, log *zerolog.Logger) {
+	h := handler{
+		service:     cfg.TeamService,
+		taskService: cfg.TaskService,
+		log:         log,
+	}
+
+	staffOnly := auth.RequireRoles(models.UserRoleAdministrator, models.UserRoleTeacher)
+	studentOnly := auth.RequireRoles(models.UserRoleStudent)
+
+	teamGroup := router.Group("/team", auth.New(cfg.JWTConfig, log))
+	teamGroup.Get("/task/:taskId/settings", h.getSettings)
+	teamGroup.Put("/task/:taskId/settings", staffOnly, h.setSettings)
+	teamGroup.Delete("/task/:taskId/settings", staffOnly, h.deleteSettings)
+	teamGroup.Get("/task/:taskId", h.getByTaskId)
+	teamGroup.Get("/task/:taskId/my", studentOnly, h.getMy)
+	teamGroup.Post("/task/:taskId", h.create)
+	teamGroup.Delete("/:id", staffOnly, h.delete)
+	teamGroup.Post("/:id/members", h.addMember)
+	teamGroup.Delete("/:id/members/:userId", h.removeMember)
+	teamGroup.Put("/:id/members/:userId/adjustment", staffOnly, h.setAdjustment)
+}
@@ -0,0 +1,110 @@
+package trashhandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/repo"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog"
+)
+
+type handler struct {
+	service services.TrashService
+	log     *zerolog.Logger
+}
+
+// scopeUserId lets administrators see and restore the items of the groups and
+// students they see besides their own. Everyone else is limited to their own
+// items.
+func scopeUserId(claims auth.Claims) *int64 {
+	if claims.Role != models.UserRoleAdministrator {
+		return nil
+	}
+	return claims.ScopeUserId()
+}
+
+func (h *handler) getList(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	limit := ctx.QueryInt("limit")
+	if limit == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, `Query parameter <limit> missed or equal to zero`)
+	}
+
+	offset := ctx.QueryInt("offset", -1)
+	if offset == -1 {
+		return fiber.NewError(fiber.StatusBadRequest, `Query parameter <offset> missed`)
+	}
+
+	opts := services.TrashServiceGetListOpts{
+		UserId:      claims.UserId,
+		ScopeUserId: scopeUserId(claims),
+		Limit:       int64(limit),
+		Offset:      int64(offset),
+	}
+
+	items, err := h.service.GetList(ctx.UserContext(), opts)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetList: %v", err))
+	}
+
+	count, err := h.service.GetCount(ctx.UserContext(), opts)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetCount: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(getListResponse{
+		Items: items,
+		Count: count,
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) restore(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	if err = h.service.Restore(ctx.UserContext(), services.TrashServiceRestoreOpts{
+		Kind:        ctx.Params("kind"),
+		Id:          int64(id),
+		UserId:      claims.UserId,
+		ScopeUserId: scopeUserId(claims),
+	}); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTrashKind):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, repo.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "Item not found in the trash")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Restore: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
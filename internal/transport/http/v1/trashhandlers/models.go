@@ -0,0 +1,10 @@
+package trashhandlers
+
+import (
+	"backend/internal/models"
+)
+
+type getListResponse struct {
+	Items []models.TrashItem `json:"data"`
+	Count int64              `json:"count"`
+}
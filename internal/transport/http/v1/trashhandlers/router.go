@@ -0,0 +1,26 @@
+package trashhandlers
+
+import (
+	"backend/internal/models"
+	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type Config struct {
+	TrashService services.TrashService
+	JWTConfig    models.JWTConfig
+}
+
+func New(router fiber.Router, cfg Config, log *zerolog.Logger) {
+	h := handler{
+		service: cfg.TrashService,
+		log:     log,
+	}
+
+	trashGroup := router.Group("/trash", auth.New(cfg.JWTConfig, log))
+	trashGroup.Get("/", h.getList)
+	trashGroup.Post("/:kind/:id/restore", h.restore)
+}
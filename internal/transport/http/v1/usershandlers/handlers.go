@@ -1,7 +1,12 @@
 package usershandlers
 
 import (
+	"backend/internal/models"
+	"backend/internal/repo"
 	"backend/internal/services"
+	"backend/internal/transport/http/auth"
+	"backend/internal/transport/http/filter"
+	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	jsoniter "github.com/json-iterator/go"
@@ -9,8 +14,48 @@ import (
 )
 
 type handler struct {
-	service services.UserService
-	log     *zerolog.Logger
+	service      services.UserService
+	groupService services.GroupService
+	log          *zerolog.Logger
+}
+
+// checkUser makes sure the caller may see the user. Students only see
+// themselves, staff the students of their visible groups together with
+// groupIds. Users without a group are left to administrators.
+func (h *handler) checkUser(ctx *fiber.Ctx, claims auth.Claims, userId int64, groupIds ...int64) error {
+	if claims.Role == models.UserRoleStudent {
+		if userId != claims.UserId {
+			return fiber.NewError(fiber.StatusForbidden, "Access denied")
+		}
+		return nil
+	}
+
+	user, err := h.service.GetById(ctx.UserContext(), userId)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, services.ErrUserNotFound.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetById: %v", err))
+	}
+	if user.GroupId != nil {
+		groupIds = append(groupIds, *user.GroupId)
+	} else if claims.Role != models.UserRoleAdministrator {
+		return fiber.NewError(fiber.StatusForbidden, "Access denied")
+	}
+	if len(groupIds) == 0 {
+		return nil
+	}
+
+	if err = h.groupService.CheckAccess(ctx.UserContext(), services.GroupServiceCheckAccessOpts{
+		ScopeUserId: claims.UserId,
+		GroupIds:    groupIds,
+	}); err != nil {
+		if errors.Is(err, services.ErrGroupNotAssigned) {
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.groupService.CheckAccess: %v", err))
+	}
+	return nil
 }
 
 func (h *handler) getById(ctx *fiber.Ctx) error {
@@ -47,27 +92,23 @@ func (h *handler) getList(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, `Query parameter <offset> missed`)
 	}
 
-	roleId := ctx.QueryInt("roleId", -1)
-	if roleId == -1 {
-		return fiber.NewError(fiber.StatusBadRequest, `Query parameter <roleId> missed`)
-	}
+	listFilter := filter.FromQuery(ctx, filterFields)
 
-	users, err := h.service.GetListByRoleId(ctx.UserContext(), services.UserServiceGetListByRoleIdOpts{
-		RoleId: int64(roleId),
+	users, err := h.service.GetList(ctx.UserContext(), services.UserServiceGetListOpts{
+		Filter: listFilter,
 		Limit:  int64(limit),
 		Offset: int64(offset),
 	})
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetListByRoleId: %v", err))
+		if errors.Is(err, repo.ErrInvalidFilter) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetList: %v", err))
 	}
 
-	count, err := h.service.GetListByRoleIdCount(ctx.UserContext(), services.UserServiceGetListByRoleIdOpts{
-		RoleId: int64(roleId),
-		Limit:  int64(limit),
-		Offset: int64(offset),
-	})
+	count, err := h.service.GetCount(ctx.UserContext(), listFilter)
 	if err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetListByRoleIdCount: %v", err))
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetCount: %v", err))
 	}
 
 	responseBytes, err := jsoniter.Marshal(getListResponse{
@@ -84,3 +125,78 @@ func (h *handler) getList(ctx *fiber.Ctx) error {
 
 	return nil
 }
+
+func (h *handler) getMemberships(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	if err = h.checkUser(ctx, claims, int64(id)); err != nil {
+		return err
+	}
+
+	memberships, err := h.service.GetMemberships(ctx.UserContext(), int64(id))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.GetMemberships: %v", err))
+	}
+
+	responseBytes, err := jsoniter.Marshal(getMembershipsResponse{
+		Data: memberships,
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("jsoniter.Marshal: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusOK).Send(responseBytes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
+
+func (h *handler) transfer(ctx *fiber.Ctx) error {
+	claims, err := auth.GetClaimsFromCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("auth.GetClaimsFromCtx: %w", err)
+	}
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, `Path parameter <id> empty or not a number`)
+	}
+
+	var req transferRequest
+	if err = jsoniter.Unmarshal(ctx.Body(), &req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("jsoniter.Unmarshal: %v", err))
+	}
+
+	if err = h.checkUser(ctx, claims, int64(id), req.GroupId); err != nil {
+		return err
+	}
+
+	if err = h.service.Transfer(ctx.UserContext(), services.UserServiceTransferOpts{
+		UserId:        int64(id),
+		GroupId:       req.GroupId,
+		EffectiveFrom: req.EffectiveFrom,
+	}); err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrInvalidTransfer):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("h.service.Transfer: %v", err))
+	}
+
+	if err = ctx.Status(fiber.StatusAccepted).Send(nil); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("ctx.Send: %v", err))
+	}
+
+	return nil
+}
@@ -2,9 +2,21 @@ package usershandlers
 
 import (
 	"backend/internal/models"
+	"time"
 )
 
+var filterFields = []string{"groupId", "roleId", "active", "email", "createdAt"}
+
 type getListResponse struct {
 	Users []models.User `json:"data"`
-	Count int           `json:"count"`
+	Count int64         `json:"count"`
+}
+
+type getMembershipsResponse struct {
+	Data []models.GroupMembership `json:"data"`
+}
+
+type transferRequest struct {
+	GroupId       int64      `json:"groupId"`
+	EffectiveFrom *time.Time `json:"effectiveFrom"`
 }
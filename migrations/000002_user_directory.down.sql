@@ -0,0 +1,6 @@
+drop index if exists public.user_role_id_idx;
+drop index if exists public.user_group_id_idx;
+drop index if exists public.user_search_trgm_idx;
+
+alter table public."user"
+    drop column if exists is_active;
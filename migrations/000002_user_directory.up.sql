@@ -0,0 +1,10 @@
+create extension if not exists pg_trgm;
+
+alter table public."user"
+    add column if not exists is_active boolean not null default true;
+
+create index if not exists user_search_trgm_idx on public."user"
+    using gin ((last_name || ' ' || first_name || ' ' || coalesce(middle_name, '') || ' ' || email) gin_trgm_ops);
+
+create index if not exists user_group_id_idx on public."user" (group_id);
+create index if not exists user_role_id_idx on public."user" (role_id);
@@ -0,0 +1,21 @@
+create table if not exists public.group_membership
+(
+    id             bigserial primary key,
+    user_id        bigint      not null references public."user" (id),
+    group_id       bigint      not null references public."group" (id),
+    effective_from timestamptz not null,
+    effective_till timestamptz,
+    created_at     timestamptz not null default now(),
+
+    constraint group_membership_period check (effective_till is null or effective_till > effective_from)
+);
+
+create unique index if not exists group_membership_current_idx on public.group_membership (user_id)
+    where effective_till is null;
+
+create index if not exists group_membership_user_id_idx on public.group_membership (user_id, effective_from);
+
+insert into public.group_membership (user_id, group_id, effective_from)
+select u.id, u.group_id, u.created_at
+from public."user" u
+where u.group_id is not null;
@@ -0,0 +1,3 @@
+drop function if exists public.visible_groups(bigint);
+
+drop table if exists public.group_assignment;
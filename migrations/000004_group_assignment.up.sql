@@ -0,0 +1,22 @@
+create table if not exists public.group_assignment
+(
+    user_id    bigint      not null references public."user" (id),
+    group_id   bigint      not null references public."group" (id),
+    created_at timestamptz not null default now(),
+
+    primary key (user_id, group_id)
+);
+
+create index if not exists group_assignment_group_id_idx on public.group_assignment (group_id);
+
+-- visible_groups returns groups whose students the given staff member is allowed to see.
+create or replace function public.visible_groups(p_user_id bigint)
+    returns setof bigint
+    language sql
+    stable
+as
+$$
+select ga.group_id
+from public.group_assignment ga
+where ga.user_id = p_user_id
+$$;
@@ -0,0 +1,11 @@
+delete from public.group_assignment ga
+using public."user" u
+where u.id = ga.user_id and u.role_id = 4;
+
+-- Observers lose every right: they are kept as deactivated students so that the
+-- rows referencing them survive.
+update public."user"
+set role_id = 3, is_active = false
+where role_id = 4;
+
+delete from public.roles where id = 4;
@@ -0,0 +1,5 @@
+insert into public.roles (id, name)
+values (4, 'observer')
+on conflict (id) do nothing;
+
+select setval(pg_get_serial_sequence('public.roles', 'id'), (select max(id) from public.roles));
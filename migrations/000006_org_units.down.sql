@@ -0,0 +1,17 @@
+create or replace function public.visible_groups(p_user_id bigint)
+    returns setof bigint
+    language sql
+    stable
+as
+$$
+select ga.group_id
+from public.group_assignment ga
+where ga.user_id = p_user_id
+$$;
+
+drop table if exists public.org_unit_admin;
+
+alter table public."group"
+    drop column if exists org_unit_id;
+
+drop table if exists public.org_unit;
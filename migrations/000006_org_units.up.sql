@@ -0,0 +1,56 @@
+create table if not exists public.org_unit
+(
+    id         bigserial primary key,
+    parent_id  bigint references public.org_unit (id),
+    kind       text        not null,
+    name       text        not null,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz
+);
+
+create index if not exists org_unit_parent_id_idx on public.org_unit (parent_id);
+
+alter table public."group"
+    add column if not exists org_unit_id bigint references public.org_unit (id);
+
+create table if not exists public.org_unit_admin
+(
+    user_id     bigint      not null references public."user" (id),
+    org_unit_id bigint      not null references public.org_unit (id),
+    created_at  timestamptz not null default now(),
+
+    primary key (user_id, org_unit_id)
+);
+
+-- Administrators without org unit assignments see every group, scoped administrators
+-- see groups attached anywhere below their units.
+create or replace function public.visible_groups(p_user_id bigint)
+    returns setof bigint
+    language sql
+    stable
+as
+$$
+select ga.group_id
+from public.group_assignment ga
+where ga.user_id = p_user_id
+union
+select g.id
+from public."group" g
+where exists (select 1 from public."user" u where u.id = p_user_id and u.role_id = 1)
+  and not exists (select 1 from public.org_unit_admin oua where oua.user_id = p_user_id)
+union
+select g.id
+from public."group" g
+where g.org_unit_id in (
+    with recursive subtree as (
+        select oua.org_unit_id id
+        from public.org_unit_admin oua
+        where oua.user_id = p_user_id
+        union
+        select ou.id
+        from public.org_unit ou
+        join subtree s on ou.parent_id = s.id
+    )
+    select id from subtree
+)
+$$;
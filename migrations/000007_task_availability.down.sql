@@ -0,0 +1,2 @@
+alter table public.task
+    drop column if exists allow_late_submissions;
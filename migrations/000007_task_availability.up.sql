@@ -0,0 +1,2 @@
+alter table public.task
+    add column if not exists allow_late_submissions boolean not null default false;
@@ -0,0 +1,21 @@
+alter table public.mark
+    drop column if exists raw_mark;
+
+alter table public.answer
+    drop column if exists late_seconds,
+    drop column if exists is_late;
+
+alter table public.task
+    drop constraint if exists task_late_policy_check;
+
+alter table public.task
+    add column if not exists allow_late_submissions boolean not null default false;
+
+update public.task
+set allow_late_submissions = true
+where late_policy <> 'none';
+
+alter table public.task
+    drop column if exists late_grace_hours,
+    drop column if exists late_penalty,
+    drop column if exists late_policy;
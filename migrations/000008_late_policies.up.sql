@@ -0,0 +1,29 @@
+alter table public.task
+    add column if not exists late_policy      text    not null default 'none',
+    add column if not exists late_penalty     integer not null default 0,
+    add column if not exists late_grace_hours integer not null default 0;
+
+update public.task
+set late_policy = 'fixed'
+where allow_late_submissions;
+
+alter table public.task
+    drop column if exists allow_late_submissions;
+
+alter table public.task
+    add constraint task_late_policy_check
+        check (late_policy in ('none', 'fixed', 'percent_per_day', 'grace'));
+
+alter table public.answer
+    add column if not exists is_late      boolean not null default false,
+    add column if not exists late_seconds bigint  not null default 0;
+
+alter table public.mark
+    add column if not exists raw_mark integer;
+
+update public.mark
+set raw_mark = mark
+where raw_mark is null;
+
+alter table public.mark
+    alter column raw_mark set not null;
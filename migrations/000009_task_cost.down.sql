@@ -0,0 +1,5 @@
+alter table public.task
+    drop constraint if exists task_cost_check;
+
+alter table public.task
+    drop column if exists cost;
@@ -0,0 +1,13 @@
+alter table public.task
+    add column if not exists cost integer not null default 100;
+
+update public.task t
+set cost = greatest(t.cost, coalesce((
+    select max(m.raw_mark)
+    from public.mark m
+    join public.answer a on a.id = m.answer_id
+    where a.task_id = t.id
+), 0));
+
+alter table public.task
+    add constraint task_cost_check check (cost > 0);
@@ -0,0 +1,8 @@
+drop table if exists public.mark_criterion;
+
+alter table public.task
+    drop column if exists rubric_id;
+
+drop table if exists public.rubric_level;
+drop table if exists public.rubric_criterion;
+drop table if exists public.rubric;
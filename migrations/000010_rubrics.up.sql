@@ -0,0 +1,46 @@
+create table if not exists public.rubric
+(
+    id          bigserial primary key,
+    name        text        not null,
+    description text,
+    created_by  bigint      not null references public."user" (id),
+    created_at  timestamptz not null default now(),
+    updated_at  timestamptz
+);
+
+create table if not exists public.rubric_criterion
+(
+    id          bigserial primary key,
+    rubric_id   bigint  not null references public.rubric (id) on delete cascade,
+    position    integer not null,
+    name        text    not null,
+    description text
+);
+
+create index if not exists rubric_criterion_rubric_id_idx on public.rubric_criterion (rubric_id);
+
+create table if not exists public.rubric_level
+(
+    id           bigserial primary key,
+    criterion_id bigint  not null references public.rubric_criterion (id) on delete cascade,
+    position     integer not null,
+    name         text    not null,
+    description  text,
+    points       integer not null check (points >= 0)
+);
+
+create index if not exists rubric_level_criterion_id_idx on public.rubric_level (criterion_id);
+
+alter table public.task
+    add column if not exists rubric_id bigint references public.rubric (id);
+
+create table if not exists public.mark_criterion
+(
+    mark_id      bigint  not null references public.mark (id) on delete cascade,
+    criterion_id bigint  not null references public.rubric_criterion (id),
+    level_id     bigint  not null references public.rubric_level (id),
+    points       integer not null,
+    comment      text,
+
+    primary key (mark_id, criterion_id)
+);
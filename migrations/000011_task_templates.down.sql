@@ -0,0 +1,4 @@
+drop index if exists public.task_created_by_is_template_idx;
+
+alter table public.task
+    drop column if exists is_template;
@@ -0,0 +1,4 @@
+alter table public.task
+    add column if not exists is_template boolean not null default false;
+
+create index if not exists task_created_by_is_template_idx on public.task (created_by, is_template);
@@ -0,0 +1,5 @@
+alter table public.task
+    drop column if exists series_index,
+    drop column if exists series_id;
+
+drop table if exists public.task_series;
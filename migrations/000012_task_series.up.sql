@@ -0,0 +1,18 @@
+create table if not exists public.task_series
+(
+    id             bigserial primary key,
+    source_task_id bigint      not null references public.task (id),
+    rrule          text        not null,
+    generated      integer     not null default 1,
+    next_run_at    timestamptz,
+    cancelled_at   timestamptz,
+    created_at     timestamptz not null default now(),
+    updated_at     timestamptz
+);
+
+create index if not exists task_series_next_run_at_idx on public.task_series (next_run_at)
+    where cancelled_at is null;
+
+alter table public.task
+    add column if not exists series_id    bigint references public.task_series (id),
+    add column if not exists series_index integer not null default 0;
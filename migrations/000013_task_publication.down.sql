@@ -0,0 +1,6 @@
+drop index if exists public.task_scheduled_publish_at_idx;
+
+alter table public.task
+    drop column if exists published_at,
+    drop column if exists publish_at,
+    drop column if exists state;
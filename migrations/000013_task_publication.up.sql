@@ -0,0 +1,10 @@
+alter table public.task
+    add column if not exists state        text not null default 'published'
+        check (state in ('draft', 'scheduled', 'published')),
+    add column if not exists publish_at   timestamptz,
+    add column if not exists published_at timestamptz;
+
+update public.task set published_at = created_at where state = 'published' and published_at is null;
+
+create index if not exists task_scheduled_publish_at_idx on public.task (publish_at)
+    where state = 'scheduled';
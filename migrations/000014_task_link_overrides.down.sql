@@ -0,0 +1,5 @@
+drop function if exists public.task_window(bigint, bigint);
+
+alter table public.task_links
+    drop column if exists effective_till,
+    drop column if exists effective_from;
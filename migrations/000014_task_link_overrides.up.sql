@@ -0,0 +1,25 @@
+alter table public.task_links
+    add column if not exists effective_from timestamptz,
+    add column if not exists effective_till timestamptz;
+
+-- task_window returns the dates of the task for the user: an override on the
+-- user's own link wins over one on the user's group link, which wins over the
+-- dates of the task itself.
+create or replace function public.task_window(p_task_id bigint, p_user_id bigint)
+    returns table
+            (
+                effective_from timestamptz,
+                effective_till timestamptz
+            )
+    language sql
+    stable
+as
+$$
+select coalesce(ul.effective_from, gl.effective_from, t.effective_from),
+       coalesce(ul.effective_till, gl.effective_till, t.effective_till)
+from public.task t
+left join public.task_links ul on ul.task_id = t.id and ul.user_id = p_user_id
+left join public."user" u on u.id = p_user_id
+left join public.task_links gl on gl.task_id = t.id and gl.group_id = u.group_id
+where t.id = p_task_id
+$$;
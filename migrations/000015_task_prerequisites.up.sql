@@ -0,0 +1,14 @@
+create table if not exists public.task_prerequisite
+(
+    id                   bigserial primary key,
+    task_id              bigint      not null references public.task (id) on delete cascade,
+    prerequisite_task_id bigint      not null references public.task (id) on delete cascade,
+    condition            text        not null check (condition in ('submitted', 'graded', 'mark_at_least')),
+    min_mark             integer,
+    created_at           timestamptz not null default now(),
+    constraint unique_task_prerequisite unique (task_id, prerequisite_task_id),
+    constraint task_prerequisite_not_self check (task_id <> prerequisite_task_id),
+    constraint task_prerequisite_min_mark check ((condition = 'mark_at_least') = (min_mark is not null))
+);
+
+create index if not exists task_prerequisite_prerequisite_task_id_idx on public.task_prerequisite (prerequisite_task_id);
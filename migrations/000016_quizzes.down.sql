@@ -0,0 +1,4 @@
+drop table if exists public.quiz_response;
+drop table if exists public.task_quiz;
+drop table if exists public.question;
+drop table if exists public.question_bank;
@@ -0,0 +1,45 @@
+create table if not exists public.question_bank
+(
+    id          bigserial primary key,
+    name        text        not null,
+    description text,
+    created_by  bigint      not null references public."user" (id),
+    created_at  timestamptz not null default now(),
+    updated_at  timestamptz
+);
+
+create table if not exists public.question
+(
+    id         bigserial primary key,
+    bank_id    bigint  not null references public.question_bank (id) on delete cascade,
+    position   integer not null,
+    kind       text    not null check (kind in ('single_choice', 'multiple_choice', 'numeric', 'short_text')),
+    text       text    not null,
+    points     integer not null check (points > 0),
+    options    jsonb   not null default '[]',
+    answer_key jsonb   not null
+);
+
+create index if not exists question_bank_id_idx on public.question (bank_id);
+
+create table if not exists public.task_quiz
+(
+    task_id        bigint primary key references public.task (id) on delete cascade,
+    bank_id        bigint      not null references public.question_bank (id),
+    question_count integer check (question_count > 0),
+    shuffle        boolean     not null default false,
+    created_at     timestamptz not null default now(),
+    updated_at     timestamptz
+);
+
+create table if not exists public.quiz_response
+(
+    answer_id   bigint  not null references public.answer (id) on delete cascade,
+    question_id bigint  not null references public.question (id),
+    position    integer not null,
+    response    jsonb   not null,
+    is_correct  boolean not null,
+    points      integer not null,
+
+    primary key (answer_id, question_id)
+);
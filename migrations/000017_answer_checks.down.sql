@@ -0,0 +1,3 @@
+drop table if exists public.answer_check_result;
+drop table if exists public.answer_check;
+drop table if exists public.task_checker;
@@ -0,0 +1,38 @@
+create table if not exists public.task_checker
+(
+    task_id       bigint primary key references public.task (id) on delete cascade,
+    kind          text        not null,
+    command       text        not null,
+    tests_file_id bigint      not null references public.file (id),
+    timeout_ms    integer     not null check (timeout_ms > 0),
+    memory_mb     integer     not null check (memory_mb > 0),
+    created_at    timestamptz not null default now(),
+    updated_at    timestamptz
+);
+
+create table if not exists public.answer_check
+(
+    id            bigserial primary key,
+    answer_id     bigint      not null references public.answer (id) on delete cascade,
+    status        text        not null default 'pending' check (status in ('pending', 'running', 'done', 'failed')),
+    proposed_mark integer,
+    error         text,
+    created_at    timestamptz not null default now(),
+    started_at    timestamptz,
+    finished_at   timestamptz
+);
+
+create index if not exists answer_check_answer_id_idx on public.answer_check (answer_id);
+create index if not exists answer_check_pending_idx on public.answer_check (id) where status in ('pending', 'running');
+
+create table if not exists public.answer_check_result
+(
+    check_id    bigint  not null references public.answer_check (id) on delete cascade,
+    position    integer not null,
+    name        text    not null,
+    status      text    not null,
+    duration_ms integer not null,
+    output      text,
+
+    primary key (check_id, position)
+);
@@ -0,0 +1,16 @@
+drop index if exists public.group_deleted_at_idx;
+drop index if exists public.file_deleted_at_idx;
+drop index if exists public.answer_deleted_at_idx;
+drop index if exists public.task_deleted_at_idx;
+
+alter table public."group"
+    drop column if exists deleted_at;
+
+alter table public.file
+    drop column if exists deleted_at;
+
+alter table public.answer
+    drop column if exists deleted_at;
+
+alter table public.task
+    drop column if exists deleted_at;
@@ -0,0 +1,20 @@
+alter table public.task
+    add column if not exists deleted_at timestamptz;
+
+alter table public.answer
+    add column if not exists deleted_at timestamptz;
+
+alter table public.file
+    add column if not exists deleted_at timestamptz;
+
+alter table public."group"
+    add column if not exists deleted_at timestamptz;
+
+create index if not exists task_deleted_at_idx on public.task (deleted_at)
+    where deleted_at is not null;
+create index if not exists answer_deleted_at_idx on public.answer (deleted_at)
+    where deleted_at is not null;
+create index if not exists file_deleted_at_idx on public.file (deleted_at)
+    where deleted_at is not null;
+create index if not exists group_deleted_at_idx on public."group" (deleted_at)
+    where deleted_at is not null;
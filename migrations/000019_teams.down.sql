@@ -0,0 +1,11 @@
+drop function if exists public.member_mark(integer, bigint, bigint);
+drop function if exists public.team_of(bigint, bigint);
+
+drop index if exists public.answer_team_id_idx;
+
+alter table public.answer
+    drop column if exists team_id;
+
+drop table if exists public.team_member;
+drop table if exists public.team;
+drop table if exists public.task_team;
@@ -0,0 +1,71 @@
+create table if not exists public.task_team
+(
+    task_id     bigint primary key references public.task (id) on delete cascade,
+    min_size    integer     not null check (min_size > 0),
+    max_size    integer     not null,
+    self_signup boolean     not null default false,
+    created_at  timestamptz not null default now(),
+    updated_at  timestamptz,
+
+    constraint task_team_size check (max_size >= min_size)
+);
+
+create table if not exists public.team
+(
+    id         bigserial primary key,
+    task_id    bigint      not null references public.task (id) on delete cascade,
+    name       text        not null,
+    created_at timestamptz not null default now()
+);
+
+create index if not exists team_task_id_idx on public.team (task_id);
+
+create table if not exists public.team_member
+(
+    team_id            bigint      not null references public.team (id) on delete cascade,
+    task_id            bigint      not null references public.task (id) on delete cascade,
+    user_id            bigint      not null references public."user" (id),
+    mark_adjustment    integer     not null default 0,
+    adjustment_comment text,
+    joined_at          timestamptz not null default now(),
+
+    primary key (team_id, user_id),
+    constraint unique_team_member_task unique (task_id, user_id)
+);
+
+alter table public.answer
+    add column if not exists team_id bigint references public.team (id) on delete set null;
+
+create index if not exists answer_team_id_idx on public.answer (team_id)
+    where team_id is not null;
+
+-- team_of returns the team of the user on the task, or null.
+create or replace function public.team_of(p_task_id bigint, p_user_id bigint)
+    returns bigint
+    language sql
+    stable
+as
+$$
+select tm.team_id
+from public.team_member tm
+where tm.task_id = p_task_id
+  and tm.user_id = p_user_id
+$$;
+
+-- member_mark returns the mark of a team answer as it applies to one member,
+-- that is with the member's individual adjustment. Marks of individual
+-- answers are returned unchanged.
+create or replace function public.member_mark(p_mark integer, p_team_id bigint, p_user_id bigint)
+    returns integer
+    language sql
+    stable
+as
+$$
+select greatest(p_mark + coalesce((
+    select tm.mark_adjustment
+    from public.team_member tm
+    where tm.team_id = p_team_id
+      and tm.user_id = p_user_id
+), 0), 0)
+where p_mark is not null
+$$;
@@ -0,0 +1,8 @@
+drop table if exists public.comment_read;
+
+drop index if exists public.file_comment_id_idx;
+
+alter table public.file
+    drop column if exists comment_id;
+
+drop table if exists public.comment;
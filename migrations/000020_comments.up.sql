@@ -0,0 +1,31 @@
+-- comment holds the discussion on a task. Comments with an answer_id form the
+-- private thread on that answer, the others the thread of the task itself.
+create table if not exists public.comment
+(
+    id         bigserial primary key,
+    task_id    bigint      not null references public.task (id) on delete cascade,
+    answer_id  bigint references public.answer (id) on delete cascade,
+    author_id  bigint      not null references public."user" (id),
+    text       text        not null,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz
+);
+
+create index if not exists comment_thread_idx on public.comment (task_id, answer_id, id);
+
+alter table public.file
+    add column if not exists comment_id bigint references public.comment (id) on delete set null;
+
+create index if not exists file_comment_id_idx on public.file (comment_id)
+    where comment_id is not null;
+
+create table if not exists public.comment_read
+(
+    user_id      bigint      not null references public."user" (id),
+    task_id      bigint      not null references public.task (id) on delete cascade,
+    answer_id    bigint references public.answer (id) on delete cascade,
+    last_read_id bigint      not null,
+    read_at      timestamptz not null default now()
+);
+
+create unique index if not exists comment_read_thread_idx on public.comment_read (user_id, task_id, coalesce(answer_id, 0));
@@ -0,0 +1,8 @@
+-- calendar_token holds the secret that opens a user's calendar feed without
+-- logging in. Resetting it revokes every subscription made with the old one.
+create table if not exists public.calendar_token
+(
+    user_id    bigint      not null references public."user" (id) primary key,
+    token      text        not null unique,
+    created_at timestamptz not null default now()
+);
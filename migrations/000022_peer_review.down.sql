@@ -0,0 +1,3 @@
+drop table if exists public.peer_review_criterion;
+drop table if exists public.peer_review;
+drop table if exists public.task_peer_review;
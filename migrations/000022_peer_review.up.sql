@@ -0,0 +1,44 @@
+-- task_peer_review turns on peer review for a task. Once the task is over each
+-- submitter is assigned reviews_per_answer answers of other students, and
+-- assigned_at records that this has been done.
+create table if not exists public.task_peer_review
+(
+    task_id            bigint primary key references public.task (id) on delete cascade,
+    reviews_per_answer integer     not null check (reviews_per_answer > 0),
+    rubric_id          bigint references public.rubric (id),
+    mark_weight        integer     not null default 0 check (mark_weight between 0 and 100),
+    assigned_at        timestamptz,
+    created_at         timestamptz not null default now(),
+    updated_at         timestamptz
+);
+
+create table if not exists public.peer_review
+(
+    id                 bigserial primary key,
+    task_id            bigint      not null references public.task (id) on delete cascade,
+    answer_id          bigint      not null references public.answer (id) on delete cascade,
+    reviewer_id        bigint      not null references public."user" (id),
+    state              text        not null default 'assigned',
+    score              integer,
+    text               text,
+    moderation_comment text,
+    created_at         timestamptz not null default now(),
+    submitted_at       timestamptz,
+    moderated_at       timestamptz,
+
+    constraint peer_review_answer_reviewer unique (answer_id, reviewer_id)
+);
+
+create index if not exists peer_review_task_id_idx on public.peer_review (task_id);
+create index if not exists peer_review_reviewer_id_idx on public.peer_review (reviewer_id);
+
+create table if not exists public.peer_review_criterion
+(
+    peer_review_id bigint  not null references public.peer_review (id) on delete cascade,
+    criterion_id   bigint  not null references public.rubric_criterion (id),
+    level_id       bigint  not null references public.rubric_level (id),
+    points         integer not null,
+    comment        text,
+
+    primary key (peer_review_id, criterion_id)
+);
@@ -0,0 +1 @@
+drop function if exists public.sees_student(bigint, bigint, timestamptz);
@@ -0,0 +1,29 @@
+-- sees_student tells whether the staff member sees the student as of the given moment:
+-- through the group the student belonged to then or through the current one. Global
+-- administrators see every student, including those without a group.
+create or replace function public.sees_student(p_viewer_id bigint, p_user_id bigint, p_at timestamptz)
+    returns boolean
+    language sql
+    stable
+as
+$$
+select exists (
+    select 1
+    from public."user" v
+    where v.id = p_viewer_id
+      and v.role_id = 1
+      and not exists (select 1 from public.org_unit_admin oua where oua.user_id = p_viewer_id)
+) or exists (
+    select 1
+    from public.group_membership gm
+    where gm.user_id = p_user_id
+      and gm.effective_from <= p_at
+      and (gm.effective_till is null or gm.effective_till > p_at)
+      and gm.group_id in (select public.visible_groups(p_viewer_id))
+) or exists (
+    select 1
+    from public."user" u
+    where u.id = p_user_id
+      and u.group_id in (select public.visible_groups(p_viewer_id))
+)
+$$;
@@ -0,0 +1,2 @@
+alter table public.file
+    drop column if exists created_by;
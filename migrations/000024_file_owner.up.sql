@@ -0,0 +1,2 @@
+alter table public.file
+    add column if not exists created_by bigint references public."user" (id);
@@ -0,0 +1,14 @@
+create or replace function public.member_mark(p_mark integer, p_team_id bigint, p_user_id bigint)
+    returns integer
+    language sql
+    stable
+as
+$$
+select greatest(p_mark + coalesce((
+    select tm.mark_adjustment
+    from public.team_member tm
+    where tm.team_id = p_team_id
+      and tm.user_id = p_user_id
+), 0), 0)
+where p_mark is not null
+$$;
@@ -0,0 +1,20 @@
+-- member_mark keeps the adjusted mark of a team member within the cost of the task.
+create or replace function public.member_mark(p_mark integer, p_team_id bigint, p_user_id bigint)
+    returns integer
+    language sql
+    stable
+as
+$$
+select least(greatest(p_mark + coalesce((
+    select tm.mark_adjustment
+    from public.team_member tm
+    where tm.team_id = p_team_id
+      and tm.user_id = p_user_id
+), 0), 0), coalesce((
+    select t.cost
+    from public.team te
+    join public.task t on t.id = te.task_id
+    where te.id = p_team_id
+), p_mark))
+where p_mark is not null
+$$;
@@ -0,0 +1,156 @@
+package ical
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const dateTimeLayout = "20060102T150405Z"
+
+// maxLineLength is the limit in octets RFC 5545 sets on content lines, longer
+// lines are folded.
+const maxLineLength = 75
+
+// Event is a VEVENT. UID must stay the same across feed refreshes so that
+// calendar clients update the event instead of adding a new one.
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	URL          string
+	Start        time.Time
+	End          time.Time
+	LastModified time.Time
+	Categories   []string
+}
+
+// Calendar is a VCALENDAR published as a subscribable feed.
+type Calendar struct {
+	ProdId string
+	Name   string
+	// RefreshInterval hints clients how often to fetch the feed again.
+	RefreshInterval time.Duration
+	Events          []Event
+}
+
+// Marshal encodes the calendar as RFC 5545 text.
+func (c Calendar) Marshal() []byte {
+	var b builder
+	b.line("BEGIN", "VCALENDAR")
+	b.line("VERSION", "2.0")
+	b.line("PRODID", c.ProdId)
+	b.line("CALSCALE", "GREGORIAN")
+	b.line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		b.line("X-WR-CALNAME", escape(c.Name))
+	}
+	if c.RefreshInterval > 0 {
+		b.line("REFRESH-INTERVAL;VALUE=DURATION", duration(c.RefreshInterval))
+		b.line("X-PUBLISHED-TTL", duration(c.RefreshInterval))
+	}
+
+	now := time.Now()
+	for _, e := range c.Events {
+		b.line("BEGIN", "VEVENT")
+		b.line("UID", e.UID)
+		b.line("DTSTAMP", formatTime(now))
+		b.line("DTSTART", formatTime(e.Start))
+		b.line("DTEND", formatTime(e.End))
+		b.line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			b.line("DESCRIPTION", escape(e.Description))
+		}
+		if e.URL != "" {
+			b.line("URL", e.URL)
+		}
+		if len(e.Categories) > 0 {
+			categories := make([]string, 0, len(e.Categories))
+			for _, category := range e.Categories {
+				categories = append(categories, escape(category))
+			}
+			b.line("CATEGORIES", strings.Join(categories, ","))
+		}
+		if !e.LastModified.IsZero() {
+			b.line("LAST-MODIFIED", formatTime(e.LastModified))
+			b.line("SEQUENCE", formatSequence(e.LastModified))
+		}
+		b.line("END", "VEVENT")
+	}
+
+	b.line("END", "VCALENDAR")
+	return []byte(b.String())
+}
+
+type builder struct {
+	strings.Builder
+}
+
+// line writes "name:value" folded into lines of at most maxLineLength octets,
+// never splitting a UTF-8 sequence.
+func (b *builder) line(name, value string) {
+	s := name + ":" + value
+	for first := true; ; first = false {
+		limit := maxLineLength
+		if !first {
+			b.WriteString(" ")
+			limit--
+		}
+		if len(s) <= limit {
+			b.WriteString(s)
+			b.WriteString("\r\n")
+			return
+		}
+
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n")
+		s = s[cut:]
+	}
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+// escape escapes a TEXT value.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(dateTimeLayout)
+}
+
+// formatSequence derives the sequence from the modification time in minutes,
+// which keeps it growing without storing a counter and fits in 32 bits.
+func formatSequence(t time.Time) string {
+	return strconv.FormatInt(t.Unix()/60, 10)
+}
+
+func duration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		d = time.Minute
+	}
+
+	var b strings.Builder
+	b.WriteString("PT")
+	if h := int64(d / time.Hour); h > 0 {
+		b.WriteString(strconv.FormatInt(h, 10))
+		b.WriteString("H")
+	}
+	if m := int64(d%time.Hour) / int64(time.Minute); m > 0 {
+		b.WriteString(strconv.FormatInt(m, 10))
+		b.WriteString("M")
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// FileURLPattern is where links of the form file:<id> point to: the download
+// endpoint of the files API.
+const FileURLPattern = "/api/v1/file/download/%d"
+
+const fileScheme = "file:"
+
+var (
+	md = goldmark.New(
+		goldmark.WithExtensions(extension.GFM, mathExtension{}),
+		goldmark.WithParserOptions(
+			parser.WithASTTransformers(util.Prioritized(fileLinkTransformer{}, 100)),
+		),
+	)
+	policy = newPolicy()
+)
+
+// newPolicy allows the elements user generated content usually needs plus
+// what the GFM output relies on: task list checkboxes, code languages for
+// highlighting and math markers for formula rendering.
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+-]+$`)).OnElements("code")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^math (inline|display)$`)).OnElements("span")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	return p
+}
+
+// Render converts Markdown to HTML that is safe to embed in a page. Raw HTML
+// in the source is dropped, LaTeX between $ or $$ delimiters is passed through
+// untouched for the client to typeset, and file:<id> links and images point to
+// the attached file.
+func Render(src string) string {
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(src), &buf); err != nil {
+		// Converting into a buffer does not fail in practice; fall back to
+		// the escaped source rather than lose the content.
+		return "<p>" + html.EscapeString(src) + "</p>"
+	}
+	return policy.Sanitize(buf.String())
+}
+
+// RenderPtr renders optional content, keeping nil as nil.
+func RenderPtr(src *string) *string {
+	if src == nil {
+		return nil
+	}
+	rendered := Render(*src)
+	return &rendered
+}
+
+type fileLinkTransformer struct{}
+
+func (fileLinkTransformer) Transform(doc *ast.Document, _ text.Reader, _ parser.Context) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.Link:
+			node.Destination = fileURL(node.Destination)
+		case *ast.Image:
+			node.Destination = fileURL(node.Destination)
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// fileURL resolves file:<id> to the download URL. Anything else is returned
+// as is; a malformed file: link is dropped later by the sanitiser.
+func fileURL(dest []byte) []byte {
+	if !bytes.HasPrefix(dest, []byte(fileScheme)) {
+		return dest
+	}
+	id, err := strconv.ParseInt(string(dest[len(fileScheme):]), 10, 64)
+	if err != nil || id <= 0 {
+		return dest
+	}
+	return []byte(fmt.Sprintf(FileURLPattern, id))
+}
@@ -0,0 +1,121 @@
+package markdown
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "script tag",
+			src:  "<script>alert(1)</script>",
+			want: "\n",
+		},
+		{
+			name: "event handler attribute",
+			src:  "<img src=x onerror=alert(1)>",
+			want: "\n",
+		},
+		{
+			name: "raw link with handler",
+			src:  `<a href="https://example.com" onclick="x()">a</a>`,
+			want: "<p>a</p>\n",
+		},
+		{
+			name: "javascript link",
+			src:  "[x](javascript:alert(1))",
+			want: "<p>x</p>\n",
+		},
+		{
+			name: "external link",
+			src:  "[a](https://example.com)",
+			want: "<p><a href=\"https://example.com\" rel=\"nofollow\">a</a></p>\n",
+		},
+		{
+			name: "file image",
+			src:  "![a](file:12)",
+			want: "<p><img src=\"/api/v1/file/download/12\" alt=\"a\"></p>\n",
+		},
+		{
+			name: "malformed file link",
+			src:  "[a](file:abc)",
+			want: "<p>a</p>\n",
+		},
+		{
+			name: "task list",
+			src:  "- [x] done",
+			want: "<ul>\n<li><input checked=\"\" disabled=\"\" type=\"checkbox\"> done</li>\n</ul>\n",
+		},
+		{
+			name: "code language",
+			src:  "```go\nfmt.Println()\n```",
+			want: "<pre><code class=\"language-go\">fmt.Println()\n</code></pre>\n",
+		},
+		{
+			name: "inline math kept verbatim",
+			src:  "$a_1 * b_2$",
+			want: "<p><span class=\"math inline\">\\(a_1 * b_2\\)</span></p>\n",
+		},
+		{
+			name: "inline math escaped",
+			src:  "$x<script>$",
+			want: "<p><span class=\"math inline\">\\(x&lt;script&gt;\\)</span></p>\n",
+		},
+		{
+			name: "prices are not math",
+			src:  "costs $5 and $10",
+			want: "<p>costs $5 and $10</p>\n",
+		},
+		{
+			name: "space after opening delimiter",
+			src:  "$ a$",
+			want: "<p>$ a$</p>\n",
+		},
+		{
+			name: "space before closing delimiter",
+			src:  "$a $",
+			want: "<p>$a $</p>\n",
+		},
+		{
+			name: "escaped delimiter",
+			src:  `\$x$`,
+			want: "<p>$x$</p>\n",
+		},
+		{
+			name: "display math over lines",
+			src:  "$$\nx^2\n$$",
+			want: "<p><span class=\"math display\">\\[x^2\\]</span></p>\n",
+		},
+		{
+			name: "display math escaped",
+			src:  "$$x<y$$",
+			want: "<p><span class=\"math display\">\\[x&lt;y\\]</span></p>\n",
+		},
+		{
+			name: "unclosed display math",
+			src:  "unclosed $$ x",
+			want: "<p>unclosed $$ x</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.src); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderPtr(t *testing.T) {
+	if got := RenderPtr(nil); got != nil {
+		t.Errorf("RenderPtr(nil) = %q, want nil", *got)
+	}
+
+	src := "*a*"
+	if got := RenderPtr(&src); got == nil || *got != "<p><em>a</em></p>\n" {
+		t.Errorf("RenderPtr(%q) = %v, want %q", src, got, "<p><em>a</em></p>\n")
+	}
+}
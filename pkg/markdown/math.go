@@ -0,0 +1,118 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var kindMath = ast.NewNodeKind("Math")
+
+// mathNode keeps a formula verbatim so that Markdown emphasis and escapes do
+// not mangle LaTeX such as a_1 * b_2.
+type mathNode struct {
+	ast.BaseInline
+	display bool
+	value   []byte
+}
+
+func (n *mathNode) Kind() ast.NodeKind {
+	return kindMath
+}
+
+func (n *mathNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Value": string(n.value)}, nil)
+}
+
+type mathExtension struct{}
+
+func (mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(util.Prioritized(mathParser{}, 150)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(mathRenderer{}, 150)))
+}
+
+type mathParser struct{}
+
+func (mathParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p mathParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	if bytes.HasPrefix(line, []byte("$$")) {
+		return p.parseDisplay(block)
+	}
+	return p.parseInline(block, line)
+}
+
+// parseInline reads $...$ on a single line. Like most Markdown math dialects
+// it requires the formula to hug its delimiters and the closing $ not to be
+// followed by a digit, so prices like $5 and $10 stay text.
+func (mathParser) parseInline(block text.Reader, line []byte) ast.Node {
+	if len(line) < 3 || line[1] == ' ' || line[1] == '$' {
+		return nil
+	}
+	for i := 2; i < len(line); i++ {
+		if line[i] != '$' || line[i-1] == '\\' {
+			continue
+		}
+		if line[i-1] == ' ' || (i+1 < len(line) && line[i+1] >= '0' && line[i+1] <= '9') {
+			return nil
+		}
+		block.Advance(i + 1)
+		return &mathNode{value: bytes.Clone(line[1:i])}
+	}
+	return nil
+}
+
+// parseDisplay reads $$...$$, which may span several lines of a paragraph.
+func (mathParser) parseDisplay(block text.Reader) ast.Node {
+	line, _ := block.PeekLine()
+	start, startSegment := block.Position()
+
+	var value []byte
+	offset := 2
+	for line != nil {
+		if i := bytes.Index(line[offset:], []byte("$$")); i >= 0 {
+			value = append(value, line[offset:offset+i]...)
+			block.Advance(offset + i + 2)
+			return &mathNode{display: true, value: bytes.TrimSpace(value)}
+		}
+		value = append(value, line[offset:]...)
+		block.AdvanceLine()
+		line, _ = block.PeekLine()
+		offset = 0
+	}
+	block.SetPosition(start, startSegment)
+	return nil
+}
+
+type mathRenderer struct{}
+
+func (mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMath, renderMath)
+}
+
+// renderMath wraps the formula in the \( \) or \[ \] delimiters that client
+// side typesetters look for.
+func renderMath(w util.BufWriter, _ []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	node := n.(*mathNode)
+	if node.display {
+		_, _ = w.WriteString(`<span class="math display">\[`)
+		_, _ = w.Write(util.EscapeHTML(node.value))
+		_, _ = w.WriteString(`\]</span>`)
+	} else {
+		_, _ = w.WriteString(`<span class="math inline">\(`)
+		_, _ = w.Write(util.EscapeHTML(node.value))
+		_, _ = w.WriteString(`\)</span>`)
+	}
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,131 @@
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+const (
+	untilDateLayout     = "20060102"
+	untilDateTimeLayout = "20060102T150405Z"
+)
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// Rule is the subset of RFC 5545 RRULE supported for recurring tasks:
+// FREQ (DAILY, WEEKLY, MONTHLY), INTERVAL, COUNT and UNTIL.
+type Rule struct {
+	Freq     string
+	Interval int
+	Count    int
+	Until    *time.Time
+}
+
+// Parse parses a rule such as "FREQ=WEEKLY;INTERVAL=2;UNTIL=20261220".
+// A leading "RRULE:" is accepted. A rule without COUNT or UNTIL is rejected
+// so that every series is finite.
+func Parse(s string) (Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+
+	rule := Rule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Rule{}, fmt.Errorf("%w: %q", ErrInvalidRule, part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch value = strings.ToUpper(value); value {
+			case FreqDaily, FreqWeekly, FreqMonthly:
+				rule.Freq = value
+			default:
+				return Rule{}, fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRule, value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("%w: INTERVAL %q", ErrInvalidRule, value)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("%w: COUNT %q", ErrInvalidRule, value)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return Rule{}, fmt.Errorf("%w: UNTIL %q", ErrInvalidRule, value)
+			}
+			rule.Until = &until
+		default:
+			return Rule{}, fmt.Errorf("%w: unsupported part %q", ErrInvalidRule, key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return Rule{}, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if rule.Count == 0 && rule.Until == nil {
+		return Rule{}, fmt.Errorf("%w: COUNT or UNTIL is required", ErrInvalidRule)
+	}
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse(untilDateTimeLayout, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(untilDateLayout, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// A date-only UNTIL includes the whole day.
+	return t.Add(24*time.Hour - time.Second), nil
+}
+
+// Occurrence returns the n-th occurrence of the rule starting at start, where
+// the occurrence 0 is start itself. ok is false when the rule ends before it.
+func (r Rule) Occurrence(start time.Time, n int) (t time.Time, ok bool) {
+	if r.Count > 0 && n >= r.Count {
+		return time.Time{}, false
+	}
+
+	step := n * r.Interval
+	switch r.Freq {
+	case FreqDaily:
+		t = start.AddDate(0, 0, step)
+	case FreqWeekly:
+		t = start.AddDate(0, 0, 7*step)
+	case FreqMonthly:
+		t = addMonths(start, step)
+	default:
+		return time.Time{}, false
+	}
+
+	if r.Until != nil && t.After(*r.Until) {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// addMonths moves t by n months keeping its day of month, clamped to the last
+// day of the target month: January 31 plus one month is February 28 (or 29)
+// rather than March 3 as with time.AddDate.
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	first = first.AddDate(0, n, 0)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
@@ -0,0 +1,170 @@
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	until := time.Date(2026, 12, 20, 23, 59, 59, 0, time.UTC)
+	untilTime := time.Date(2026, 12, 20, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		src     string
+		want    Rule
+		wantErr bool
+	}{
+		{
+			name: "count",
+			src:  "FREQ=DAILY;COUNT=3",
+			want: Rule{Freq: FreqDaily, Interval: 1, Count: 3},
+		},
+		{
+			name: "prefix and lower case",
+			src:  " RRULE:freq=weekly;interval=2;count=5 ",
+			want: Rule{Freq: FreqWeekly, Interval: 2, Count: 5},
+		},
+		{
+			name: "until date includes the whole day",
+			src:  "FREQ=MONTHLY;UNTIL=20261220",
+			want: Rule{Freq: FreqMonthly, Interval: 1, Until: &until},
+		},
+		{
+			name: "until date time",
+			src:  "FREQ=MONTHLY;UNTIL=20261220T100000Z",
+			want: Rule{Freq: FreqMonthly, Interval: 1, Until: &untilTime},
+		},
+		{name: "no end", src: "FREQ=DAILY", wantErr: true},
+		{name: "no freq", src: "COUNT=3", wantErr: true},
+		{name: "unsupported freq", src: "FREQ=YEARLY;COUNT=3", wantErr: true},
+		{name: "zero interval", src: "FREQ=DAILY;INTERVAL=0;COUNT=3", wantErr: true},
+		{name: "negative count", src: "FREQ=DAILY;COUNT=-1", wantErr: true},
+		{name: "bad until", src: "FREQ=DAILY;UNTIL=tomorrow", wantErr: true},
+		{name: "unsupported part", src: "FREQ=DAILY;COUNT=3;BYDAY=MO", wantErr: true},
+		{name: "missing value", src: "FREQ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.src)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRule) {
+					t.Fatalf("Parse(%q) error = %v, want ErrInvalidRule", tt.src, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.src, err)
+			}
+			if got.Freq != tt.want.Freq || got.Interval != tt.want.Interval || got.Count != tt.want.Count {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.src, got, tt.want)
+			}
+			if (got.Until == nil) != (tt.want.Until == nil) || got.Until != nil && !got.Until.Equal(*tt.want.Until) {
+				t.Errorf("Parse(%q) Until = %v, want %v", tt.src, got.Until, tt.want.Until)
+			}
+		})
+	}
+}
+
+func TestOccurrence(t *testing.T) {
+	start := time.Date(2026, 1, 31, 9, 30, 0, 0, time.UTC)
+	until := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		rule   Rule
+		n      int
+		want   time.Time
+		wantOk bool
+	}{
+		{
+			name:   "start itself",
+			rule:   Rule{Freq: FreqDaily, Interval: 1, Count: 3},
+			n:      0,
+			want:   start,
+			wantOk: true,
+		},
+		{
+			name:   "daily interval",
+			rule:   Rule{Freq: FreqDaily, Interval: 2, Count: 3},
+			n:      2,
+			want:   time.Date(2026, 2, 4, 9, 30, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name:   "weekly",
+			rule:   Rule{Freq: FreqWeekly, Interval: 1, Count: 3},
+			n:      1,
+			want:   time.Date(2026, 2, 7, 9, 30, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name:   "monthly clamps to the end of a short month",
+			rule:   Rule{Freq: FreqMonthly, Interval: 1, Count: 12},
+			n:      1,
+			want:   time.Date(2026, 2, 28, 9, 30, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name:   "monthly clamps to a leap day",
+			rule:   Rule{Freq: FreqMonthly, Interval: 1, Count: 36},
+			n:      25,
+			want:   time.Date(2028, 2, 29, 9, 30, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name:   "monthly keeps the day after a short month",
+			rule:   Rule{Freq: FreqMonthly, Interval: 1, Count: 12},
+			n:      2,
+			want:   time.Date(2026, 3, 31, 9, 30, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name:   "monthly clamps to thirty days",
+			rule:   Rule{Freq: FreqMonthly, Interval: 1, Count: 12},
+			n:      3,
+			want:   time.Date(2026, 4, 30, 9, 30, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name:   "monthly across the year",
+			rule:   Rule{Freq: FreqMonthly, Interval: 5, Count: 12},
+			n:      2,
+			want:   time.Date(2026, 11, 30, 9, 30, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name:   "past count",
+			rule:   Rule{Freq: FreqDaily, Interval: 1, Count: 3},
+			n:      3,
+			wantOk: false,
+		},
+		{
+			name:   "within until",
+			rule:   Rule{Freq: FreqWeekly, Interval: 1, Until: &until},
+			n:      4,
+			want:   time.Date(2026, 2, 28, 9, 30, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name:   "past until",
+			rule:   Rule{Freq: FreqWeekly, Interval: 1, Until: &until},
+			n:      5,
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.rule.Occurrence(start, tt.n)
+			if ok != tt.wantOk {
+				t.Fatalf("Occurrence(%d) ok = %v, want %v", tt.n, ok, tt.wantOk)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("Occurrence(%d) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}